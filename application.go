@@ -2,6 +2,7 @@ package flutter
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"runtime/debug"
@@ -14,9 +15,12 @@ import (
 	"golang.org/x/text/language"
 
 	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/capitalization"
 	"github.com/go-flutter-desktop/go-flutter/internal/debounce"
 	"github.com/go-flutter-desktop/go-flutter/internal/opengl"
-	"github.com/go-flutter-desktop/go-flutter/internal/tasker"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/go-flutter-desktop/go-flutter/plugin/clipboard"
+	"github.com/go-flutter-desktop/go-flutter/plugin/eventsource"
 )
 
 // Run executes a flutter application with the provided options.
@@ -55,6 +59,8 @@ func NewApplication(opt ...Option) *Application {
 	opt = append(opt, AddPlugin(defaultIsolatePlugin))
 	opt = append(opt, AddPlugin(defaultMousecursorPlugin))
 	opt = append(opt, AddPlugin(defaultRestorationPlugin))
+	opt = append(opt, AddPlugin(defaultClipboardWatcherPlugin))
+	opt = append(opt, AddPlugin(defaultDropPlugin))
 
 	// apply all configs
 	for _, o := range opt {
@@ -100,8 +106,26 @@ func createResourceWindow(window *glfw.Window) (*glfw.Window, error) {
 // Run starts the application and waits for it to finish.
 func (a *Application) Run() error {
 	runtime.LockOSThread()
+	// closed last, after the engine shutdown
+	for _, recording := range a.config.messageRecordings {
+		defer recording.close()
+	}
+
+	inputMethod := a.config.inputMethod
+	if inputMethod == nil && !a.config.inputMethodDisabled {
+		inputMethod = newDefaultInputMethod()
+		if inputMethod != nil {
+			defer inputMethod.Close()
+		}
+	}
+	restoreXIM := func() {}
+	if inputMethod != nil && runtime.GOOS == "linux" {
+		// GLFW would also pass the key events to the input method, with XIM
+		restoreXIM = disableXIM()
+	}
 
 	err := glfw.Init()
+	restoreXIM()
 	if err != nil {
 		return errors.Wrap(err, "glfw init")
 	}
@@ -205,13 +229,34 @@ func (a *Application) Run() error {
 	a.engine.IcuDataPath = a.config.icuDataPath
 	a.engine.ElfSnapshotPath = a.config.elfSnapshotpath
 
+	// Tasks posted from other goroutines to the platform thread, drained in
+	// the main loop.
+	platformTasks := taskqueue.New(postEmptyEvent)
+	mainThread := newMainThread(platformTasks)
+	// Timers and file descriptor sources of the plugins, run by the eventLoop.
+	eventSources := eventsource.New(platformTasks.Post, postEmptyEvent)
+	defer eventSources.Close()
+
 	// Create a messenger and init plugins
-	messenger := newMessenger(a.engine)
+	messenger := newMessenger(a.engine, platformTasks)
+	messenger.interceptors = a.config.messageInterceptors
+	messenger.fallbackHandler = a.config.fallbackHandler
 	// Attach PlatformMessage callback function onto the engine
 	a.engine.PlatfromMessage = messenger.handlePlatformMessage
 
+	// Tasks which must run with the window OpenGL context, on the thread
+	// running the render tasks.
+	glTasks := platformTasks
+	var renderer *renderThread
+	if a.config.renderThread {
+		renderer = startRenderThread(a.engine.RunTask, a.config.idlePolicy)
+		// Stop the render thread once the engine has been shutdown.
+		defer renderer.stopAndWait()
+		glTasks = renderer.tasks
+	}
+
 	// Create a TextureRegistry
-	texturer := newTextureRegistry(a.engine, a.window)
+	texturer := newTextureRegistry(a.engine, a.window, glTasks, mainThread)
 	// Attach TextureRegistry callback function onto the engine
 	a.engine.GLExternalTextureFrameCallback = texturer.handleExternalTexture
 
@@ -219,10 +264,19 @@ func (a *Application) Run() error {
 	eventLoop := newEventLoop(
 		postEmptyEvent,   // Wakeup GLFW
 		a.engine.RunTask, // Flush tasks
+		eventSources,
+		a.config.idlePolicy,
 	)
 	// Attach TaskRunner callback functions onto the engine
 	a.engine.TaskRunnerRunOnCurrentThread = eventLoop.RunOnCurrentThread
 	a.engine.TaskRunnerPostTask = eventLoop.PostTask
+	if renderer != nil {
+		a.engine.RenderTaskRunnerRunOnCurrentThread = renderer.eventLoop.RunOnCurrentThread
+		a.engine.RenderTaskRunnerPostTask = renderer.eventLoop.PostTask
+		// The window context is made current on the render thread by the
+		// engine, it must not be current on the main thread.
+		glfw.DetachCurrentContext()
+	}
 
 	// Attach GL callback functions onto the engine
 	a.engine.GLMakeCurrent = func() bool {
@@ -279,6 +333,18 @@ func (a *Application) Run() error {
 	if err != nil {
 		fmt.Printf("go-flutter: %v\n", err)
 	}
+	// the text input capitalizes with the case mappings of the same locale
+	defaultTextinputPlugin.capitalizer = capitalization.New(languageTag)
+
+	// The clipboard of the flutter/platform channel and of the plugins.
+	clipboardBackend := a.config.clipboard
+	if clipboardBackend == nil {
+		clipboardBackend = newDefaultClipboard(a.window, mainThread)
+	}
+	defaultPlatformPlugin.clipboard = clipboardBackend
+	clipboardWatcher := clipboard.NewWatcher(clipboardBackend)
+	defaultClipboardWatcherPlugin.watcher = clipboardWatcher
+	defaultClipboardWatcherPlugin.interval = a.config.clipboardWatchInterval
 
 	// Register plugins
 	for _, p := range a.config.plugins {
@@ -302,6 +368,38 @@ func (a *Application) Run() error {
 				return errors.Wrap(err, "failed to initialize texture plugin"+fmt.Sprintf("%T", p))
 			}
 		}
+
+		// Extra init call for plugins that satisfy the PluginMainThread interface.
+		if mainThreadPlugin, ok := p.(PluginMainThread); ok {
+			err = mainThreadPlugin.InitPluginMainThread(mainThread)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize main thread plugin"+fmt.Sprintf("%T", p))
+			}
+		}
+
+		// Extra init call for plugins that satisfy the PluginEventLoop interface.
+		if eventLoopPlugin, ok := p.(PluginEventLoop); ok {
+			err = eventLoopPlugin.InitPluginEventLoop(eventSources)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize event loop plugin"+fmt.Sprintf("%T", p))
+			}
+		}
+
+		// Extra init call for plugins that satisfy the PluginClipboard interface.
+		if clipboardPlugin, ok := p.(PluginClipboard); ok {
+			err = clipboardPlugin.InitPluginClipboard(clipboardBackend)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize clipboard plugin"+fmt.Sprintf("%T", p))
+			}
+		}
+
+		// Extra init call for plugins that satisfy the PluginClipboardWatcher interface.
+		if watcherPlugin, ok := p.(PluginClipboardWatcher); ok {
+			err = watcherPlugin.InitPluginClipboardWatcher(clipboardWatcher)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize clipboard watcher plugin"+fmt.Sprintf("%T", p))
+			}
+		}
 	}
 
 	// Change the flutter initial route
@@ -323,13 +421,12 @@ func (a *Application) Run() error {
 	// Debounce the position callback.
 	// This avoid making too much flutter redraw and potentially redundant
 	// network calls.
-	glfwDebouceTasker := tasker.New()
 	debounced := debounce.New(50 * time.Millisecond)
 	// SetPosCallback is called when the window is moved, this directly calls
 	// glfwRefreshCallback in order to redraw and avoid transparent scene.
 	a.window.SetPosCallback(func(window *glfw.Window, xpos int, ypos int) {
 		debounced(func() {
-			glfwDebouceTasker.Do(func() {
+			platformTasks.Post(func() {
 				windowManager.glfwRefreshCallback(window)
 			})
 		})
@@ -338,12 +435,36 @@ func (a *Application) Run() error {
 		windowManager.glfwRefreshCallback(window)
 	})
 
-	// Attach glfw window callbacks for text input
+	// Attach glfw window callbacks for text input and key events
 	defaultTextinputPlugin.backOnEscape = a.config.backOnEscape
+	defaultTextinputPlugin.mainThread = mainThread
+	defaultTextinputPlugin.window = a.window
+	defaultTextinputPlugin.windowManager = windowManager
+	if inputMethod != nil {
+		defaultTextinputPlugin.inputMethod = inputMethod
+		inputMethod.SetHandler(inputMethodHandler{defaultTextinputPlugin})
+	}
+	defaultKeyeventsPlugin.engine = a.engine
+	defaultKeyeventsPlugin.mainThread = mainThread
+	defaultKeyeventsPlugin.messenger = messenger
+	defaultTextinputPlugin.keyEvents = defaultKeyeventsPlugin.pending
+	// report the Caps Lock and Num Lock state in the modifier bits
+	a.window.SetInputMode(glfw.LockKeyMods, glfw.True)
+	a.window.SetFocusCallback(func(window *glfw.Window, focused bool) {
+		defaultKeyeventsPlugin.glfwFocusCallback(window, focused)
+		defaultTextinputPlugin.glfwFocusCallback(window, focused)
+	})
 	a.window.SetKeyCallback(
 		func(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-			defaultTextinputPlugin.glfwKeyCallback(window, key, scancode, action, mods)
-			defaultKeyeventsPlugin.sendKeyEvent(window, key, scancode, action, mods)
+			// the keys composing text aren't sent to the framework
+			if defaultTextinputPlugin.processInputMethodKey(key, scancode, action, mods) {
+				return
+			}
+			// the text input and back navigation shortcuts only apply to the
+			// keys unhandled by the framework.
+			defaultKeyeventsPlugin.sendKeyEvent(window, key, scancode, action, mods, func() {
+				defaultTextinputPlugin.glfwKeyCallback(window, key, scancode, action, mods)
+			})
 		})
 	a.window.SetCharCallback(defaultTextinputPlugin.glfwCharCallback)
 
@@ -353,26 +474,47 @@ func (a *Application) Run() error {
 	// Attach glfw window callbacks for mouse input
 	a.window.SetCursorEnterCallback(windowManager.glfwCursorEnterCallback)
 	a.window.SetCursorPosCallback(windowManager.glfwCursorPosCallback)
-	a.window.SetMouseButtonCallback(windowManager.glfwMouseButtonCallback)
+	a.window.SetMouseButtonCallback(
+		func(window *glfw.Window, key glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+			// the modifiers held while the window was unfocused are only
+			// known from the next event.
+			defaultKeyeventsPlugin.syncModifiers(window, mods)
+			windowManager.glfwMouseButtonCallback(window, key, action, mods)
+		})
 	a.window.SetScrollCallback(
 		func(window *glfw.Window, xoff float64, yoff float64) {
 			windowManager.glfwScrollCallback(window, xoff, yoff, a.config.scrollAmount)
 		})
 
+	// Attach glfw window callback for files dropped on the window
+	defaultDropPlugin.filters = a.config.dropFilters
+	a.window.SetDropCallback(
+		func(window *glfw.Window, names []string) {
+			defaultDropPlugin.glfwDropCallback(window, names, windowManager.pixelsPerScreenCoordinate)
+		})
+
+	// Stop the texture uploader once the engine has been shutdown.
+	defer texturer.close()
+
 	// Shutdown the engine if we return from this function (on purpose or panic)
 	defer a.engine.Shutdown()
+	// Reply to the messages left without reply while the engine is running,
+	// the handlers replying later are ignored.
+	defer messenger.close()
 
 	// Handle events until the window indicates we should stop. An event may tell the window to stop, in which case
 	// we'll exit on next iteration.
 	for !a.window.ShouldClose() {
 		eventLoop.WaitForEvents(func(duration float64) {
+			if math.IsInf(duration, 1) {
+				glfw.WaitEvents()
+				return
+			}
 			glfw.WaitEventsTimeout(duration)
 		})
 
 		// Execute tasks that MUST be run in the engine thread (!blocks rendering!)
-		glfwDebouceTasker.ExecuteTasks()
-		messenger.engineTasker.ExecuteTasks()
-		texturer.engineTasker.ExecuteTasks()
+		platformTasks.Drain()
 	}
 
 	fmt.Println("go-flutter: closing application")
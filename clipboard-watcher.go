@@ -0,0 +1,133 @@
+package flutter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/clipboard"
+	"github.com/go-flutter-desktop/go-flutter/plugin/eventsource"
+)
+
+const clipboardChannelName = "go-flutter/clipboard"
+
+// defaultClipboardWatchInterval is the interval between two polls of the
+// clipboard, while changes are watched.
+const defaultClipboardWatchInterval = 500 * time.Millisecond
+
+// ClipboardWatchInterval sets the interval between two polls of the clipboard
+// while its changes are watched, by Dart or by a plugin. An interval of 0
+// disables the watcher.
+func ClipboardWatchInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.clipboardWatchInterval = interval
+	}
+}
+
+// clipboardWatcherPlugin implements flutter.Plugin and sends the clipboard
+// changes on the go-flutter/clipboard event channel. Every event is a map
+// with the MIME types of the new content, and whether it has text:
+// {"types": ["text/plain"], "hasStrings": true}.
+//
+// The clipboard is polled from a timer of the platform event loop, running
+// while the watcher has subscribers. The clipboard is read in a goroutine, the
+// backend may run a process for it, and the changes are notified on the
+// platform thread.
+type clipboardWatcherPlugin struct {
+	interval time.Duration
+	watcher  *clipboard.Watcher
+	sources  *eventsource.Sources
+
+	// timer polls the clipboard, nil while the watcher has no subscriber.
+	// It's guarded by the lock of the watcher.
+	timer *eventsource.Timer
+	// polling is set while the clipboard is read, the ticks are skipped
+	// meanwhile. It's only used on the platform thread.
+	polling bool
+
+	// unsubscribe stops the events of the Dart stream, nil while nobody
+	// listens.
+	unsubscribe     func()
+	unsubscribeLock sync.Mutex
+}
+
+// all hardcoded because theres not pluggable renderer system.
+var defaultClipboardWatcherPlugin = &clipboardWatcherPlugin{}
+
+var _ PluginEventLoop = &clipboardWatcherPlugin{} // compile-time type check
+
+func (p *clipboardWatcherPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	channel := plugin.NewEventChannel(messenger, clipboardChannelName, plugin.StandardMethodCodec{})
+	channel.Handle(p)
+	return nil
+}
+
+func (p *clipboardWatcherPlugin) InitPluginEventLoop(sources *eventsource.Sources) error {
+	if p.interval <= 0 {
+		return nil
+	}
+	p.sources = sources
+	p.watcher.OnActive(p.watch)
+	return nil
+}
+
+// watch starts the poll timer when the watcher gets its first subscriber, and
+// stops it when the last one leaves.
+func (p *clipboardWatcherPlugin) watch(active bool) {
+	if active && p.timer == nil {
+		p.timer = p.sources.AddTimer(p.interval, true, p.poll)
+	} else if !active && p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+// poll reads the clipboard in a goroutine, and posts the notification of its
+// change back to the platform thread.
+func (p *clipboardWatcherPlugin) poll() {
+	if p.polling {
+		return
+	}
+	p.polling = true
+	go func() {
+		change, changed, err := p.watcher.Check()
+		p.sources.AddTimer(0, false, func() {
+			p.polling = false
+			if err != nil {
+				fmt.Printf("go-flutter: failed to poll the clipboard: %v\n", err)
+				return
+			}
+			if changed {
+				p.watcher.Notify(change)
+			}
+		})
+	}()
+}
+
+func (p *clipboardWatcherPlugin) OnListen(arguments interface{}, sink *plugin.EventSink) {
+	p.unsubscribeLock.Lock()
+	defer p.unsubscribeLock.Unlock()
+	if p.unsubscribe != nil {
+		p.unsubscribe()
+	}
+	p.unsubscribe = p.watcher.Subscribe(func(change clipboard.Change) {
+		types := make([]interface{}, len(change.Types))
+		for i, t := range change.Types {
+			types[i] = t
+		}
+		sink.Success(map[interface{}]interface{}{
+			"types":      types,
+			"hasStrings": change.HasType(clipboard.MIMEText),
+		})
+	})
+}
+
+func (p *clipboardWatcherPlugin) OnCancel(arguments interface{}) {
+	p.unsubscribeLock.Lock()
+	defer p.unsubscribeLock.Unlock()
+	if p.unsubscribe != nil {
+		p.unsubscribe()
+		p.unsubscribe = nil
+	}
+}
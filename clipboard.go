@@ -0,0 +1,77 @@
+package flutter
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin/clipboard"
+)
+
+// Clipboard sets the clipboard backend of the application, used by the
+// flutter/platform channel and given to the plugins implementing
+// PluginClipboard. By default, on Linux, the clipboard of the system is
+// accessed with clipboard.NewCommand. The text-only clipboard of GLFW is used
+// on the other platforms, and when the clipboard tools aren't installed.
+func Clipboard(c clipboard.Clipboard) Option {
+	return func(conf *config) {
+		conf.clipboard = c
+	}
+}
+
+// newDefaultClipboard returns the Command clipboard on Linux, or the GLFW
+// clipboard when it's unavailable.
+func newDefaultClipboard(window *glfw.Window, mainThread *MainThread) clipboard.Clipboard {
+	if runtime.GOOS == "linux" {
+		c, err := clipboard.NewCommand()
+		if err == nil {
+			return c
+		}
+		fmt.Printf("go-flutter: using the text-only GLFW clipboard: %v\n", err)
+	}
+	return &glfwClipboard{window: window, mainThread: mainThread}
+}
+
+// glfwClipboard is the text-only clipboard of GLFW. GLFW calls are made on
+// the main thread, so that plugins may use it from any goroutine.
+type glfwClipboard struct {
+	window     *glfw.Window
+	mainThread *MainThread
+}
+
+var _ clipboard.Clipboard = &glfwClipboard{} // compile-time type check
+
+func (c *glfwClipboard) text() (text string) {
+	c.mainThread.RunOnMainThread(func() {
+		text = c.window.GetClipboardString()
+	})
+	return text
+}
+
+func (c *glfwClipboard) Types() ([]string, error) {
+	if c.text() == "" {
+		return nil, nil
+	}
+	return []string{clipboard.MIMEText}, nil
+}
+
+func (c *glfwClipboard) Read(mimeType string) ([]byte, error) {
+	if mimeType != clipboard.MIMEText {
+		return nil, clipboard.ErrUnavailable
+	}
+	text := c.text()
+	if text == "" {
+		return nil, clipboard.ErrUnavailable
+	}
+	return []byte(text), nil
+}
+
+// Write writes the text representation of content, the others are dropped.
+func (c *glfwClipboard) Write(content map[string][]byte) error {
+	text := string(content[clipboard.MIMEText])
+	c.mainThread.RunOnMainThread(func() {
+		c.window.SetClipboardString(text)
+	})
+	return clipboard.Dropped(content, clipboard.MIMEText)
+}
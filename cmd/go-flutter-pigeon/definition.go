@@ -0,0 +1,217 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// definition is the parsed representation of a Pigeon API file, independent
+// of the format (Dart or JSON) it was read from.
+type definition struct {
+	Classes []*class
+	Enums   []*enum
+	APIs    []*api
+}
+
+// class is a Pigeon data class.
+type class struct {
+	Name   string
+	Fields []field
+}
+
+// enum is a Pigeon enum, its values are sent as their index.
+type enum struct {
+	Name   string
+	Values []string
+}
+
+type apiKind int
+
+const (
+	// hostAPI is implemented in Go and called from Dart (@HostApi).
+	hostAPI apiKind = iota
+	// flutterAPI is implemented in Dart and called from Go (@FlutterApi).
+	flutterAPI
+)
+
+// api is a set of methods sharing a direction.
+type api struct {
+	Name    string
+	Kind    apiKind
+	Methods []method
+}
+
+type method struct {
+	Name    string
+	Args    []field
+	Returns typeRef
+	// Async is set by the @async annotation. Async host methods are handled
+	// outside of the platform thread.
+	Async bool
+}
+
+type field struct {
+	Name string
+	Type typeRef
+}
+
+// typeRef is a reference to a Dart type, such as `List<Book?>?`.
+type typeRef struct {
+	Name     string
+	Args     []typeRef
+	Nullable bool
+}
+
+func (t typeRef) isVoid() bool {
+	return t.Name == "void"
+}
+
+// String returns the Dart representation of the type.
+func (t typeRef) String() string {
+	s := t.Name
+	if len(t.Args) > 0 {
+		args := make([]string, 0, len(t.Args))
+		for _, a := range t.Args {
+			args = append(args, a.String())
+		}
+		s += "<" + strings.Join(args, ", ") + ">"
+	}
+	if t.Nullable {
+		s += "?"
+	}
+	return s
+}
+
+// parseTypeRef parses a Dart type expression.
+func parseTypeRef(s string) (typeRef, error) {
+	t, rest, err := parseTypeRefPrefix(strings.TrimSpace(s))
+	if err != nil {
+		return typeRef{}, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return typeRef{}, errors.Errorf("unexpected %q after type in %q", rest, s)
+	}
+	return t, nil
+}
+
+func parseTypeRefPrefix(s string) (t typeRef, rest string, err error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && isIdentChar(s[i]) {
+		i++
+	}
+	if i == 0 {
+		return t, s, errors.Errorf("expected a type name in %q", s)
+	}
+	t.Name, s = s[:i], strings.TrimSpace(s[i:])
+	if strings.HasPrefix(s, "<") {
+		s = s[1:]
+		for {
+			var arg typeRef
+			arg, s, err = parseTypeRefPrefix(s)
+			if err != nil {
+				return t, s, err
+			}
+			t.Args = append(t.Args, arg)
+			s = strings.TrimSpace(s)
+			if strings.HasPrefix(s, ",") {
+				s = s[1:]
+				continue
+			}
+			if !strings.HasPrefix(s, ">") {
+				return t, s, errors.Errorf("unterminated type arguments for %s", t.Name)
+			}
+			s = strings.TrimSpace(s[1:])
+			break
+		}
+	}
+	if strings.HasPrefix(s, "?") {
+		t.Nullable = true
+		s = s[1:]
+	}
+	return t, s, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// validate checks that every referenced type is known and every generic type
+// has the right number of arguments.
+func (d *definition) validate() error {
+	known := make(map[string]bool)
+	for _, c := range d.Classes {
+		if known[c.Name] {
+			return errors.Errorf("duplicate declaration of %s", c.Name)
+		}
+		known[c.Name] = true
+	}
+	for _, e := range d.Enums {
+		if known[e.Name] {
+			return errors.Errorf("duplicate declaration of %s", e.Name)
+		}
+		known[e.Name] = true
+	}
+
+	var check func(t typeRef, where string) error
+	check = func(t typeRef, where string) error {
+		switch t.Name {
+		case "List":
+			if len(t.Args) != 1 {
+				return errors.Errorf("%s: List requires one type argument", where)
+			}
+		case "Map":
+			if len(t.Args) != 2 {
+				return errors.Errorf("%s: Map requires two type arguments", where)
+			}
+		default:
+			if len(t.Args) != 0 {
+				return errors.Errorf("%s: %s doesn't take type arguments", where, t.Name)
+			}
+			if _, ok := builtinTypes[t.Name]; !ok && !known[t.Name] {
+				return errors.Errorf("%s: unknown type %s", where, t.Name)
+			}
+		}
+		for _, a := range t.Args {
+			if a.isVoid() {
+				return errors.Errorf("%s: void is not a valid type argument", where)
+			}
+			if err := check(a, where); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, c := range d.Classes {
+		for _, f := range c.Fields {
+			if f.Type.isVoid() {
+				return errors.Errorf("%s.%s: fields cannot be void", c.Name, f.Name)
+			}
+			if err := check(f.Type, c.Name+"."+f.Name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range d.APIs {
+		for _, m := range a.Methods {
+			where := a.Name + "." + m.Name
+			for _, arg := range m.Args {
+				if arg.Type.isVoid() {
+					return errors.Errorf("%s: arguments cannot be void", where)
+				}
+				if err := check(arg.Type, where); err != nil {
+					return err
+				}
+			}
+			if !m.Returns.isVoid() {
+				if err := check(m.Returns, where); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
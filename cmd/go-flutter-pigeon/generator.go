@@ -0,0 +1,752 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// builtinTypes maps the Dart types supported by the StandardMessageCodec to
+// their Go counterpart.
+var builtinTypes = map[string]string{
+	"String":      "string",
+	"int":         "int64",
+	"double":      "float64",
+	"bool":        "bool",
+	"Object":      "interface{}",
+	"Uint8List":   "[]byte",
+	"Int32List":   "[]int32",
+	"Int64List":   "[]int64",
+	"Float64List": "[]float64",
+}
+
+// firstCustomType is the first type discriminator used to encode data
+// classes, as done by the Dart code generated by Pigeon.
+const firstCustomType = 128
+
+type generator struct {
+	def           *definition
+	pkg           string
+	channelPrefix string
+
+	classes map[string]*class
+	enums   map[string]*enum
+
+	// helpers holds the encode/decode helpers, by type key, required by the
+	// generated code.
+	helpers map[string]typeRef
+
+	buf bytes.Buffer
+}
+
+// generate returns the formatted Go source for the given definition.
+func generate(def *definition, pkg string, channelPrefix string) ([]byte, error) {
+	err := def.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		def:           def,
+		pkg:           pkg,
+		channelPrefix: channelPrefix,
+		classes:       make(map[string]*class),
+		enums:         make(map[string]*enum),
+		helpers:       make(map[string]typeRef),
+	}
+	for _, c := range def.Classes {
+		g.classes[c.Name] = c
+	}
+	for _, e := range def.Enums {
+		g.enums[e.Name] = e
+	}
+
+	for _, a := range def.APIs {
+		if n := len(g.codecClasses(a)); n > 255-firstCustomType {
+			return nil, errors.Errorf("%s uses %d data classes, at most %d are supported", a.Name, n, 255-firstCustomType)
+		}
+	}
+
+	g.genHeader()
+	for _, e := range def.Enums {
+		g.genEnum(e)
+	}
+	for _, c := range def.Classes {
+		g.genClass(c)
+	}
+	for _, a := range def.APIs {
+		g.genCodec(a)
+		switch a.Kind {
+		case hostAPI:
+			g.genHostAPI(a)
+		case flutterAPI:
+			g.genFlutterAPI(a)
+		}
+	}
+	g.genHelpers()
+	g.genRuntime()
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return g.buf.Bytes(), errors.Wrap(err, "generated invalid Go code")
+	}
+	return src, nil
+}
+
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *generator) genHeader() {
+	g.p("// Code generated by go-flutter-pigeon. DO NOT EDIT.")
+	g.p("")
+	g.p("package %s", g.pkg)
+	g.p("")
+	g.p("import (")
+	g.p("\t\"fmt\"")
+	g.p("")
+	g.p("\t\"github.com/go-flutter-desktop/go-flutter/plugin\"")
+	g.p(")")
+	g.p("")
+}
+
+func (g *generator) genEnum(e *enum) {
+	g.p("// %s is sent over the channels as the index of its value.", e.Name)
+	g.p("type %s int", e.Name)
+	g.p("")
+	g.p("// Values of %s.", e.Name)
+	g.p("const (")
+	for i, v := range e.Values {
+		g.p("\t%s%s %s = %d", e.Name, exported(v), e.Name, i)
+	}
+	g.p(")")
+	g.p("")
+}
+
+func (g *generator) genClass(c *class) {
+	g.p("// %s is a Pigeon data class.", c.Name)
+	g.p("type %s struct {", c.Name)
+	for _, f := range c.Fields {
+		g.p("\t%s %s", exported(f.Name), g.goType(f.Type))
+	}
+	g.p("}")
+	g.p("")
+
+	g.p("func (v *%s) pigeonEncode() []interface{} {", c.Name)
+	g.p("\treturn []interface{}{")
+	for _, f := range c.Fields {
+		g.p("\t\t%s,", g.encodeCall(f.Type, "v."+exported(f.Name)))
+	}
+	g.p("\t}")
+	g.p("}")
+	g.p("")
+
+	g.p("func pigeonDecode%sFields(content interface{}) (*%s, error) {", c.Name, c.Name)
+	g.p("\tlist, ok := content.([]interface{})")
+	g.p("\tif !ok {")
+	g.p("\t\treturn nil, fmt.Errorf(\"expected %s fields, got %%T\", content)", c.Name)
+	g.p("\t}")
+	g.p("\tv := &%s{}", c.Name)
+	if len(c.Fields) > 0 {
+		g.p("\tvar err error")
+	}
+	for i, f := range c.Fields {
+		// Missing trailing fields are left to their zero value, which allows
+		// adding fields to a class without breaking older clients.
+		g.p("\tif len(list) > %d {", i)
+		g.p("\t\tv.%s, err = %s", exported(f.Name), g.decodeCall(f.Type, fmt.Sprintf("list[%d]", i)))
+		g.p("\t\tif err != nil {")
+		g.p("\t\t\treturn nil, fmt.Errorf(\"field %s.%s: %%v\", err)", c.Name, f.Name)
+		g.p("\t\t}")
+		g.p("\t}")
+	}
+	g.p("\treturn v, nil")
+	g.p("}")
+	g.p("")
+}
+
+// codecClasses returns the data classes encoded by the codec of an API, as
+// numbered by Pigeon: the classes used by the methods, and by the fields of
+// these classes, sorted by name.
+func (g *generator) codecClasses(a *api) []*class {
+	seen := make(map[string]bool)
+	var names []string
+	var visit func(t typeRef)
+	visit = func(t typeRef) {
+		for _, arg := range t.Args {
+			visit(arg)
+		}
+		c, ok := g.classes[t.Name]
+		if !ok || seen[t.Name] {
+			return
+		}
+		seen[t.Name] = true
+		names = append(names, t.Name)
+		for _, f := range c.Fields {
+			visit(f.Type)
+		}
+	}
+	for _, m := range a.Methods {
+		visit(m.Returns)
+		for _, arg := range m.Args {
+			visit(arg.Type)
+		}
+	}
+	sort.Strings(names)
+	classes := make([]*class, 0, len(names))
+	for _, name := range names {
+		classes = append(classes, g.classes[name])
+	}
+	return classes
+}
+
+// codecName returns the name of the codec variable of an API.
+func codecName(a *api) string {
+	return "pigeon" + a.Name + "Codec"
+}
+
+// genCodec writes the codec of an API. Pigeon generates a codec per API,
+// whose type discriminators depend on the data classes the API uses.
+func (g *generator) genCodec(a *api) {
+	classes := g.codecClasses(a)
+	name := codecName(a)
+	if len(classes) == 0 {
+		g.p("// %s is the StandardMessageCodec, %s uses no data class.", name, a.Name)
+		g.p("var %s = plugin.StandardMessageCodec{}", name)
+		g.p("")
+		return
+	}
+
+	g.p("// %s is the StandardMessageCodec extended with the", name)
+	g.p("// data classes used by %s.", a.Name)
+	g.p("var %s = plugin.StandardMessageCodec{Extension: %sExtension{}}", name, name)
+	g.p("")
+	g.p("type %sExtension struct{}", name)
+	g.p("")
+	g.p("func (%sExtension) EncodeValue(value interface{}) (byte, interface{}, bool) {", name)
+	g.p("\tswitch v := value.(type) {")
+	for i, c := range classes {
+		g.p("\tcase *%s:", c.Name)
+		g.p("\t\tif v != nil {")
+		g.p("\t\t\treturn %d, v.pigeonEncode(), true", firstCustomType+i)
+		g.p("\t\t}")
+	}
+	g.p("\t}")
+	g.p("\treturn 0, nil, false")
+	g.p("}")
+	g.p("")
+	g.p("func (%sExtension) DecodeValue(valueType byte, content interface{}) (interface{}, error) {", name)
+	g.p("\tswitch valueType {")
+	for i, c := range classes {
+		g.p("\tcase %d:", firstCustomType+i)
+		g.p("\t\treturn pigeonDecode%sFields(content)", c.Name)
+	}
+	g.p("\t}")
+	g.p("\treturn nil, fmt.Errorf(\"unknown pigeon value type %%d\", valueType)")
+	g.p("}")
+	g.p("")
+}
+
+func (g *generator) channelName(a *api, m method) string {
+	return g.channelPrefix + "." + a.Name + "." + m.Name
+}
+
+// goSignature returns the Go signature of an API method, without its name.
+func (g *generator) goSignature(m method) string {
+	args := make([]string, 0, len(m.Args))
+	for _, arg := range m.Args {
+		args = append(args, goArgName(arg.Name)+" "+g.goType(arg.Type))
+	}
+	if m.Returns.isVoid() {
+		return "(" + strings.Join(args, ", ") + ") error"
+	}
+	return "(" + strings.Join(args, ", ") + ") (" + g.goType(m.Returns) + ", error)"
+}
+
+func (g *generator) genHostAPI(a *api) {
+	g.p("// %s is implemented in Go and called from Dart.", a.Name)
+	g.p("//")
+	g.p("// Returning a *plugin.Error from a method sets the code of the")
+	g.p("// PlatformException thrown on the Dart side.")
+	g.p("type %s interface {", a.Name)
+	for _, m := range a.Methods {
+		if m.Async {
+			g.p("\t// %s is called outside of the platform thread.", exported(m.Name))
+		}
+		g.p("\t%s%s", exported(m.Name), g.goSignature(m))
+	}
+	g.p("}")
+	g.p("")
+
+	g.p("// SetUp%s registers api to handle the calls made from Dart. A nil api", a.Name)
+	g.p("// unregisters the handlers.")
+	g.p("func SetUp%s(messenger plugin.BinaryMessenger, api %s) {", a.Name, a.Name)
+	for _, m := range a.Methods {
+		name := g.channelName(a, m)
+		g.p("\tif api == nil {")
+		g.p("\t\tmessenger.SetChannelHandler(%q, nil)", name)
+		g.p("\t} else {")
+		g.p("\t\tpigeonHandle(messenger, %q, %s, %t, func(args []interface{}) (interface{}, error) {", name, codecName(a), m.Async)
+		g.p("\t\t\tif len(args) != %d {", len(m.Args))
+		g.p("\t\t\t\treturn nil, fmt.Errorf(\"%s expects %d arguments, got %%d\", len(args))", m.Name, len(m.Args))
+		g.p("\t\t\t}")
+		callArgs := make([]string, 0, len(m.Args))
+		for i, arg := range m.Args {
+			v := "arg" + exported(arg.Name)
+			g.p("\t\t\t%s, err := %s", v, g.decodeCall(arg.Type, fmt.Sprintf("args[%d]", i)))
+			g.p("\t\t\tif err != nil {")
+			g.p("\t\t\t\treturn nil, fmt.Errorf(\"argument %s: %%v\", err)", arg.Name)
+			g.p("\t\t\t}")
+			callArgs = append(callArgs, v)
+		}
+		call := "api." + exported(m.Name) + "(" + strings.Join(callArgs, ", ") + ")"
+		if m.Returns.isVoid() {
+			g.p("\t\t\treturn nil, %s", call)
+		} else {
+			g.p("\t\t\tresult, err := %s", call)
+			g.p("\t\t\tif err != nil {")
+			g.p("\t\t\t\treturn nil, err")
+			g.p("\t\t\t}")
+			g.p("\t\t\treturn %s, nil", g.encodeCall(m.Returns, "result"))
+		}
+		g.p("\t\t})")
+		g.p("\t}")
+	}
+	g.p("}")
+	g.p("")
+}
+
+func (g *generator) genFlutterAPI(a *api) {
+	g.p("// %s is implemented in Dart and called from Go.", a.Name)
+	g.p("type %s struct {", a.Name)
+	for _, m := range a.Methods {
+		g.p("\t%s *plugin.BasicMessageChannel", goIdent(m.Name))
+	}
+	g.p("}")
+	g.p("")
+
+	g.p("// New%s creates the channels used to call the Dart implementation.", a.Name)
+	g.p("func New%s(messenger plugin.BinaryMessenger) *%s {", a.Name, a.Name)
+	g.p("\treturn &%s{", a.Name)
+	for _, m := range a.Methods {
+		g.p("\t\t%s: plugin.NewBasicMessageChannel(messenger, %q, %s),", goIdent(m.Name), g.channelName(a, m), codecName(a))
+	}
+	g.p("\t}")
+	g.p("}")
+	g.p("")
+
+	for _, m := range a.Methods {
+		args := "nil"
+		if len(m.Args) > 0 {
+			encoded := make([]string, 0, len(m.Args))
+			for _, arg := range m.Args {
+				encoded = append(encoded, g.encodeCall(arg.Type, goArgName(arg.Name)))
+			}
+			args = "[]interface{}{" + strings.Join(encoded, ", ") + "}"
+		}
+
+		g.p("// %s calls %s.%s on the Dart side and waits for its reply.", exported(m.Name), a.Name, m.Name)
+		g.p("func (a *%s) %s%s {", a.Name, exported(m.Name), g.goSignature(m))
+		if m.Returns.isVoid() {
+			g.p("\t_, err := pigeonSend(a.%s, %s)", goIdent(m.Name), args)
+			g.p("\treturn err")
+		} else {
+			g.p("\treply, err := pigeonSend(a.%s, %s)", goIdent(m.Name), args)
+			g.p("\tif err != nil {")
+			g.p("\t\treturn %s, err", g.zeroValue(m.Returns))
+			g.p("\t}")
+			g.p("\treturn %s", g.decodeCall(m.Returns, "reply"))
+		}
+		g.p("}")
+		g.p("")
+	}
+}
+
+// genRuntime writes the helpers shared by the generated APIs.
+func (g *generator) genRuntime() {
+	g.p(`// pigeonHandle registers a handler on a host API channel. Pigeon replies are
+// a list holding the result, or the code, message and details of an error.
+func pigeonHandle(messenger plugin.BinaryMessenger, channelName string, codec plugin.StandardMessageCodec, async bool, handler func(args []interface{}) (interface{}, error)) {
+	handleMessage := func(message interface{}) interface{} {
+		var args []interface{}
+		if message != nil {
+			var ok bool
+			args, ok = message.([]interface{})
+			if !ok {
+				return pigeonWrapError(fmt.Errorf("expected a list of arguments, got %%T", message))
+			}
+		}
+		result, err := handler(args)
+		if err != nil {
+			return pigeonWrapError(err)
+		}
+		return []interface{}{result}
+	}
+
+	if !async {
+		channel := plugin.NewBasicMessageChannel(messenger, channelName, codec)
+		channel.HandleFunc(func(message interface{}) (interface{}, error) {
+			return handleMessage(message), nil
+		})
+		return
+	}
+
+	messenger.SetChannelHandler(channelName, func(binaryMessage []byte, r plugin.ResponseSender) error {
+		message, err := codec.DecodeMessage(binaryMessage)
+		if err != nil {
+			r.Send(nil)
+			return err
+		}
+		go func() {
+			binaryReply, err := codec.EncodeMessage(handleMessage(message))
+			if err != nil {
+				fmt.Printf("go-flutter: failed to encode reply on channel '%%s': %%v\n", channelName, err)
+			}
+			r.Send(binaryReply)
+		}()
+		return nil
+	})
+}
+
+func pigeonWrapError(err error) []interface{} {
+	code := "error"
+	if e, ok := err.(*plugin.Error); ok {
+		code = e.Code()
+	}
+	return []interface{}{code, err.Error(), nil}
+}
+
+// pigeonSend sends a message to a Flutter API channel and unwraps its reply.
+func pigeonSend(channel *plugin.BasicMessageChannel, message interface{}) (interface{}, error) {
+	reply, err := channel.SendWithReply(message)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := reply.([]interface{})
+	if !ok {
+		return nil, plugin.FlutterError{
+			Code:    "channel-error",
+			Message: "Unable to establish connection on channel.",
+		}
+	}
+	if len(list) == 0 {
+		// the reply of a void method
+		return nil, nil
+	}
+	if len(list) > 1 {
+		flutterErr := plugin.FlutterError{}
+		flutterErr.Code, _ = list[0].(string)
+		flutterErr.Message, _ = list[1].(string)
+		if len(list) > 2 {
+			flutterErr.Details = list[2]
+		}
+		return nil, flutterErr
+	}
+	return list[0], nil
+}
+
+func pigeonToInt64(v interface{}) (int64, error) {
+	switch i := v.(type) {
+	case int32:
+		return int64(i), nil
+	case int64:
+		return i, nil
+	}
+	return 0, fmt.Errorf("expected int, got %%T", v)
+}`)
+}
+
+// typeKey returns a name identifying t, used to name the helpers.
+func typeKey(t typeRef) string {
+	var key string
+	switch t.Name {
+	case "List":
+		key = "ListOf" + typeKey(t.Args[0])
+	case "Map":
+		key = "MapOf" + typeKey(t.Args[0]) + "To" + typeKey(t.Args[1])
+	default:
+		key = exported(t.Name)
+	}
+	if t.Nullable {
+		key = "Nullable" + key
+	}
+	return key
+}
+
+func (g *generator) goType(t typeRef) string {
+	switch t.Name {
+	case "List":
+		return "[]" + g.goType(t.Args[0])
+	case "Map":
+		key := t.Args[0]
+		key.Nullable = false
+		return "map[" + g.goType(key) + "]" + g.goType(t.Args[1])
+	}
+	if _, ok := g.classes[t.Name]; ok {
+		return "*" + t.Name
+	}
+	if _, ok := g.enums[t.Name]; ok {
+		if t.Nullable {
+			return "*" + t.Name
+		}
+		return t.Name
+	}
+	goType := builtinTypes[t.Name]
+	if t.Nullable && isScalar(t.Name) {
+		return "*" + goType
+	}
+	return goType
+}
+
+// isScalar reports whether a builtin type is represented by a non-nillable
+// Go value.
+func isScalar(name string) bool {
+	switch name {
+	case "String", "int", "double", "bool":
+		return true
+	}
+	return false
+}
+
+func (g *generator) zeroValue(t typeRef) string {
+	goType := g.goType(t)
+	switch {
+	case t.Nullable || strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") ||
+		strings.HasPrefix(goType, "map[") || goType == "interface{}":
+		return "nil"
+	case goType == "string":
+		return `""`
+	case goType == "bool":
+		return "false"
+	}
+	return "0"
+}
+
+func (g *generator) encodeCall(t typeRef, expr string) string {
+	g.helpers[typeKey(t)] = t
+	return "pigeonEncode" + typeKey(t) + "(" + expr + ")"
+}
+
+func (g *generator) decodeCall(t typeRef, expr string) string {
+	g.helpers[typeKey(t)] = t
+	return "pigeonDecode" + typeKey(t) + "(" + expr + ")"
+}
+
+// genHelpers writes the encode and decode helpers of every type used, in a
+// stable order. Writing a helper may require other helpers.
+func (g *generator) genHelpers() {
+	done := make(map[string]bool)
+	for {
+		var keys []string
+		for key := range g.helpers {
+			if !done[key] {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			done[key] = true
+			g.genEncodeHelper(key, g.helpers[key])
+			g.genDecodeHelper(key, g.helpers[key])
+		}
+	}
+}
+
+func (g *generator) genEncodeHelper(key string, t typeRef) {
+	goType := g.goType(t)
+	g.p("func pigeonEncode%s(v %s) interface{} {", key, goType)
+	nillable := t.Nullable || strings.HasPrefix(goType, "*")
+	switch {
+	case t.Name == "List":
+		if nillable {
+			g.p("\tif v == nil {")
+			g.p("\t\treturn nil")
+			g.p("\t}")
+		}
+		g.p("\tlist := make([]interface{}, 0, len(v))")
+		g.p("\tfor _, e := range v {")
+		g.p("\t\tlist = append(list, %s)", g.encodeCall(t.Args[0], "e"))
+		g.p("\t}")
+		g.p("\treturn list")
+	case t.Name == "Map":
+		if nillable {
+			g.p("\tif v == nil {")
+			g.p("\t\treturn nil")
+			g.p("\t}")
+		}
+		key := t.Args[0]
+		key.Nullable = false
+		g.p("\tm := make(map[interface{}]interface{}, len(v))")
+		g.p("\tfor k, e := range v {")
+		g.p("\t\tm[%s] = %s", g.encodeCall(key, "k"), g.encodeCall(t.Args[1], "e"))
+		g.p("\t}")
+		g.p("\treturn m")
+	case g.classes[t.Name] != nil:
+		g.p("\tif v == nil {")
+		g.p("\t\treturn nil")
+		g.p("\t}")
+		g.p("\treturn v")
+	case g.enums[t.Name] != nil:
+		if t.Nullable {
+			g.p("\tif v == nil {")
+			g.p("\t\treturn nil")
+			g.p("\t}")
+			g.p("\treturn int64(*v)")
+		} else {
+			g.p("\treturn int64(v)")
+		}
+	case t.Nullable && isScalar(t.Name):
+		g.p("\tif v == nil {")
+		g.p("\t\treturn nil")
+		g.p("\t}")
+		g.p("\treturn *v")
+	case t.Nullable && t.Name != "Object":
+		g.p("\tif v == nil {")
+		g.p("\t\treturn nil")
+		g.p("\t}")
+		g.p("\treturn v")
+	default:
+		g.p("\treturn v")
+	}
+	g.p("}")
+	g.p("")
+}
+
+func (g *generator) genDecodeHelper(key string, t typeRef) {
+	goType := g.goType(t)
+	g.p("func pigeonDecode%s(v interface{}) (%s, error) {", key, goType)
+	if t.Name != "Object" {
+		g.p("\tif v == nil {")
+		if t.Nullable {
+			g.p("\t\treturn nil, nil")
+		} else {
+			g.p("\t\treturn %s, fmt.Errorf(\"unexpected null %s\")", g.zeroValue(t), t.String())
+		}
+		g.p("\t}")
+	}
+
+	nonNull := t
+	nonNull.Nullable = false
+	zero := g.zeroValue(t)
+	switch {
+	case t.Name == "List":
+		g.p("\tlist, ok := v.([]interface{})")
+		g.p("\tif !ok {")
+		g.p("\t\treturn nil, fmt.Errorf(\"expected %s, got %%T\", v)", t.String())
+		g.p("\t}")
+		g.p("\tresult := make(%s, 0, len(list))", goType)
+		g.p("\tfor _, e := range list {")
+		g.p("\t\tdecoded, err := %s", g.decodeCall(t.Args[0], "e"))
+		g.p("\t\tif err != nil {")
+		g.p("\t\t\treturn nil, err")
+		g.p("\t\t}")
+		g.p("\t\tresult = append(result, decoded)")
+		g.p("\t}")
+		g.p("\treturn result, nil")
+	case t.Name == "Map":
+		keyType := t.Args[0]
+		keyType.Nullable = false
+		g.p("\tm, ok := v.(map[interface{}]interface{})")
+		g.p("\tif !ok {")
+		g.p("\t\treturn nil, fmt.Errorf(\"expected %s, got %%T\", v)", t.String())
+		g.p("\t}")
+		g.p("\tresult := make(%s, len(m))", goType)
+		g.p("\tfor k, e := range m {")
+		g.p("\t\tdecodedKey, err := %s", g.decodeCall(keyType, "k"))
+		g.p("\t\tif err != nil {")
+		g.p("\t\t\treturn nil, err")
+		g.p("\t\t}")
+		g.p("\t\tresult[decodedKey], err = %s", g.decodeCall(t.Args[1], "e"))
+		g.p("\t\tif err != nil {")
+		g.p("\t\t\treturn nil, err")
+		g.p("\t\t}")
+		g.p("\t}")
+		g.p("\treturn result, nil")
+	case g.classes[t.Name] != nil:
+		g.p("\tc, ok := v.(*%s)", t.Name)
+		g.p("\tif !ok {")
+		g.p("\t\treturn nil, fmt.Errorf(\"expected %s, got %%T\", v)", t.Name)
+		g.p("\t}")
+		g.p("\treturn c, nil")
+	case g.enums[t.Name] != nil:
+		g.p("\ti, err := pigeonToInt64(v)")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn %s, err", zero)
+		g.p("\t}")
+		g.p("\tif i < 0 || i >= %d {", len(g.enums[t.Name].Values))
+		g.p("\t\treturn %s, fmt.Errorf(\"invalid %s index %%d\", i)", zero, t.Name)
+		g.p("\t}")
+		g.p("\te := %s(i)", t.Name)
+		if t.Nullable {
+			g.p("\treturn &e, nil")
+		} else {
+			g.p("\treturn e, nil")
+		}
+	case t.Name == "Object":
+		g.p("\treturn v, nil")
+	case t.Name == "int":
+		g.p("\ti, err := pigeonToInt64(v)")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn %s, err", zero)
+		g.p("\t}")
+		if t.Nullable {
+			g.p("\treturn &i, nil")
+		} else {
+			g.p("\treturn i, nil")
+		}
+	default:
+		g.p("\tdecoded, ok := v.(%s)", g.goType(nonNull))
+		g.p("\tif !ok {")
+		g.p("\t\treturn %s, fmt.Errorf(\"expected %s, got %%T\", v)", zero, t.Name)
+		g.p("\t}")
+		if t.Nullable && isScalar(t.Name) {
+			g.p("\treturn &decoded, nil")
+		} else {
+			g.p("\treturn decoded, nil")
+		}
+	}
+	g.p("}")
+	g.p("")
+}
+
+// exported returns name with its first letter in upper case.
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// flutterAPILocals are the identifiers declared by the generated methods of
+// a Flutter API: the receiver and the locals.
+var flutterAPILocals = map[string]bool{"a": true, "reply": true, "err": true}
+
+// goArgName returns the Go name of a method argument, renamed when it
+// collides with a Go keyword or with the identifiers of the generated
+// methods.
+func goArgName(name string) string {
+	if flutterAPILocals[name] {
+		return name + "Arg"
+	}
+	return goIdent(name)
+}
+
+// goIdent returns name, renamed when it collides with a Go keyword.
+func goIdent(name string) string {
+	switch name {
+	case "break", "case", "chan", "const", "continue", "default", "defer",
+		"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+		"interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var":
+		return name + "Arg"
+	}
+	return name
+}
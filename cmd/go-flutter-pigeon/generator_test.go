@@ -0,0 +1,156 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestdata(t *testing.T, path string) []byte {
+	src, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var def *definition
+	if path[len(path)-5:] == ".json" {
+		def, err = parseJSON(src)
+	} else {
+		def, err = parseDart(string(src))
+	}
+	require.NoError(t, err)
+	out, err := generate(def, "library", "dev.flutter.pigeon")
+	require.NoError(t, err)
+	return out
+}
+
+func TestGenerateDartAndJSON(t *testing.T) {
+	fromDart := generateTestdata(t, "testdata/messages.dart")
+	fromJSON := generateTestdata(t, "testdata/messages.json")
+	assert.Equal(t, string(fromDart), string(fromJSON))
+	typeCheck(t, fromDart)
+}
+
+// typeCheck checks that the generated code compiles.
+func typeCheck(t *testing.T, src []byte) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "messages.go", src, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("library", fset, []*ast.File{file}, nil)
+	assert.NoError(t, err)
+}
+
+// TestGeneratedLibraryUpToDate checks the code tested against a fake Dart side
+// in the library package, run go generate there to update it.
+func TestGeneratedLibraryUpToDate(t *testing.T) {
+	generated, err := ioutil.ReadFile("internal/library/messages.pigeon.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(generateTestdata(t, "testdata/messages.dart")), string(generated))
+}
+
+// TestGenerateCodecs checks the type discriminators of the data classes
+// against the codecs of the Dart code generated by Pigeon.
+func TestGenerateCodecs(t *testing.T) {
+	dart, err := ioutil.ReadFile("testdata/codecs.pigeon.dart")
+	require.NoError(t, err)
+	expected := codecTypes(t, string(dart),
+		`class _(\w+)Codec extends StandardMessageCodec`,
+		`value is (\w+)\) \{\s+buffer\.putUint8\((\d+)\)`)
+
+	out := generateTestdata(t, "testdata/codecs.dart")
+	typeCheck(t, out)
+	generated := string(out)
+	got := codecTypes(t, generated,
+		`func \(pigeon(\w+)CodecExtension\) EncodeValue`,
+		`case \*(\w+):\s+if v != nil \{\s+return (\d+),`)
+	assert.Equal(t, expected, got)
+	assert.Contains(t, generated, "var pigeonCounterApiCodec = plugin.StandardMessageCodec{}")
+}
+
+// codecTypes returns the type discriminators of the data classes, by class
+// name, of each codec found in src.
+func codecTypes(t *testing.T, src string, codecPattern, typePattern string) map[string]map[string]string {
+	codecs := make(map[string]map[string]string)
+	codecRe := regexp.MustCompile(codecPattern)
+	typeRe := regexp.MustCompile(typePattern)
+	starts := codecRe.FindAllStringSubmatchIndex(src, -1)
+	require.NotEmpty(t, starts)
+	for i, start := range starts {
+		end := len(src)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		types := make(map[string]string)
+		for _, match := range typeRe.FindAllStringSubmatch(src[start[1]:end], -1) {
+			types[match[1]] = match[2]
+		}
+		codecs[src[start[2]:start[3]]] = types
+	}
+	return codecs
+}
+
+func TestGenerateRenamesArguments(t *testing.T) {
+	def, err := parseDart(`
+@FlutterApi()
+abstract class Events {
+  void collide(String a, int reply, bool err, String type);
+}`)
+	require.NoError(t, err)
+	out, err := generate(def, "events", "dev.flutter.pigeon")
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(out),
+		"Collide(aArg string, replyArg int64, errArg bool, typeArg string) error"), string(out))
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "events.go", out, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("events", fset, []*ast.File{file}, nil)
+	assert.NoError(t, err)
+}
+
+func TestParseMethodAnnotations(t *testing.T) {
+	def, err := parseDart(`
+@HostApi()
+abstract class Api {
+  @Deprecated('use pong (soon)')
+  @ObjCSelector("ping:")
+  @async
+  void ping(int a);
+  @pigeon.TaskQueue(type: TaskQueueType.serialBackgroundThread)
+  void pong();
+}`)
+	require.NoError(t, err)
+	methods := def.APIs[0].Methods
+	require.Len(t, methods, 2)
+	assert.Equal(t, "ping", methods[0].Name)
+	assert.True(t, methods[0].Async)
+	assert.Equal(t, "pong", methods[1].Name)
+	assert.False(t, methods[1].Async)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown type":            `class A { Foo? foo; }`,
+		"list argument":           `class A { List<int, int>? list; }`,
+		"missing api kind":        `abstract class Api { void ping(); }`,
+		"named arguments":         `@HostApi() abstract class Api { void ping({int a}); }`,
+		"void field":              `class A { void a; }`,
+		"invalid annotation":      `@HostApi() abstract class Api { @ void ping(); }`,
+		"unterminated annotation": `@HostApi() abstract class Api { @Deprecated('x' void ping(); }`,
+	}
+	for name, src := range tests {
+		def, err := parseDart(src)
+		if err == nil {
+			err = def.validate()
+		}
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,5 @@
+// Package library holds the code generated from testdata/messages.dart, to
+// test it against a messenger emulating the Dart side.
+package library
+
+//go:generate go run ../.. -input ../../testdata/messages.dart -output messages.pigeon.go
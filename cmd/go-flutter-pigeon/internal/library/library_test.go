@@ -0,0 +1,108 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// dartMessenger emulates the Dart side of the Flutter APIs: the replies are
+// given by channel, and the messages received are recorded.
+type dartMessenger struct {
+	replies  map[string]interface{}
+	messages map[string]interface{}
+}
+
+var _ plugin.BinaryMessenger = &dartMessenger{}
+
+func newDartMessenger() *dartMessenger {
+	return &dartMessenger{
+		replies:  make(map[string]interface{}),
+		messages: make(map[string]interface{}),
+	}
+}
+
+func (m *dartMessenger) SendWithReply(channel string, binaryMessage []byte) ([]byte, error) {
+	message, err := pigeonLibraryEventsCodec.DecodeMessage(binaryMessage)
+	if err != nil {
+		return nil, err
+	}
+	m.messages[channel] = message
+	reply, ok := m.replies[channel]
+	if !ok {
+		// no handler registered on the Dart side
+		return nil, nil
+	}
+	return pigeonLibraryEventsCodec.EncodeMessage(reply)
+}
+
+func (m *dartMessenger) Send(channel string, binaryMessage []byte) error {
+	_, err := m.SendWithReply(channel, binaryMessage)
+	return err
+}
+
+func (m *dartMessenger) SetChannelHandler(channel string, handler plugin.ChannelHandlerFunc) {}
+
+func TestFlutterAPIVoidMethod(t *testing.T) {
+	messenger := newDartMessenger()
+	// Pigeon replies to void methods with an empty list
+	messenger.replies["dev.flutter.pigeon.LibraryEvents.onAdded"] = []interface{}{}
+	events := NewLibraryEvents(messenger)
+
+	title := "Dune"
+	err := events.OnAdded(&Book{Title: &title})
+	require.NoError(t, err)
+	message := messenger.messages["dev.flutter.pigeon.LibraryEvents.onAdded"].([]interface{})
+	require.Len(t, message, 1)
+	book, ok := message[0].(*Book)
+	require.True(t, ok)
+	assert.Equal(t, "Dune", *book.Title)
+}
+
+func TestFlutterAPIResult(t *testing.T) {
+	messenger := newDartMessenger()
+	messenger.replies["dev.flutter.pigeon.LibraryEvents.greet"] = []interface{}{"hello"}
+	events := NewLibraryEvents(messenger)
+
+	greeting, err := events.Greet("reader", GenreScience)
+	require.NoError(t, err)
+	require.NotNil(t, greeting)
+	assert.Equal(t, "hello", *greeting)
+	assert.Equal(t, []interface{}{"reader", int64(GenreScience)},
+		messenger.messages["dev.flutter.pigeon.LibraryEvents.greet"])
+
+	// a null result of a nullable return type
+	messenger.replies["dev.flutter.pigeon.LibraryEvents.greet"] = []interface{}{nil}
+	greeting, err = events.Greet("reader", GenreFiction)
+	require.NoError(t, err)
+	assert.Nil(t, greeting)
+}
+
+func TestFlutterAPIRenamedArguments(t *testing.T) {
+	messenger := newDartMessenger()
+	messenger.replies["dev.flutter.pigeon.LibraryEvents.rate"] = []interface{}{}
+	events := NewLibraryEvents(messenger)
+
+	err := events.Rate("Dune", 5, true)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"Dune", int64(5), true},
+		messenger.messages["dev.flutter.pigeon.LibraryEvents.rate"])
+}
+
+func TestFlutterAPIErrors(t *testing.T) {
+	messenger := newDartMessenger()
+	messenger.replies["dev.flutter.pigeon.LibraryEvents.greet"] = []interface{}{"unknown-reader", "who are you?", "details"}
+	events := NewLibraryEvents(messenger)
+
+	_, err := events.Greet("reader", GenreScience)
+	assert.Equal(t, plugin.FlutterError{Code: "unknown-reader", Message: "who are you?", Details: "details"}, err)
+
+	// without Dart handler, the reply is null
+	err = events.OnAdded(&Book{})
+	flutterErr, ok := err.(plugin.FlutterError)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, "channel-error", flutterErr.Code)
+}
@@ -0,0 +1,84 @@
+// Command go-flutter-pigeon generates Go bindings for Pigeon APIs.
+//
+// Pigeon (https://pub.dev/packages/pigeon) generates the Dart side of typed
+// platform channels from an API definition. go-flutter-pigeon reads the same
+// definition, or its JSON equivalent, and generates the Go side on top of
+// plugin.BasicMessageChannel and plugin.StandardMessageCodec:
+//
+//   - data classes become structs, enums become int types,
+//   - @HostApi() classes become Go interfaces, registered with SetUp<Api>,
+//   - @FlutterApi() classes become structs whose methods call Dart.
+//
+// It is meant to be used with go generate:
+//
+//	//go:generate go run github.com/go-flutter-desktop/go-flutter/cmd/go-flutter-pigeon -input pigeons/messages.dart -output messages.pigeon.go
+//
+// Data classes are encoded as lists of fields with type discriminators starting
+// at 128, in declaration order. Declare them in the same order as the Dart
+// input given to Pigeon.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	input := flag.String("input", "", "path to the Pigeon definition, a .dart or .json file")
+	output := flag.String("output", "", "path to the generated Go file (default: stdout)")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "name of the generated Go package (default: $GOPACKAGE)")
+	channelPrefix := flag.String("channel-prefix", "dev.flutter.pigeon", "prefix of the generated channel names")
+	flag.Parse()
+
+	err := run(*input, *output, *pkg, *channelPrefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-flutter-pigeon: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output, pkg, channelPrefix string) error {
+	if input == "" {
+		return errors.New("missing -input")
+	}
+	if pkg == "" {
+		if output == "" {
+			return errors.New("missing -package")
+		}
+		abs, err := filepath.Abs(filepath.Dir(output))
+		if err != nil {
+			return err
+		}
+		pkg = filepath.Base(abs)
+	}
+
+	src, err := ioutil.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	var def *definition
+	if strings.EqualFold(filepath.Ext(input), ".json") {
+		def, err = parseJSON(src)
+	} else {
+		def, err = parseDart(string(src))
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s", input)
+	}
+
+	generated, err := generate(def, pkg, channelPrefix)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(generated)
+		return err
+	}
+	return ioutil.WriteFile(output, generated, 0644)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	dartLineComment  = regexp.MustCompile(`//[^\n]*`)
+	dartBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	// matches the start of a top-level declaration, including its annotations.
+	dartDeclaration = regexp.MustCompile(`((?:@\w+(?:\([^)]*\))?\s*)*)(abstract\s+)?(class|enum)\s+(\w+)[^{]*\{`)
+	dartAnnotation  = regexp.MustCompile(`@(\w+)`)
+	// matches the name of a leading annotation of a method, possibly
+	// qualified by a library prefix.
+	dartMethodAnnotation = regexp.MustCompile(`^@([\w.]+)`)
+	dartFieldPrefix      = regexp.MustCompile(`^(?:(?:final|late|const)\s+)*`)
+)
+
+// parseDart reads the subset of the Dart language used by Pigeon input files:
+// data classes with typed fields, enums and abstract classes annotated with
+// @HostApi() or @FlutterApi(). Imports, constructors and @ConfigurePigeon are
+// ignored.
+func parseDart(src string) (*definition, error) {
+	src = dartBlockComment.ReplaceAllString(src, "")
+	src = dartLineComment.ReplaceAllString(src, "")
+
+	d := &definition{}
+	for len(src) > 0 {
+		loc := dartDeclaration.FindStringSubmatchIndex(src)
+		if loc == nil {
+			break
+		}
+		annotations := src[loc[2]:loc[3]]
+		abstract := loc[4] != -1
+		keyword := src[loc[6]:loc[7]]
+		name := src[loc[8]:loc[9]]
+
+		body, rest, err := matchBraces(src[loc[1]:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %s", keyword, name)
+		}
+		src = rest
+
+		switch {
+		case keyword == "enum":
+			e := &enum{Name: name}
+			for _, v := range strings.Split(body, ",") {
+				v = strings.TrimSpace(v)
+				if v != "" {
+					e.Values = append(e.Values, v)
+				}
+			}
+			d.Enums = append(d.Enums, e)
+
+		case abstract:
+			a := &api{Name: name}
+			switch {
+			case hasAnnotation(annotations, "HostApi"):
+				a.Kind = hostAPI
+			case hasAnnotation(annotations, "FlutterApi"):
+				a.Kind = flutterAPI
+			default:
+				return nil, errors.Errorf("abstract class %s must be annotated with @HostApi() or @FlutterApi()", name)
+			}
+			for _, member := range splitMembers(body) {
+				m, err := parseDartMethod(member)
+				if err != nil {
+					return nil, errors.Wrapf(err, "api %s", name)
+				}
+				a.Methods = append(a.Methods, m)
+			}
+			d.APIs = append(d.APIs, a)
+
+		default:
+			c := &class{Name: name}
+			for _, member := range splitMembers(body) {
+				if strings.Contains(member, "(") {
+					continue // constructor
+				}
+				f, err := parseDartField(member)
+				if err != nil {
+					return nil, errors.Wrapf(err, "class %s", name)
+				}
+				c.Fields = append(c.Fields, f)
+			}
+			d.Classes = append(d.Classes, c)
+		}
+	}
+	return d, nil
+}
+
+func hasAnnotation(annotations, name string) bool {
+	for _, m := range dartAnnotation.FindAllStringSubmatch(annotations, -1) {
+		if m[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matchBraces returns the content up to the brace closing an already opened
+// one, and the remaining source after that brace.
+func matchBraces(src string) (body string, rest string, err error) {
+	depth := 1
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return src[:i], src[i+1:], nil
+			}
+		}
+	}
+	return "", "", errors.New("unterminated declaration")
+}
+
+// splitMembers splits a class body on semicolons, dropping empty members and
+// constructor bodies.
+func splitMembers(body string) []string {
+	var members []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			depth--
+			if depth == 0 && body[i] == '}' {
+				// end of a constructor body, which isn't terminated by a
+				// semicolon.
+				members = appendMember(members, body[start:i+1])
+				start = i + 1
+			}
+		case ';':
+			if depth == 0 {
+				members = appendMember(members, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return appendMember(members, body[start:])
+}
+
+func appendMember(members []string, member string) []string {
+	member = strings.TrimSpace(member)
+	if member == "" {
+		return members
+	}
+	return append(members, member)
+}
+
+func parseDartField(member string) (field, error) {
+	member = dartFieldPrefix.ReplaceAllString(member, "")
+	t, rest, err := parseTypeRefPrefix(member)
+	if err != nil {
+		return field{}, err
+	}
+	name := strings.TrimSpace(rest)
+	if i := strings.Index(name, "="); i != -1 {
+		// default values are a Dart-side concern.
+		name = strings.TrimSpace(name[:i])
+	}
+	if !isIdent(name) {
+		return field{}, errors.Errorf("invalid field declaration %q", member)
+	}
+	return field{Name: name, Type: t}, nil
+}
+
+func parseDartMethod(member string) (method, error) {
+	var m method
+	for strings.HasPrefix(member, "@") {
+		// annotations other than @async, e.g. @Deprecated() or
+		// @ObjCSelector(), are ignored.
+		name, rest, err := splitDartAnnotation(member)
+		if err != nil {
+			return m, err
+		}
+		if name == "async" {
+			m.Async = true
+		}
+		member = rest
+	}
+
+	var err error
+	var rest string
+	m.Returns, rest, err = parseTypeRefPrefix(member)
+	if err != nil {
+		return m, err
+	}
+	open := strings.Index(rest, "(")
+	close := strings.LastIndex(rest, ")")
+	if open == -1 || close < open {
+		return m, errors.Errorf("invalid method declaration %q", member)
+	}
+	m.Name = strings.TrimSpace(rest[:open])
+	if !isIdent(m.Name) {
+		return m, errors.Errorf("invalid method name in %q", member)
+	}
+	if strings.TrimSpace(rest[close+1:]) != "" {
+		return m, errors.Errorf("unexpected %q after method %s", rest[close+1:], m.Name)
+	}
+
+	for _, arg := range splitArguments(rest[open+1 : close]) {
+		if strings.ContainsAny(arg, "{}[]") {
+			return m, errors.Errorf("method %s: only positional arguments are supported", m.Name)
+		}
+		f, err := parseDartField(arg)
+		if err != nil {
+			return m, errors.Wrapf(err, "method %s", m.Name)
+		}
+		m.Args = append(m.Args, f)
+	}
+	return m, nil
+}
+
+// splitDartAnnotation splits the leading annotation of a member, returning
+// its name and the rest of the member. The annotation arguments may contain
+// parentheses and strings.
+func splitDartAnnotation(member string) (name string, rest string, err error) {
+	loc := dartMethodAnnotation.FindStringSubmatchIndex(member)
+	if loc == nil {
+		return "", "", errors.Errorf("invalid annotation in %q", member)
+	}
+	name = member[loc[2]:loc[3]]
+	rest = strings.TrimSpace(member[loc[1]:])
+	if !strings.HasPrefix(rest, "(") {
+		return name, rest, nil
+	}
+
+	depth := 0
+	var quote byte
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return name, strings.TrimSpace(rest[i+1:]), nil
+			}
+		}
+	}
+	return "", "", errors.Errorf("unterminated annotation @%s in %q", name, member)
+}
+
+// splitArguments splits an argument list on the commas that are not part of
+// a generic type.
+func splitArguments(s string) []string {
+	var args []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = appendMember(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return appendMember(args, s[start:])
+}
+
+func isIdent(s string) bool {
+	if s == "" || ('0' <= s[0] && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
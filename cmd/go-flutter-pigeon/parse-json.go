@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// jsonDefinition is the JSON equivalent of a Pigeon Dart file. Types are
+// written with the Dart syntax, for example:
+//
+//	{
+//	  "classes": [{"name": "Book", "fields": [{"name": "title", "type": "String?"}]}],
+//	  "enums": [{"name": "State", "values": ["pending", "done"]}],
+//	  "hostApis": [{"name": "BookApi", "methods": [
+//	    {"name": "search", "args": [{"name": "keyword", "type": "String"}], "returns": "List<Book?>"}
+//	  ]}],
+//	  "flutterApis": [{"name": "BookEvents", "methods": [
+//	    {"name": "onBookAdded", "args": [{"name": "book", "type": "Book"}], "returns": "void"}
+//	  ]}]
+//	}
+type jsonDefinition struct {
+	Classes []struct {
+		Name   string      `json:"name"`
+		Fields []jsonField `json:"fields"`
+	} `json:"classes"`
+	Enums []struct {
+		Name   string   `json:"name"`
+		Values []string `json:"values"`
+	} `json:"enums"`
+	HostAPIs    []jsonAPI `json:"hostApis"`
+	FlutterAPIs []jsonAPI `json:"flutterApis"`
+}
+
+type jsonAPI struct {
+	Name    string `json:"name"`
+	Methods []struct {
+		Name    string      `json:"name"`
+		Args    []jsonField `json:"args"`
+		Returns string      `json:"returns"`
+		Async   bool        `json:"async"`
+	} `json:"methods"`
+}
+
+type jsonField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func parseJSON(src []byte) (*definition, error) {
+	var j jsonDefinition
+	err := json.Unmarshal(src, &j)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode json definition")
+	}
+
+	d := &definition{}
+	for _, jc := range j.Classes {
+		c := &class{Name: jc.Name}
+		c.Fields, err = convertJSONFields(jc.Fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "class %s", jc.Name)
+		}
+		d.Classes = append(d.Classes, c)
+	}
+	for _, je := range j.Enums {
+		d.Enums = append(d.Enums, &enum{Name: je.Name, Values: je.Values})
+	}
+	for _, group := range []struct {
+		kind apiKind
+		apis []jsonAPI
+	}{{hostAPI, j.HostAPIs}, {flutterAPI, j.FlutterAPIs}} {
+		for _, ja := range group.apis {
+			a := &api{Name: ja.Name, Kind: group.kind}
+			for _, jm := range ja.Methods {
+				m := method{Name: jm.Name, Async: jm.Async}
+				m.Args, err = convertJSONFields(jm.Args)
+				if err != nil {
+					return nil, errors.Wrapf(err, "api %s method %s", ja.Name, jm.Name)
+				}
+				returns := jm.Returns
+				if returns == "" {
+					returns = "void"
+				}
+				m.Returns, err = parseTypeRef(returns)
+				if err != nil {
+					return nil, errors.Wrapf(err, "api %s method %s", ja.Name, jm.Name)
+				}
+				a.Methods = append(a.Methods, m)
+			}
+			d.APIs = append(d.APIs, a)
+		}
+	}
+	return d, nil
+}
+
+func convertJSONFields(jfs []jsonField) ([]field, error) {
+	fields := make([]field, 0, len(jfs))
+	for _, jf := range jfs {
+		t, err := parseTypeRef(jf.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s", jf.Name)
+		}
+		if !isIdent(jf.Name) {
+			return nil, errors.Errorf("invalid field name %q", jf.Name)
+		}
+		fields = append(fields, field{Name: jf.Name, Type: t})
+	}
+	return fields, nil
+}
@@ -0,0 +1,52 @@
+import 'package:pigeon/pigeon.dart';
+
+// The classes are declared out of alphabetical order, Pigeon numbers them by
+// name in the codec of each API.
+
+enum Shape {
+  round,
+  long,
+}
+
+class Zebra {
+  String? name;
+  Mango? favorite;
+}
+
+class Mango {
+  Shape? shape;
+  List<Kiwi?>? neighbours;
+}
+
+class Kiwi {
+  int? weight;
+}
+
+class Apple {
+  String? variety;
+}
+
+class Unused {
+  bool? flag;
+}
+
+@HostApi()
+abstract class ZooApi {
+  Zebra find(String name);
+  void plant(Apple apple);
+}
+
+@HostApi()
+abstract class OrchardApi {
+  List<Apple?> pick(Map<String?, Kiwi?> basket);
+}
+
+@FlutterApi()
+abstract class ZooEvents {
+  void onBorn(Zebra zebra, Shape shape);
+}
+
+@FlutterApi()
+abstract class CounterApi {
+  int count(String name);
+}
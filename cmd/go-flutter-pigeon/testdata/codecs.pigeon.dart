@@ -0,0 +1,102 @@
+// The codecs of the Dart code generated by Pigeon for codecs.dart, the
+// classes of each API are numbered from 128 by name.
+// ignore_for_file: public_member_api_docs, non_constant_identifier_names
+
+class _ZooApiCodec extends StandardMessageCodec {
+  const _ZooApiCodec();
+  @override
+  void writeValue(WriteBuffer buffer, Object? value) {
+    if (value is Apple) {
+      buffer.putUint8(128);
+      writeValue(buffer, value.encode());
+    } else if (value is Kiwi) {
+      buffer.putUint8(129);
+      writeValue(buffer, value.encode());
+    } else if (value is Mango) {
+      buffer.putUint8(130);
+      writeValue(buffer, value.encode());
+    } else if (value is Zebra) {
+      buffer.putUint8(131);
+      writeValue(buffer, value.encode());
+    } else {
+      super.writeValue(buffer, value);
+    }
+  }
+
+  @override
+  Object? readValueOfType(int type, ReadBuffer buffer) {
+    switch (type) {
+      case 128: 
+        return Apple.decode(readValue(buffer)!);
+      case 129: 
+        return Kiwi.decode(readValue(buffer)!);
+      case 130: 
+        return Mango.decode(readValue(buffer)!);
+      case 131: 
+        return Zebra.decode(readValue(buffer)!);
+      default:
+        return super.readValueOfType(type, buffer);
+    }
+  }
+}
+
+class _OrchardApiCodec extends StandardMessageCodec {
+  const _OrchardApiCodec();
+  @override
+  void writeValue(WriteBuffer buffer, Object? value) {
+    if (value is Apple) {
+      buffer.putUint8(128);
+      writeValue(buffer, value.encode());
+    } else if (value is Kiwi) {
+      buffer.putUint8(129);
+      writeValue(buffer, value.encode());
+    } else {
+      super.writeValue(buffer, value);
+    }
+  }
+
+  @override
+  Object? readValueOfType(int type, ReadBuffer buffer) {
+    switch (type) {
+      case 128: 
+        return Apple.decode(readValue(buffer)!);
+      case 129: 
+        return Kiwi.decode(readValue(buffer)!);
+      default:
+        return super.readValueOfType(type, buffer);
+    }
+  }
+}
+
+class _ZooEventsCodec extends StandardMessageCodec {
+  const _ZooEventsCodec();
+  @override
+  void writeValue(WriteBuffer buffer, Object? value) {
+    if (value is Kiwi) {
+      buffer.putUint8(128);
+      writeValue(buffer, value.encode());
+    } else if (value is Mango) {
+      buffer.putUint8(129);
+      writeValue(buffer, value.encode());
+    } else if (value is Zebra) {
+      buffer.putUint8(130);
+      writeValue(buffer, value.encode());
+    } else {
+      super.writeValue(buffer, value);
+    }
+  }
+
+  @override
+  Object? readValueOfType(int type, ReadBuffer buffer) {
+    switch (type) {
+      case 128: 
+        return Kiwi.decode(readValue(buffer)!);
+      case 129: 
+        return Mango.decode(readValue(buffer)!);
+      case 130: 
+        return Zebra.decode(readValue(buffer)!);
+      default:
+        return super.readValueOfType(type, buffer);
+    }
+  }
+}
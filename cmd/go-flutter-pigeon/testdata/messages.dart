@@ -0,0 +1,34 @@
+import 'package:pigeon/pigeon.dart';
+
+enum Genre {
+  fiction,
+  science,
+}
+
+/// A book from the library.
+class Book {
+  String? title;
+  int? pages;
+  List<String?>? tags;
+  Genre? genre;
+  Book? sequel;
+  Map<String?, double?>? ratings;
+  Uint8List? cover;
+}
+
+@HostApi()
+abstract class LibraryApi {
+  List<Book?> search(String keyword, int limit);
+  @async
+  void add(Book book);
+  int count();
+}
+
+@FlutterApi()
+abstract class LibraryEvents {
+  void onAdded(Book book);
+  String? greet(String name, Genre genre);
+  // the argument names collide with the identifiers of the generated method
+  @Deprecated('use greet (soon)')
+  void rate(String a, int reply, bool err);
+}
@@ -0,0 +1,55 @@
+// Command go-flutter-recording prints a platform message recording, made with
+// the flutter.RecordMessages option, with the payloads decoded.
+//
+// Usage:
+//
+//	go-flutter-recording [-channel name] recording.jsonl
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin/recording"
+)
+
+func main() {
+	channel := flag.String("channel", "", "only print the messages of this channel")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: go-flutter-recording [-channel name] recording.jsonl")
+		os.Exit(2)
+	}
+
+	err := run(flag.Arg(0), *channel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-flutter-recording: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string, channel string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := recording.NewDecoder()
+	reader := recording.NewReader(f)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if channel != "" && record.Channel != channel {
+			continue
+		}
+		fmt.Println(decoder.Format(record))
+	}
+}
@@ -0,0 +1,84 @@
+package flutter
+
+import (
+	"fmt"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+const dropChannelName = "go-flutter/drop"
+
+// Drop describes files dropped on the window.
+type Drop struct {
+	// Paths of the dropped files.
+	Paths []string
+	// X and Y are the position of the pointer in the window, in physical
+	// pixels like the pointer events sent to Flutter.
+	X, Y float64
+}
+
+// DropFilterFunc is called with every Drop, before it's sent to Flutter. It
+// may modify the Drop, e.g., to remove or resolve some paths. The Drop is
+// discarded when the function returns false.
+type DropFilterFunc func(drop *Drop) bool
+
+// AddDropFilter adds a filter to the drops, called on the main thread. The
+// filters are called in the order they were added, a Drop discarded by a
+// filter isn't given to the next ones.
+func AddDropFilter(filter DropFilterFunc) Option {
+	return func(c *config) {
+		c.dropFilters = append(c.dropFilters, filter)
+	}
+}
+
+// dropPlugin implements flutter.Plugin and sends the files dropped on the
+// window with a "drop" method call on the go-flutter/drop channel. The
+// arguments are the paths and the pointer position:
+// {"paths": ["/tmp/a.png"], "x": 120.0, "y": 48.0}.
+type dropPlugin struct {
+	channel *plugin.MethodChannel
+	filters []DropFilterFunc
+}
+
+// all hardcoded because theres not pluggable renderer system.
+var defaultDropPlugin = &dropPlugin{}
+
+func (p *dropPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	p.channel = plugin.NewMethodChannel(messenger, dropChannelName, plugin.JSONMethodCodec{})
+	return nil
+}
+
+// glfwDropCallback is called with the paths of the files dropped on the
+// window. GLFW doesn't give the drop position, the cursor position is used.
+func (p *dropPlugin) glfwDropCallback(window *glfw.Window, names []string, pixelsPerScreenCoordinate float64) {
+	x, y := window.GetCursorPos()
+	drop := &Drop{
+		Paths: names,
+		X:     x * pixelsPerScreenCoordinate,
+		Y:     y * pixelsPerScreenCoordinate,
+	}
+	for _, filter := range p.filters {
+		if !filter(drop) {
+			return
+		}
+	}
+	if len(drop.Paths) == 0 {
+		return
+	}
+
+	arguments := struct {
+		Paths []string `json:"paths"`
+		X     float64  `json:"x"`
+		Y     float64  `json:"y"`
+	}{
+		Paths: drop.Paths,
+		X:     drop.X,
+		Y:     drop.Y,
+	}
+	err := p.channel.InvokeMethod("drop", arguments)
+	if err != nil {
+		fmt.Printf("go-flutter: failed to send the dropped files: %v\n", err)
+	}
+}
@@ -4,12 +4,13 @@ import "C"
 
 // #include "embedder.h"
 // #include <stdlib.h>
-// FlutterEngineResult runFlutter(void *user_data, FlutterEngine *engine, FlutterProjectArgs * Args);
+// FlutterEngineResult runFlutter(void *user_data, FlutterEngine *engine, FlutterProjectArgs * Args, bool separate_render_runner);
 // FlutterEngineResult
 // createMessageResponseHandle(FlutterEngine engine, void *user_data,
 //                             FlutterPlatformMessageResponseHandle **reply);
 // const int32_t kFlutterSemanticsNodeIdBatchEnd = -1;
 // const int32_t kFlutterSemanticsCustomActionIdBatchEnd = -1;
+// FlutterEngineResult sendKeyEvent(FlutterEngine engine, const FlutterKeyEvent *event, uintptr_t callback_id);
 // FlutterEngineAOTDataSource* createAOTDataSource(FlutterEngineAOTDataSource *data_in, const char * elfSnapshotPath);
 import "C"
 import (
@@ -69,6 +70,11 @@ type FlutterEngine struct {
 	TaskRunnerRunOnCurrentThread func() bool
 	TaskRunnerPostTask           func(trask FlutterTask, targetTimeNanos uint64)
 
+	// render task runner interop, optional. When not set, render tasks are
+	// handled by the platform task runner.
+	RenderTaskRunnerRunOnCurrentThread func() bool
+	RenderTaskRunnerPostTask           func(task FlutterTask, targetTimeNanos uint64)
+
 	// platform message callback function
 	PlatfromMessage func(message *PlatformMessage)
 
@@ -147,7 +153,8 @@ func (flu *FlutterEngine) Run(userData unsafe.Pointer, vmArgs []string) error {
 
 	args.struct_size = C.size_t(unsafe.Sizeof(args))
 
-	res := (Result)(C.runFlutter(userData, &flu.Engine, &args))
+	separateRenderRunner := flu.RenderTaskRunnerRunOnCurrentThread != nil && flu.RenderTaskRunnerPostTask != nil
+	res := (Result)(C.runFlutter(userData, &flu.Engine, &args, C.bool(separateRenderRunner)))
 	if flu.Engine == nil {
 		return ResultInvalidArguments.GoError("engine.Run()")
 	}
@@ -248,6 +255,86 @@ func (flu *FlutterEngine) SendPointerEvent(event PointerEvent) error {
 	return (Result)(res).GoError("engine.SendPointerEvent")
 }
 
+// KeyEventType is the type of a KeyEvent.
+type KeyEventType int32
+
+// Values representing the type of a KeyEvent.
+const (
+	KeyEventTypeUp     KeyEventType = C.kFlutterKeyEventTypeUp
+	KeyEventTypeDown   KeyEventType = C.kFlutterKeyEventTypeDown
+	KeyEventTypeRepeat KeyEventType = C.kFlutterKeyEventTypeRepeat
+)
+
+// KeyEvent corresponds to the C.FlutterKeyEvent struct. An event whose
+// Physical and Logical keys are 0 is an empty event.
+type KeyEvent struct {
+	Type KeyEventType
+	// Physical is the USB HID code of the key, a PhysicalKeyboardKey.
+	Physical uint64
+	// Logical is the LogicalKeyboardKey of the key.
+	Logical uint64
+	// Character produced by the event, ignored for up events.
+	Character string
+	// Synthesized is true for events which don't correspond to a native
+	// event.
+	Synthesized bool
+}
+
+// keyEventCallbacks holds the callbacks of the key events being handled by
+// the framework, the C callback gets their id.
+var keyEventCallbacks = &callbackRegistry{callbacks: make(map[uintptr]func(bool))}
+
+type callbackRegistry struct {
+	lock      sync.Mutex
+	nextID    uintptr
+	callbacks map[uintptr]func(bool)
+}
+
+func (r *callbackRegistry) add(callback func(bool)) uintptr {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.nextID++
+	r.callbacks[r.nextID] = callback
+	return r.nextID
+}
+
+func (r *callbackRegistry) take(id uintptr) func(bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	callback := r.callbacks[id]
+	delete(r.callbacks, id)
+	return callback
+}
+
+// SendKeyEvent sends a KeyEvent to the Flutter engine. callback, which may be
+// nil, is called with whether the framework handled the event.
+func (flu *FlutterEngine) SendKeyEvent(event KeyEvent, callback func(handled bool)) error {
+	cKeyEvent := C.FlutterKeyEvent{
+		// the timestamp is in microseconds
+		timestamp:   C.double(FlutterEngineGetCurrentTime() / 1000),
+		_type:       (C.FlutterKeyEventType)(event.Type),
+		physical:    C.uint64_t(event.Physical),
+		logical:     C.uint64_t(event.Logical),
+		synthesized: C.bool(event.Synthesized),
+	}
+	cKeyEvent.struct_size = C.size_t(unsafe.Sizeof(cKeyEvent))
+	if event.Character != "" && event.Type != KeyEventTypeUp {
+		cCharacter := C.CString(event.Character)
+		defer C.free(unsafe.Pointer(cCharacter))
+		cKeyEvent.character = cCharacter
+	}
+
+	var callbackID uintptr
+	if callback != nil {
+		callbackID = keyEventCallbacks.add(callback)
+	}
+	res := C.sendKeyEvent(flu.Engine, &cKeyEvent, C.uintptr_t(callbackID))
+	if (Result)(res) != ResultSuccess && callback != nil {
+		keyEventCallbacks.take(callbackID)
+	}
+	return (Result)(res).GoError("engine.SendKeyEvent()")
+}
+
 // WindowMetricsEvent represents a window's resolution.
 type WindowMetricsEvent struct {
 	Width      int
@@ -20,12 +20,18 @@ bool proxy_gl_external_texture_frame_callback(void *user_data,
 bool proxy_runs_task_on_current_thread_callback(void *user_data);
 void proxy_post_task_callback(FlutterTask task, uint64_t target_time_nanos,
                               void *user_data);
+bool proxy_render_runs_task_on_current_thread_callback(void *user_data);
+void proxy_render_post_task_callback(FlutterTask task,
+                                     uint64_t target_time_nanos,
+                                     void *user_data);
 
 void proxy_desktop_binary_reply(const uint8_t *data, size_t data_size,
                                 void *user_data);
+void proxy_key_event_callback(bool handled, void *user_data);
 
 // C helper
-FlutterEngineResult runFlutter(void *user_data, FlutterEngine *engine, FlutterProjectArgs *Args) {
+FlutterEngineResult runFlutter(void *user_data, FlutterEngine *engine, FlutterProjectArgs *Args,
+                               bool separate_render_runner) {
   FlutterRendererConfig config = {};
   config.type = kOpenGL;
 
@@ -48,12 +54,26 @@ FlutterEngineResult runFlutter(void *user_data, FlutterEngine *engine, FlutterPr
   platform_task_runner.runs_task_on_current_thread_callback =
       proxy_runs_task_on_current_thread_callback;
   platform_task_runner.post_task_callback = proxy_post_task_callback;
+  platform_task_runner.identifier = 1;
+
+  FlutterTaskRunnerDescription render_task_runner = {};
+  render_task_runner.struct_size = sizeof(FlutterTaskRunnerDescription);
+  render_task_runner.user_data = user_data;
+  render_task_runner.runs_task_on_current_thread_callback =
+      proxy_render_runs_task_on_current_thread_callback;
+  render_task_runner.post_task_callback = proxy_render_post_task_callback;
+  render_task_runner.identifier = 2;
 
   FlutterCustomTaskRunners custom_task_runners = {};
   custom_task_runners.struct_size = sizeof(FlutterCustomTaskRunners);
-  // Render task and platform task are handled by the same TaskRunner
   custom_task_runners.platform_task_runner = &platform_task_runner;
-  custom_task_runners.render_task_runner = &platform_task_runner;
+  if (separate_render_runner) {
+    // Render tasks are handled on a dedicated thread
+    custom_task_runners.render_task_runner = &render_task_runner;
+  } else {
+    // Render task and platform task are handled by the same TaskRunner
+    custom_task_runners.render_task_runner = &platform_task_runner;
+  }
   Args->custom_task_runners = &custom_task_runners;
 
   return FlutterEngineRun(FLUTTER_ENGINE_VERSION, &config, Args, user_data,
@@ -72,3 +92,10 @@ createMessageResponseHandle(FlutterEngine engine, void *user_data,
   return FlutterPlatformMessageCreateResponseHandle(
       engine, proxy_desktop_binary_reply, user_data, reply);
 }
+
+FlutterEngineResult sendKeyEvent(FlutterEngine engine,
+                                 const FlutterKeyEvent *event,
+                                 uintptr_t callback_id) {
+  return FlutterEngineSendKeyEvent(engine, event, proxy_key_event_callback,
+                                   (void *)callback_id);
+}
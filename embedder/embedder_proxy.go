@@ -94,9 +94,31 @@ func proxy_post_task_callback(task C.FlutterTask, targetTimeNanos C.uint64_t, us
 	flutterEngine.TaskRunnerPostTask(task, uint64(targetTimeNanos))
 }
 
+//export proxy_render_runs_task_on_current_thread_callback
+func proxy_render_runs_task_on_current_thread_callback(userData unsafe.Pointer) C.bool {
+	flutterEnginePointer := *(*uintptr)(userData)
+	flutterEngine := (*FlutterEngine)(unsafe.Pointer(flutterEnginePointer))
+	return C.bool(flutterEngine.RenderTaskRunnerRunOnCurrentThread())
+}
+
+//export proxy_render_post_task_callback
+func proxy_render_post_task_callback(task C.FlutterTask, targetTimeNanos C.uint64_t, userData unsafe.Pointer) {
+	flutterEnginePointer := *(*uintptr)(userData)
+	flutterEngine := (*FlutterEngine)(unsafe.Pointer(flutterEnginePointer))
+	flutterEngine.RenderTaskRunnerPostTask(task, uint64(targetTimeNanos))
+}
+
 //export proxy_desktop_binary_reply
 func proxy_desktop_binary_reply(data *C.uint8_t, dataSize C.size_t, userData unsafe.Pointer) {
 	callbackPointer := *(*uintptr)(userData)
 	callback := (*DataCallback)(unsafe.Pointer(callbackPointer))
 	callback.Handle(C.GoBytes(unsafe.Pointer(data), C.int(dataSize)))
 }
+
+//export proxy_key_event_callback
+func proxy_key_event_callback(handled C.bool, userData unsafe.Pointer) {
+	callback := keyEventCallbacks.take(uintptr(userData))
+	if callback != nil {
+		callback(bool(handled))
+	}
+}
@@ -27,6 +27,8 @@ type windowManager struct {
 
 	// caching of ppsc to avoid re-calculating every event
 	pixelsPerScreenCoordinate float64
+	// the latest pixelRatio sent to the engine
+	pixelRatio float64
 }
 
 func newWindowManager(forcedPixelRatio float64) *windowManager {
@@ -210,6 +212,8 @@ func (m *windowManager) glfwRefreshCallback(window *glfw.Window) {
 		}
 	}
 
+	m.pixelRatio = pixelRatio
+
 	event := embedder.WindowMetricsEvent{
 		Width:      widthPx,
 		Height:     heightPx,
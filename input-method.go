@@ -0,0 +1,274 @@
+package flutter
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"runtime"
+	"unicode/utf16"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/keymap"
+	"github.com/go-flutter-desktop/go-flutter/plugin/ime"
+)
+
+// InputMethod sets the input method of the text input, composing text from
+// the key events, e.g., to type CJK text. By default on Linux, the Fcitx
+// input method is used when it's running, see DisableInputMethod.
+//
+// On Linux, the XIM input method of GLFW is disabled while an input method is
+// set. XMODIFIERS is set to "@im=none" while GLFW is initialized, and then
+// restored.
+func InputMethod(backend ime.Backend) Option {
+	return func(c *config) {
+		c.inputMethod = backend
+	}
+}
+
+// DisableInputMethod disables the default input method, the characters are
+// then those reported by GLFW. It has no effect on the input method set with
+// InputMethod.
+func DisableInputMethod() Option {
+	return func(c *config) {
+		c.inputMethodDisabled = true
+	}
+}
+
+// disableXIM keeps GLFW from passing the key events to the input method with
+// XIM, the input method backend already receives them. GLFW reads the XIM
+// settings from XMODIFIERS when it's initialized, restore must be called
+// then, so that the setting doesn't leak into the child processes.
+func disableXIM() (restore func()) {
+	previous, ok := os.LookupEnv("XMODIFIERS")
+	os.Setenv("XMODIFIERS", "@im=none")
+	return func() {
+		if ok {
+			os.Setenv("XMODIFIERS", previous)
+		} else {
+			os.Unsetenv("XMODIFIERS")
+		}
+	}
+}
+
+// newDefaultInputMethod connects to the Fcitx input method on Linux. It
+// returns nil when no input method is available.
+func newDefaultInputMethod() ime.Backend {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	fcitx, err := ime.NewFcitx()
+	if err != nil {
+		if errors.Cause(err) != ime.ErrUnavailable {
+			fmt.Printf("go-flutter: failed to connect to the input method: %v\n", err)
+		}
+		return nil
+	}
+	return fcitx
+}
+
+// inputMethodHandler passes the composition updates of the input method to
+// the text input, on the main thread.
+type inputMethodHandler struct {
+	p *textinputPlugin
+}
+
+var _ ime.Handler = inputMethodHandler{}
+
+func (h inputMethodHandler) Preedit(preedit ime.Preedit) {
+	h.p.mainThread.RunOnMainThreadAsync(func() {
+		h.p.setPreedit(preedit)
+	})
+}
+
+func (h inputMethodHandler) Commit(text string) {
+	h.p.mainThread.RunOnMainThreadAsync(func() {
+		h.p.commitText(text)
+	})
+}
+
+// argsEditableSizeAndTransform is the size of the text input and its
+// transform to the Flutter view, a column-major 4x4 matrix.
+type argsEditableSizeAndTransform struct {
+	Width     float64     `json:"width"`
+	Height    float64     `json:"height"`
+	Transform [16]float64 `json:"transform"`
+}
+
+// argsMarkedTextRect is the rectangle of the text being composed, or of the
+// caret, in the coordinates of the text input.
+type argsMarkedTextRect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// processInputMethodKey passes a key event to the input method while a
+// client is set. It reports whether the input method handled the key, which
+// mustn't be sent to the framework. The releases are always sent, the
+// framework would otherwise see the key as pressed.
+func (p *textinputPlugin) processInputMethodKey(key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) bool {
+	p.inputMethodHandledKey = false
+	if p.inputMethod == nil || p.clientID == 0 {
+		return false
+	}
+
+	name := keyName(key, scancode)
+	handled, err := p.inputMethod.ProcessKey(ime.KeyEvent{
+		Key:      int(key),
+		Scancode: scancode,
+		Mods:     int(mods),
+		Text:     keymap.Character(int(key), name, mods&glfw.ModShift != 0, mods&glfw.ModCapsLock != 0),
+		Release:  action == glfw.Release,
+	})
+	if err != nil {
+		fmt.Printf("go-flutter: %v\n", err)
+		return false
+	}
+	if !handled || action == glfw.Release {
+		return false
+	}
+	// the characters of the key are part of the composition
+	p.inputMethodHandledKey = true
+	return true
+}
+
+// setPreedit displays the text being composed in place of the selection, or
+// of the previous composing text.
+func (p *textinputPlugin) setPreedit(preedit ime.Preedit) {
+	if p.clientID == 0 {
+		return
+	}
+	if preedit.Text == "" && !p.isComposing() {
+		return
+	}
+
+	start, end := p.replaceComposingText(preedit.Text)
+	if start == end {
+		p.ed.ComposingBase, p.ed.ComposingExtent = -1, -1
+		p.ed.SelectionBase, p.ed.SelectionExtent = start, start
+	} else {
+		if preedit.Cursor < 0 || preedit.Cursor > len(preedit.Text) {
+			preedit.Cursor = len(preedit.Text)
+		}
+		cursor := start + len(utf16.Encode([]rune(preedit.Text[:preedit.Cursor])))
+		p.ed.ComposingBase, p.ed.ComposingExtent = start, end
+		p.ed.SelectionBase, p.ed.SelectionExtent = cursor, cursor
+	}
+	p.updateEditingState()
+}
+
+// commitText replaces the text being composed, or the selection, with the
+// text composed by the input method.
+func (p *textinputPlugin) commitText(text string) {
+	if p.clientID == 0 {
+		return
+	}
+	text = p.capitalize(text)
+
+	_, end := p.replaceComposingText(text)
+	p.ed.ComposingBase, p.ed.ComposingExtent = -1, -1
+	p.ed.SelectionBase, p.ed.SelectionExtent = end, end
+	p.updateEditingState()
+}
+
+// isComposing tells whether the editing state has a composing range.
+func (p *textinputPlugin) isComposing() bool {
+	length := len(p.ed.utf16Text)
+	return p.ed.ComposingBase >= 0 && p.ed.ComposingExtent >= 0 &&
+		p.ed.ComposingBase <= length && p.ed.ComposingExtent <= length &&
+		p.ed.ComposingBase != p.ed.ComposingExtent
+}
+
+// replaceComposingText replaces the composing range, or the selection when
+// there is no composing range, with text. It returns the range of text.
+func (p *textinputPlugin) replaceComposingText(text string) (start, end int) {
+	start, end = p.getSelectedText()
+	if p.isComposing() {
+		start, end = p.ed.ComposingBase, p.ed.ComposingExtent
+		if start > end {
+			start, end = end, start
+		}
+	}
+
+	p.replaceText(start, end, text)
+	return start, start + len(utf16.Encode([]rune(text)))
+}
+
+func (p *textinputPlugin) handleSetEditableSizeAndTransform(arguments interface{}) (reply interface{}, err error) {
+	var args argsEditableSizeAndTransform
+	err = json.Unmarshal(arguments.(json.RawMessage), &args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode json arguments for handleSetEditableSizeAndTransform")
+	}
+	p.editableTransform = &args.Transform
+	p.updateCursorRect()
+	return nil, nil
+}
+
+func (p *textinputPlugin) handleSetMarkedTextRect(arguments interface{}) (reply interface{}, err error) {
+	var args argsMarkedTextRect
+	err = json.Unmarshal(arguments.(json.RawMessage), &args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode json arguments for handleSetMarkedTextRect")
+	}
+	p.markedTextRect = args
+	p.updateCursorRect()
+	return nil, nil
+}
+
+// updateCursorRect gives the rectangle of the text being composed, in screen
+// coordinates, to the input method.
+func (p *textinputPlugin) updateCursorRect() {
+	if p.inputMethod == nil || p.window == nil || p.editableTransform == nil {
+		return
+	}
+
+	t := p.editableTransform
+	transform := func(x, y float64) (float64, float64) {
+		w := t[3]*x + t[7]*y + t[15]
+		if w == 0 {
+			w = 1
+		}
+		return (t[0]*x + t[4]*y + t[12]) / w, (t[1]*x + t[5]*y + t[13]) / w
+	}
+	r := p.markedTextRect
+	x0, y0 := transform(r.X, r.Y)
+	x1, y1 := transform(r.X+r.Width, r.Y+r.Height)
+
+	// logical pixels to screen coordinates
+	scale := p.windowManager.pixelRatio / p.windowManager.pixelsPerScreenCoordinate
+	if scale == 0 || math.IsNaN(scale) {
+		scale = 1
+	}
+	winX, winY := p.window.GetPos()
+	rect := image.Rect(
+		winX+int(math.Floor(x0*scale)), winY+int(math.Floor(y0*scale)),
+		winX+int(math.Ceil(x1*scale)), winY+int(math.Ceil(y1*scale)),
+	)
+	err := p.inputMethod.SetCursorRect(rect)
+	if err != nil {
+		fmt.Printf("go-flutter: %v\n", err)
+	}
+}
+
+// glfwFocusCallback tells the input method when the window with the text
+// input gets or loses the focus.
+func (p *textinputPlugin) glfwFocusCallback(window *glfw.Window, focused bool) {
+	if p.inputMethod == nil || p.clientID == 0 {
+		return
+	}
+	var err error
+	if focused {
+		err = p.inputMethod.FocusIn()
+	} else {
+		err = p.inputMethod.FocusOut()
+	}
+	if err != nil {
+		fmt.Printf("go-flutter: %v\n", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Package capitalization implements the TextCapitalization modes of the
+// Flutter text input, with the case mappings of a locale.
+package capitalization
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Mode is a TextCapitalization mode.
+type Mode int
+
+// Capitalization modes.
+const (
+	// None keeps the text as typed.
+	None Mode = iota
+	// Characters capitalizes every character.
+	Characters
+	// Words capitalizes the first letter of every word.
+	Words
+	// Sentences capitalizes the first letter of every sentence.
+	Sentences
+)
+
+// ParseMode returns the Mode of the name of a TextCapitalization value, e.g.
+// "TextCapitalization.words". Unknown names are None.
+func ParseMode(name string) Mode {
+	switch name {
+	case "TextCapitalization.characters":
+		return Characters
+	case "TextCapitalization.words":
+		return Words
+	case "TextCapitalization.sentences":
+		return Sentences
+	}
+	return None
+}
+
+// sentenceTerminators are the punctuation marks ending a sentence.
+const sentenceTerminators = ".!?…。！？"
+
+// Capitalizer capitalizes the typed text with the case mappings of a
+// locale, e.g., the Turkish dotted capital İ, or the Greek capital letters
+// without accent. It isn't safe for concurrent use.
+type Capitalizer struct {
+	upper cases.Caser
+	title cases.Caser
+}
+
+// New creates a Capitalizer for the locale tag.
+func New(tag language.Tag) *Capitalizer {
+	return &Capitalizer{
+		upper: cases.Upper(tag),
+		// the first letter of words and sentences keeps its accents in
+		// Greek, which title casing does.
+		title: cases.Title(tag, cases.NoLower),
+	}
+}
+
+// Apply capitalizes text, inserted after the text before, with mode.
+func (c *Capitalizer) Apply(mode Mode, before, text string) string {
+	switch mode {
+	case Characters:
+		return c.upper.String(text)
+	case Words, Sentences:
+	default:
+		return text
+	}
+
+	var b strings.Builder
+	for _, r := range text {
+		s := string(r)
+		if unicode.IsLetter(r) {
+			var starts bool
+			if mode == Words {
+				starts = startsWord(before, b.String())
+			} else {
+				starts = startsSentence(before, b.String())
+			}
+			if starts {
+				s = c.title.String(s)
+			}
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// startsWord tells whether a letter typed after the concatenated context
+// starts a word.
+func startsWord(context ...string) bool {
+	for i := len(context) - 1; i >= 0; i-- {
+		if context[i] != "" {
+			r, _ := utf8.DecodeLastRuneInString(context[i])
+			return unicode.IsSpace(r)
+		}
+	}
+	return true
+}
+
+// startsSentence tells whether a letter typed after the concatenated context
+// starts a sentence: the context is empty, or ends with a sentence
+// terminator followed by spaces.
+func startsSentence(context ...string) bool {
+	spaced := false
+	for i := len(context) - 1; i >= 0; i-- {
+		s := context[i]
+		for s != "" {
+			r, size := utf8.DecodeLastRuneInString(s)
+			if !unicode.IsSpace(r) {
+				return spaced && strings.ContainsRune(sentenceTerminators, r)
+			}
+			spaced = true
+			s = s[:len(s)-size]
+		}
+	}
+	return true
+}
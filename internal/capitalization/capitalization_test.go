@@ -0,0 +1,74 @@
+package capitalization
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestParseMode(t *testing.T) {
+	for name, mode := range map[string]Mode{
+		"TextCapitalization.none":       None,
+		"TextCapitalization.characters": Characters,
+		"TextCapitalization.words":      Words,
+		"TextCapitalization.sentences":  Sentences,
+		"":                              None,
+	} {
+		if ParseMode(name) != mode {
+			t.Errorf("expected mode %v for %q, got %v", mode, name, ParseMode(name))
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	for _, test := range []struct {
+		locale string
+		mode   Mode
+		before string
+		text   string
+		want   string
+	}{
+		{"en", None, "", "abc", "abc"},
+		{"en", Characters, "", "abc", "ABC"},
+		{"en", Characters, "", "i", "I"},
+		{"en", Words, "", "i", "I"},
+		{"en", Words, "hello", "w", "w"},
+		{"en", Words, "hello ", "w", "W"},
+		{"en", Words, "hello\n", "w", "W"},
+		{"en", Words, "", "new york", "New York"},
+		{"en", Words, "", "1st", "1st"},
+		{"en", Sentences, "", "h", "H"},
+		{"en", Sentences, "Hello", "w", "w"},
+		{"en", Sentences, "Hello ", "w", "w"},
+		{"en", Sentences, "Hello.", "w", "w"},
+		{"en", Sentences, "Hello. ", "w", "W"},
+		{"en", Sentences, "Hello?  ", "w", "W"},
+		{"en", Sentences, "", "one. two", "One. Two"},
+		{"en", Sentences, "e.g", ". a", ". A"},
+
+		// Turkish and Azerbaijani have a dotted capital İ
+		{"tr", Characters, "", "istanbul", "İSTANBUL"},
+		{"tr", Words, "", "izmir", "İzmir"},
+		{"tr", Sentences, "Bu. ", "i", "İ"},
+		{"az", Characters, "", "i", "İ"},
+		{"tr", Characters, "", "ı", "I"},
+
+		// Lithuanian removes the dot above i in capitals
+		{"lt", Characters, "", "i̇", "I"},
+		{"en", Characters, "", "i̇", "İ"},
+
+		// Greek capitals drop the accents, except for the first letter of a
+		// word or sentence
+		{"el", Characters, "", "άλφα", "ΑΛΦΑ"},
+		{"el", Words, "", "άλφα", "Άλφα"},
+		{"el", Sentences, "", "ά", "Ά"},
+		{"en", Characters, "", "ά", "Ά"},
+	} {
+		c := New(language.MustParse(test.locale))
+		got := c.Apply(test.mode, test.before, test.text)
+		if got != test.want {
+			t.Errorf("%s mode %v: expected %q after %q to be %q, got %q",
+				test.locale, test.mode, test.text, test.before, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package clock abstracts the current time, so that time-dependent code such
+// as the event loop can be driven by a virtual clock in tests.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock returns the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the Clock of the system, it returns time.Now.
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Fake is a Clock whose time only changes when it's advanced. It's safe for
+// concurrent use.
+type Fake struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+// NewFake creates a Fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the time of the clock.
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set sets the time of the clock.
+func (f *Fake) Set(now time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.now = now
+}
@@ -0,0 +1,338 @@
+// Package dbus is a minimal D-Bus client, connecting to a message bus over a
+// unix socket to call methods and receive signals.
+package dbus
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Well-known names of the message bus.
+const (
+	busName      = "org.freedesktop.DBus"
+	busPath      = ObjectPath("/org/freedesktop/DBus")
+	busInterface = "org.freedesktop.DBus"
+)
+
+// DefaultTimeout is the default timeout of the method calls.
+const DefaultTimeout = 25 * time.Second
+
+// Error is an error reply to a method call.
+type Error struct {
+	Name string
+	Body []interface{}
+}
+
+func (e *Error) Error() string {
+	if len(e.Body) > 0 {
+		if message, ok := e.Body[0].(string); ok {
+			return fmt.Sprintf("dbus: %s: %s", e.Name, message)
+		}
+	}
+	return "dbus: " + e.Name
+}
+
+// ErrClosed is returned by the calls on a closed connection.
+var ErrClosed = errors.New("dbus: connection closed")
+
+// Call is a method call.
+type Call struct {
+	Destination string
+	Path        ObjectPath
+	Interface   string
+	Member      string
+	Signature   Signature
+	Args        []interface{}
+}
+
+// Conn is a connection to a message bus.
+type Conn struct {
+	rwc io.ReadWriteCloser
+	r   *bufio.Reader
+
+	// lastSerial is accessed atomically
+	lastSerial uint32
+	writeLock  sync.Mutex
+
+	lock          sync.Mutex
+	replies       map[uint32]chan *Message
+	signalHandler func(*Message)
+	err           error
+	done          chan struct{}
+
+	name string
+}
+
+// SessionBusAddress returns the address of the session bus, from the
+// DBUS_SESSION_BUS_ADDRESS environment variable or the default socket in
+// XDG_RUNTIME_DIR.
+func SessionBusAddress() (string, error) {
+	address := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if address != "" {
+		return address, nil
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return "", errors.New("dbus: the address of the session bus is unknown")
+	}
+	return "unix:path=" + filepath.Join(runtimeDir, "bus"), nil
+}
+
+// SessionBus connects to the session bus.
+func SessionBus() (*Conn, error) {
+	address, err := SessionBusAddress()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(address)
+}
+
+// Dial connects to the message bus at address. Only the unix transport is
+// supported, with a path or abstract socket.
+func Dial(address string) (*Conn, error) {
+	err := errors.Errorf("dbus: unsupported address %q", address)
+	for _, entry := range strings.Split(address, ";") {
+		var socket string
+		if !strings.HasPrefix(entry, "unix:") {
+			continue
+		}
+		for _, param := range strings.Split(entry[len("unix:"):], ",") {
+			switch {
+			case strings.HasPrefix(param, "path="):
+				socket = param[len("path="):]
+			case strings.HasPrefix(param, "abstract="):
+				socket = "@" + param[len("abstract="):]
+			}
+		}
+		if socket == "" {
+			continue
+		}
+
+		var netConn net.Conn
+		netConn, err = net.Dial("unix", socket)
+		if err != nil {
+			continue
+		}
+		return NewConn(netConn)
+	}
+	return nil, err
+}
+
+// NewConn authenticates on the message bus connected to rwc, and registers
+// the connection with the bus. rwc is closed on error.
+func NewConn(rwc io.ReadWriteCloser) (*Conn, error) {
+	c := &Conn{
+		rwc:     rwc,
+		r:       bufio.NewReader(rwc),
+		replies: make(map[uint32]chan *Message),
+		done:    make(chan struct{}),
+	}
+	err := c.authenticate()
+	if err != nil {
+		rwc.Close()
+		return nil, err
+	}
+	go c.readLoop()
+
+	reply, err := c.Call(&Call{
+		Destination: busName,
+		Path:        busPath,
+		Interface:   busInterface,
+		Member:      "Hello",
+	}, DefaultTimeout)
+	if err != nil {
+		c.Close()
+		return nil, errors.Wrap(err, "dbus: failed to register on the bus")
+	}
+	if len(reply) == 1 {
+		c.name, _ = reply[0].(string)
+	}
+	return c, nil
+}
+
+// authenticate runs the EXTERNAL authentication mechanism, with the user id
+// of the process.
+func (c *Conn) authenticate() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	_, err := io.WriteString(c.rwc, "\x00AUTH EXTERNAL "+uid+"\r\n")
+	if err != nil {
+		return errors.Wrap(err, "dbus: failed to authenticate")
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return errors.Wrap(err, "dbus: failed to authenticate")
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return errors.Errorf("dbus: authentication rejected: %s", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(c.rwc, "BEGIN\r\n")
+	return errors.Wrap(err, "dbus: failed to authenticate")
+}
+
+// Name returns the unique name of the connection on the bus.
+func (c *Conn) Name() string {
+	return c.name
+}
+
+// SetSignalHandler sets the function called for every signal received,
+// matching the rules added with AddMatch. It's called on the goroutine
+// reading the connection, which must not wait for the reply to a call.
+func (c *Conn) SetSignalHandler(f func(*Message)) {
+	c.lock.Lock()
+	c.signalHandler = f
+	c.lock.Unlock()
+}
+
+// AddMatch asks the bus to send the signals matching rule to the
+// connection.
+func (c *Conn) AddMatch(rule string) error {
+	_, err := c.Call(&Call{
+		Destination: busName,
+		Path:        busPath,
+		Interface:   busInterface,
+		Member:      "AddMatch",
+		Signature:   "s",
+		Args:        []interface{}{rule},
+	}, DefaultTimeout)
+	return err
+}
+
+// Call calls a method and waits for its reply, at most for timeout. An error
+// reply is returned as an *Error.
+func (c *Conn) Call(call *Call, timeout time.Duration) ([]interface{}, error) {
+	m := c.message(call)
+	reply := make(chan *Message, 1)
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return nil, c.err
+	}
+	c.replies[m.Serial] = reply
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		delete(c.replies, m.Serial)
+		c.lock.Unlock()
+	}()
+
+	err := c.write(m)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-reply:
+		if r.Type == TypeError {
+			return nil, &Error{Name: r.ErrorName, Body: r.Body}
+		}
+		return r.Body, nil
+	case <-timer.C:
+		return nil, errors.Errorf("dbus: no reply to %s.%s after %v", call.Interface, call.Member, timeout)
+	case <-c.done:
+		return nil, c.closeErr()
+	}
+}
+
+// CallNoReply calls a method without waiting for a reply, which the bus
+// doesn't send.
+func (c *Conn) CallNoReply(call *Call) error {
+	m := c.message(call)
+	m.Flags |= FlagNoReplyExpected
+	return c.write(m)
+}
+
+func (c *Conn) message(call *Call) *Message {
+	return &Message{
+		Type:        TypeMethodCall,
+		Serial:      atomic.AddUint32(&c.lastSerial, 1),
+		Destination: call.Destination,
+		Path:        call.Path,
+		Interface:   call.Interface,
+		Member:      call.Member,
+		Signature:   call.Signature,
+		Body:        call.Args,
+	}
+}
+
+func (c *Conn) write(m *Message) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	select {
+	case <-c.done:
+		return c.closeErr()
+	default:
+	}
+	return WriteMessage(c.rwc, m)
+}
+
+func (c *Conn) closeErr() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+// readLoop dispatches the received messages until the connection fails.
+func (c *Conn) readLoop() {
+	for {
+		m, err := ReadMessage(c.r)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+
+		switch m.Type {
+		case TypeMethodReturn, TypeError:
+			c.lock.Lock()
+			reply, ok := c.replies[m.ReplySerial]
+			c.lock.Unlock()
+			if ok {
+				reply <- m
+			}
+		case TypeSignal:
+			c.lock.Lock()
+			handler := c.signalHandler
+			c.lock.Unlock()
+			if handler != nil {
+				handler(m)
+			}
+		default:
+			// The connection doesn't export objects, method calls are
+			// ignored.
+		}
+	}
+}
+
+// fail closes the connection once, with err.
+func (c *Conn) fail(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.err != nil {
+		return
+	}
+	if err == io.EOF {
+		err = ErrClosed
+	}
+	c.err = err
+	close(c.done)
+	c.rwc.Close()
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	c.fail(ErrClosed)
+	return nil
+}
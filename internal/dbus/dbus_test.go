@@ -0,0 +1,140 @@
+package dbus_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/dbus"
+	"github.com/go-flutter-desktop/go-flutter/internal/dbus/dbustest"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	m := &dbus.Message{
+		Type:        dbus.TypeMethodCall,
+		Serial:      7,
+		Path:        "/org/example/Object",
+		Interface:   "org.example.Interface",
+		Member:      "Method",
+		Destination: "org.example.Service",
+		Signature:   "ybnqiuxtdsogva(si)a{sv}ay",
+		Body: []interface{}{
+			byte(1), true, int16(-2), uint16(3), int32(-4), uint32(5),
+			int64(-6), uint64(7), 8.5, "text",
+			dbus.ObjectPath("/path"), dbus.Signature("a{sv}"),
+			dbus.Variant{Signature: "as", Value: []interface{}{"a", "b"}},
+			[]interface{}{[]interface{}{"x", int32(1)}, []interface{}{"yz", int32(2)}},
+			[]interface{}{[]interface{}{"key", dbus.Variant{Signature: "u", Value: uint32(9)}}},
+			[]byte{1, 2, 3},
+		},
+	}
+	var buf bytes.Buffer
+	err := dbus.WriteMessage(&buf, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Bytes()[0] != 'l' {
+		t.Fatalf("expected a little-endian message, got %v", buf.Bytes())
+	}
+	decoded, err := dbus.ReadMessage(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, m) {
+		t.Fatalf("expected %#v, got %#v", m, decoded)
+	}
+}
+
+func TestMessageErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := dbus.WriteMessage(&buf, &dbus.Message{Type: dbus.TypeSignal, Signature: "s", Body: []interface{}{int32(1)}})
+	if err == nil {
+		t.Fatal("expected an error for a value of the wrong type")
+	}
+	err = dbus.WriteMessage(&buf, &dbus.Message{Type: dbus.TypeSignal, Signature: "a(s"})
+	if err == nil {
+		t.Fatal("expected an error for an invalid signature")
+	}
+
+	buf.Reset()
+	err = dbus.WriteMessage(&buf, &dbus.Message{Type: dbus.TypeSignal, Signature: "s", Body: []interface{}{"text"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.Bytes()[:buf.Len()-3]
+	_, err = dbus.ReadMessage(bytes.NewReader(truncated))
+	if err == nil {
+		t.Fatal("expected an error for a truncated message")
+	}
+}
+
+func TestConn(t *testing.T) {
+	bus, client := dbustest.New()
+	defer bus.Close()
+	bus.Handle("org.example.Interface", "Add", func(call *dbus.Message) (dbus.Signature, []interface{}, error) {
+		return "i", []interface{}{call.Body[0].(int32) + call.Body[1].(int32)}, nil
+	})
+
+	conn, err := dbus.NewConn(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if conn.Name() != dbustest.UniqueName {
+		t.Fatalf("expected the name %s, got %s", dbustest.UniqueName, conn.Name())
+	}
+
+	reply, err := conn.Call(&dbus.Call{
+		Destination: "org.example.Service",
+		Path:        "/org/example/Object",
+		Interface:   "org.example.Interface",
+		Member:      "Add",
+		Signature:   "ii",
+		Args:        []interface{}{int32(2), int32(3)},
+	}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reply, []interface{}{int32(5)}) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+
+	_, err = conn.Call(&dbus.Call{
+		Destination: "org.example.Service",
+		Path:        "/org/example/Object",
+		Interface:   "org.example.Interface",
+		Member:      "Missing",
+	}, time.Second)
+	if dbusErr, ok := err.(*dbus.Error); !ok || dbusErr.Name != "org.freedesktop.DBus.Error.UnknownMethod" {
+		t.Fatalf("expected an UnknownMethod error, got %v", err)
+	}
+
+	signals := make(chan *dbus.Message, 1)
+	conn.SetSignalHandler(func(m *dbus.Message) { signals <- m })
+	err = conn.AddMatch("type='signal'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches := bus.Matches(); !reflect.DeepEqual(matches, []string{"type='signal'"}) {
+		t.Fatalf("unexpected match rules %v", matches)
+	}
+	err = bus.Emit("/org/example/Object", "org.example.Interface", "Changed", "s", "value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-signals:
+		if m.Member != "Changed" || !reflect.DeepEqual(m.Body, []interface{}{"value"}) {
+			t.Fatalf("unexpected signal %#v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no signal received")
+	}
+
+	conn.Close()
+	_, err = conn.Call(&dbus.Call{Member: "Add"}, time.Second)
+	if err != dbus.ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
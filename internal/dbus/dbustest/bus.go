@@ -0,0 +1,193 @@
+// Package dbustest implements a mock D-Bus message bus, to test the clients
+// of D-Bus services without a session bus.
+package dbustest
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/dbus"
+)
+
+// UniqueName is the unique name given to the client connection.
+const UniqueName = ":1.1"
+
+// HandlerFunc handles a method call of the client. It returns the signature
+// and the values of the reply, or an error sent as an error reply.
+type HandlerFunc func(call *dbus.Message) (dbus.Signature, []interface{}, error)
+
+// Bus is a mock message bus serving a single client, the services of the bus
+// are implemented by the handlers. Bus handles the Hello and AddMatch calls
+// to the bus itself, and sends every signal to the client.
+type Bus struct {
+	conn net.Conn
+
+	writeLock  sync.Mutex
+	lastSerial uint32
+
+	lock     sync.Mutex
+	handlers map[string]HandlerFunc
+	calls    []*dbus.Message
+	matches  []string
+
+	done chan struct{}
+}
+
+// New starts a Bus, and returns the client end of its connection, to be
+// given to dbus.NewConn.
+func New() (*Bus, io.ReadWriteCloser) {
+	client, server := net.Pipe()
+	b := &Bus{
+		conn:     server,
+		handlers: make(map[string]HandlerFunc),
+		done:     make(chan struct{}),
+	}
+	go b.serve()
+	return b, client
+}
+
+// Handle sets the handler of the calls of interface.member.
+func (b *Bus) Handle(iface, member string, f HandlerFunc) {
+	b.lock.Lock()
+	b.handlers[iface+"."+member] = f
+	b.lock.Unlock()
+}
+
+// Calls returns the method calls received, except the calls to the bus.
+func (b *Bus) Calls() []*dbus.Message {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]*dbus.Message(nil), b.calls...)
+}
+
+// Matches returns the match rules added by the client.
+func (b *Bus) Matches() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]string(nil), b.matches...)
+}
+
+// Emit sends a signal to the client.
+func (b *Bus) Emit(path dbus.ObjectPath, iface, member string, signature dbus.Signature, args ...interface{}) error {
+	return b.send(&dbus.Message{
+		Type:        dbus.TypeSignal,
+		Path:        path,
+		Interface:   iface,
+		Member:      member,
+		Destination: UniqueName,
+		Sender:      "org.freedesktop.DBus.Mock",
+		Signature:   signature,
+		Body:        args,
+	})
+}
+
+// Close closes the connection and waits for the bus to stop.
+func (b *Bus) Close() error {
+	err := b.conn.Close()
+	<-b.done
+	return err
+}
+
+func (b *Bus) send(m *dbus.Message) error {
+	b.writeLock.Lock()
+	defer b.writeLock.Unlock()
+	b.lastSerial++
+	m.Serial = b.lastSerial
+	return dbus.WriteMessage(b.conn, m)
+}
+
+func (b *Bus) serve() {
+	defer close(b.done)
+	r := bufio.NewReader(b.conn)
+
+	// authentication, any mechanism is accepted
+	auth, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(auth, "\x00AUTH ") {
+		b.conn.Close()
+		return
+	}
+	_, err = io.WriteString(b.conn, "OK 0123456789abcdef0123456789abcdef\r\n")
+	if err != nil {
+		return
+	}
+	begin, err := r.ReadString('\n')
+	if err != nil || begin != "BEGIN\r\n" {
+		b.conn.Close()
+		return
+	}
+
+	for {
+		m, err := dbus.ReadMessage(r)
+		if err != nil {
+			b.conn.Close()
+			return
+		}
+		if m.Type != dbus.TypeMethodCall {
+			continue
+		}
+		b.handle(m)
+	}
+}
+
+func (b *Bus) handle(m *dbus.Message) {
+	var (
+		signature dbus.Signature
+		body      []interface{}
+		err       error
+	)
+	b.lock.Lock()
+	if m.Destination == "org.freedesktop.DBus" {
+		switch m.Member {
+		case "Hello":
+			signature, body = "s", []interface{}{UniqueName}
+		case "AddMatch":
+			if len(m.Body) == 1 {
+				rule, _ := m.Body[0].(string)
+				b.matches = append(b.matches, rule)
+			}
+		}
+		b.lock.Unlock()
+	} else {
+		b.calls = append(b.calls, m)
+		handler, ok := b.handlers[m.Interface+"."+m.Member]
+		b.lock.Unlock()
+		if ok {
+			signature, body, err = handler(m)
+		} else {
+			err = &dbus.Error{
+				Name: "org.freedesktop.DBus.Error.UnknownMethod",
+				Body: []interface{}{"no handler for " + m.Interface + "." + m.Member},
+			}
+		}
+	}
+
+	if m.Flags&dbus.FlagNoReplyExpected != 0 {
+		return
+	}
+	reply := &dbus.Message{
+		Type:        dbus.TypeMethodReturn,
+		ReplySerial: m.Serial,
+		Destination: UniqueName,
+		Signature:   signature,
+		Body:        body,
+	}
+	if err != nil {
+		reply.Type = dbus.TypeError
+		reply.ErrorName = "org.freedesktop.DBus.Error.Failed"
+		reply.Signature = "s"
+		reply.Body = []interface{}{err.Error()}
+		if dbusErr, ok := err.(*dbus.Error); ok {
+			reply.ErrorName = dbusErr.Name
+			reply.Signature, reply.Body = "", nil
+			if len(dbusErr.Body) == 1 {
+				if message, ok := dbusErr.Body[0].(string); ok {
+					reply.Signature, reply.Body = "s", []interface{}{message}
+				}
+			}
+		}
+	}
+	b.send(reply)
+}
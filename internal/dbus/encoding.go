@@ -0,0 +1,480 @@
+package dbus
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// ObjectPath is a value of the D-Bus OBJECT_PATH type.
+type ObjectPath string
+
+// Signature is a value of the D-Bus SIGNATURE type.
+type Signature string
+
+// Variant is a value of the D-Bus VARIANT type.
+type Variant struct {
+	Signature Signature
+	Value     interface{}
+}
+
+// maxDepth limits the nesting of containers, as the D-Bus specification
+// does.
+const maxDepth = 64
+
+// Values are represented by the Go types:
+//
+//	y byte        n int16    q uint16   i int32    u uint32
+//	x int64       t uint64   d float64  b bool     s string
+//	o ObjectPath  g Signature           v Variant
+//
+// An array of bytes is a []byte, other arrays, structs and dict entries are
+// []interface{}.
+
+// splitSignature splits a signature into its complete types.
+func splitSignature(sig string) ([]string, error) {
+	var types []string
+	for len(sig) > 0 {
+		n, err := typeLength(sig, 0)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, sig[:n])
+		sig = sig[n:]
+	}
+	return types, nil
+}
+
+// typeLength returns the length of the first complete type of sig.
+func typeLength(sig string, depth int) (int, error) {
+	if depth > maxDepth {
+		return 0, errors.New("dbus: signature nested too deeply")
+	}
+	if len(sig) == 0 {
+		return 0, errors.New("dbus: incomplete signature")
+	}
+	switch sig[0] {
+	case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'v':
+		return 1, nil
+	case 'a':
+		n, err := typeLength(sig[1:], depth+1)
+		return n + 1, err
+	case '(', '{':
+		closing := byte(')')
+		if sig[0] == '{' {
+			closing = '}'
+		}
+		i := 1
+		for i < len(sig) && sig[i] != closing {
+			n, err := typeLength(sig[i:], depth+1)
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+		if i >= len(sig) || i == 1 {
+			return 0, errors.Errorf("dbus: invalid signature %q", sig)
+		}
+		return i + 1, nil
+	}
+	return 0, errors.Errorf("dbus: unsupported type %q in signature", sig[0])
+}
+
+// alignment returns the alignment of the values of a type.
+func alignment(typ byte) int {
+	switch typ {
+	case 'n', 'q':
+		return 2
+	case 'b', 'i', 'u', 's', 'o', 'a':
+		return 4
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	}
+	return 1
+}
+
+// encoder appends the marshalled values to buf, which starts at an 8-byte
+// boundary of the message.
+type encoder struct {
+	order binary.ByteOrder
+	buf   []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint16(v uint16) {
+	e.align(2)
+	var b [2]byte
+	e.order.PutUint16(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	var b [4]byte
+	e.order.PutUint32(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.align(8)
+	var b [8]byte
+	e.order.PutUint64(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *encoder) signature(s string) error {
+	if len(s) > 255 {
+		return errors.New("dbus: signature too long")
+	}
+	e.buf = append(e.buf, byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+	return nil
+}
+
+// encode marshals v, of the complete type sig.
+func (e *encoder) encode(sig string, v interface{}) error {
+	var ok bool
+	switch sig[0] {
+	case 'y':
+		var b byte
+		if b, ok = v.(byte); ok {
+			e.buf = append(e.buf, b)
+		}
+	case 'b':
+		var b bool
+		if b, ok = v.(bool); ok {
+			if b {
+				e.uint32(1)
+			} else {
+				e.uint32(0)
+			}
+		}
+	case 'n':
+		var n int16
+		if n, ok = v.(int16); ok {
+			e.uint16(uint16(n))
+		}
+	case 'q':
+		var n uint16
+		if n, ok = v.(uint16); ok {
+			e.uint16(n)
+		}
+	case 'i':
+		var n int32
+		if n, ok = v.(int32); ok {
+			e.uint32(uint32(n))
+		}
+	case 'u':
+		var n uint32
+		if n, ok = v.(uint32); ok {
+			e.uint32(n)
+		}
+	case 'x':
+		var n int64
+		if n, ok = v.(int64); ok {
+			e.uint64(uint64(n))
+		}
+	case 't':
+		var n uint64
+		if n, ok = v.(uint64); ok {
+			e.uint64(n)
+		}
+	case 'd':
+		var f float64
+		if f, ok = v.(float64); ok {
+			e.uint64(math.Float64bits(f))
+		}
+	case 's':
+		var s string
+		if s, ok = v.(string); ok {
+			e.string(s)
+		}
+	case 'o':
+		var p ObjectPath
+		if p, ok = v.(ObjectPath); ok {
+			e.string(string(p))
+		}
+	case 'g':
+		var s Signature
+		if s, ok = v.(Signature); ok {
+			return e.signature(string(s))
+		}
+	case 'v':
+		var variant Variant
+		if variant, ok = v.(Variant); ok {
+			n, err := typeLength(string(variant.Signature), 0)
+			if err != nil {
+				return err
+			}
+			if n != len(variant.Signature) {
+				return errors.Errorf("dbus: variant signature %q isn't a single type", variant.Signature)
+			}
+			err = e.signature(string(variant.Signature))
+			if err != nil {
+				return err
+			}
+			return e.encode(string(variant.Signature), variant.Value)
+		}
+	case 'a':
+		return e.encodeArray(sig[1:], v)
+	case '(', '{':
+		var fields []interface{}
+		if fields, ok = v.([]interface{}); ok {
+			types, err := splitSignature(sig[1 : len(sig)-1])
+			if err != nil {
+				return err
+			}
+			if len(types) != len(fields) {
+				return errors.Errorf("dbus: %d values for the struct %q", len(fields), sig)
+			}
+			e.align(8)
+			for i, field := range fields {
+				err = e.encode(types[i], field)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if !ok {
+		return errors.Errorf("dbus: cannot encode %T as %q", v, sig)
+	}
+	return nil
+}
+
+func (e *encoder) encodeArray(elem string, v interface{}) error {
+	e.uint32(0)
+	lengthOffset := len(e.buf) - 4
+	e.align(alignment(elem[0]))
+	start := len(e.buf)
+
+	switch items := v.(type) {
+	case []byte:
+		if elem != "y" {
+			return errors.Errorf("dbus: cannot encode []byte as \"a%s\"", elem)
+		}
+		e.buf = append(e.buf, items...)
+	case []interface{}:
+		for _, item := range items {
+			err := e.encode(elem, item)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.Errorf("dbus: cannot encode %T as \"a%s\"", v, elem)
+	}
+
+	e.order.PutUint32(e.buf[lengthOffset:], uint32(len(e.buf)-start))
+	return nil
+}
+
+// decoder unmarshals the values of buf, which starts at an 8-byte boundary
+// of the message.
+type decoder struct {
+	order binary.ByteOrder
+	buf   []byte
+	pos   int
+}
+
+var errTruncated = errors.New("dbus: truncated message")
+
+func (d *decoder) align(n int) error {
+	pos := (d.pos + n - 1) / n * n
+	if pos > len(d.buf) {
+		return errTruncated
+	}
+	d.pos = pos
+	return nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.buf) {
+		return nil, errTruncated
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	if err := d.align(2); err != nil {
+		return 0, err
+	}
+	b, err := d.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint16(b), nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	if err := d.align(8); err != nil {
+		return 0, err
+	}
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint64(b), nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(b[:n]), nil
+}
+
+func (d *decoder) signature() (string, error) {
+	n, err := d.read(1)
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n[0]) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(b[:n[0]]), nil
+}
+
+// decode unmarshals a value of the complete type sig.
+func (d *decoder) decode(sig string, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("dbus: value nested too deeply")
+	}
+	switch sig[0] {
+	case 'y':
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case 'b':
+		n, err := d.uint32()
+		return n != 0, err
+	case 'n':
+		n, err := d.uint16()
+		return int16(n), err
+	case 'q':
+		return d.uint16()
+	case 'i':
+		n, err := d.uint32()
+		return int32(n), err
+	case 'u':
+		return d.uint32()
+	case 'x':
+		n, err := d.uint64()
+		return int64(n), err
+	case 't':
+		return d.uint64()
+	case 'd':
+		n, err := d.uint64()
+		return math.Float64frombits(n), err
+	case 's':
+		return d.string()
+	case 'o':
+		s, err := d.string()
+		return ObjectPath(s), err
+	case 'g':
+		s, err := d.signature()
+		return Signature(s), err
+	case 'v':
+		s, err := d.signature()
+		if err != nil {
+			return nil, err
+		}
+		n, err := typeLength(s, 0)
+		if err != nil {
+			return nil, err
+		}
+		if n != len(s) {
+			return nil, errors.Errorf("dbus: variant signature %q isn't a single type", s)
+		}
+		value, err := d.decode(s, depth+1)
+		return Variant{Signature: Signature(s), Value: value}, err
+	case 'a':
+		return d.decodeArray(sig[1:], depth)
+	case '(', '{':
+		types, err := splitSignature(sig[1 : len(sig)-1])
+		if err != nil {
+			return nil, err
+		}
+		if err = d.align(8); err != nil {
+			return nil, err
+		}
+		fields := make([]interface{}, 0, len(types))
+		for _, typ := range types {
+			field, err := d.decode(typ, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+		return fields, nil
+	}
+	return nil, errors.Errorf("dbus: unsupported type %q", sig[0])
+}
+
+func (d *decoder) decodeArray(elem string, depth int) (interface{}, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if err = d.align(alignment(elem[0])); err != nil {
+		return nil, err
+	}
+	if elem == "y" {
+		b, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	}
+
+	end := d.pos + int(n)
+	if end > len(d.buf) {
+		return nil, errTruncated
+	}
+	items := []interface{}{}
+	for d.pos < end {
+		item, err := d.decode(elem, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if d.pos != end {
+		return nil, errors.New("dbus: invalid array length")
+	}
+	return items, nil
+}
@@ -0,0 +1,208 @@
+package dbus
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// MessageType is the type of a D-Bus message.
+type MessageType byte
+
+// Message types.
+const (
+	TypeMethodCall MessageType = iota + 1
+	TypeMethodReturn
+	TypeError
+	TypeSignal
+)
+
+// FlagNoReplyExpected marks the method calls without reply.
+const FlagNoReplyExpected byte = 0x1
+
+// header field codes
+const (
+	fieldPath byte = iota + 1
+	fieldInterface
+	fieldMember
+	fieldErrorName
+	fieldReplySerial
+	fieldDestination
+	fieldSender
+	fieldSignature
+)
+
+// maxMessageSize is the maximum size of a message, from the D-Bus
+// specification.
+const maxMessageSize = 1 << 27
+
+// Message is a D-Bus message. Body holds the values of the types of
+// Signature.
+type Message struct {
+	Type        MessageType
+	Flags       byte
+	Serial      uint32
+	Path        ObjectPath
+	Interface   string
+	Member      string
+	ErrorName   string
+	ReplySerial uint32
+	Destination string
+	Sender      string
+	Signature   Signature
+	Body        []interface{}
+}
+
+// WriteMessage marshals a message, in little-endian byte order, to w.
+func WriteMessage(w io.Writer, m *Message) error {
+	types, err := splitSignature(string(m.Signature))
+	if err != nil {
+		return err
+	}
+	if len(types) != len(m.Body) {
+		return errors.Errorf("dbus: %d values for the signature %q", len(m.Body), m.Signature)
+	}
+	body := encoder{order: binary.LittleEndian}
+	for i, typ := range types {
+		err = body.encode(typ, m.Body[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	var fields []interface{}
+	addField := func(code byte, sig Signature, value interface{}) {
+		fields = append(fields, []interface{}{code, Variant{Signature: sig, Value: value}})
+	}
+	if m.Path != "" {
+		addField(fieldPath, "o", m.Path)
+	}
+	if m.Interface != "" {
+		addField(fieldInterface, "s", m.Interface)
+	}
+	if m.Member != "" {
+		addField(fieldMember, "s", m.Member)
+	}
+	if m.ErrorName != "" {
+		addField(fieldErrorName, "s", m.ErrorName)
+	}
+	if m.ReplySerial != 0 {
+		addField(fieldReplySerial, "u", m.ReplySerial)
+	}
+	if m.Destination != "" {
+		addField(fieldDestination, "s", m.Destination)
+	}
+	if m.Sender != "" {
+		addField(fieldSender, "s", m.Sender)
+	}
+	if m.Signature != "" {
+		addField(fieldSignature, "g", m.Signature)
+	}
+
+	header := encoder{order: binary.LittleEndian}
+	header.buf = append(header.buf, 'l', byte(m.Type), m.Flags, 1)
+	header.uint32(uint32(len(body.buf)))
+	header.uint32(m.Serial)
+	err = header.encode("a(yv)", fields)
+	if err != nil {
+		return err
+	}
+	header.align(8)
+	if len(header.buf)+len(body.buf) > maxMessageSize {
+		return errors.New("dbus: message too large")
+	}
+
+	_, err = w.Write(append(header.buf, body.buf...))
+	return err
+}
+
+// ReadMessage reads a message from r.
+func ReadMessage(r io.Reader) (*Message, error) {
+	fixed := make([]byte, 16)
+	_, err := io.ReadFull(r, fixed)
+	if err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch fixed[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return nil, errors.Errorf("dbus: invalid byte order %q", fixed[0])
+	}
+	bodyLength := order.Uint32(fixed[4:])
+	fieldsLength := order.Uint32(fixed[12:])
+	if bodyLength > maxMessageSize || fieldsLength > maxMessageSize {
+		return nil, errors.New("dbus: message too large")
+	}
+
+	headerLength := (16 + int(fieldsLength) + 7) / 8 * 8
+	header := make([]byte, headerLength)
+	copy(header, fixed)
+	_, err = io.ReadFull(r, header[16:])
+	if err != nil {
+		return nil, err
+	}
+	body := make([]byte, bodyLength)
+	_, err = io.ReadFull(r, body)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Message{
+		Type:   MessageType(fixed[1]),
+		Flags:  fixed[2],
+		Serial: order.Uint32(fixed[8:]),
+	}
+	d := decoder{order: order, buf: header[:16+fieldsLength], pos: 12}
+	fields, err := d.decode("a(yv)", 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields.([]interface{}) {
+		field := field.([]interface{})
+		value := field[1].(Variant).Value
+		var ok bool
+		switch field[0].(byte) {
+		case fieldPath:
+			m.Path, ok = value.(ObjectPath)
+		case fieldInterface:
+			m.Interface, ok = value.(string)
+		case fieldMember:
+			m.Member, ok = value.(string)
+		case fieldErrorName:
+			m.ErrorName, ok = value.(string)
+		case fieldReplySerial:
+			m.ReplySerial, ok = value.(uint32)
+		case fieldDestination:
+			m.Destination, ok = value.(string)
+		case fieldSender:
+			m.Sender, ok = value.(string)
+		case fieldSignature:
+			m.Signature, ok = value.(Signature)
+		default:
+			// unknown fields are ignored
+			ok = true
+		}
+		if !ok {
+			return nil, errors.Errorf("dbus: invalid type of the header field %d", field[0])
+		}
+	}
+
+	types, err := splitSignature(string(m.Signature))
+	if err != nil {
+		return nil, err
+	}
+	d = decoder{order: order, buf: body}
+	for _, typ := range types {
+		value, err := d.decode(typ, 0)
+		if err != nil {
+			return nil, err
+		}
+		m.Body = append(m.Body, value)
+	}
+	return m, nil
+}
@@ -0,0 +1,143 @@
+// Package eventloop schedules the delayed tasks of the Flutter engine and the
+// timers of the plugins. It doesn't depend on GLFW nor on the engine, which
+// are given as functions, so that the scheduling can be tested with a
+// virtual clock.
+package eventloop
+
+import (
+	"container/heap"
+	"math"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/clock"
+	"github.com/go-flutter-desktop/go-flutter/internal/priorityqueue"
+	"github.com/go-flutter-desktop/go-flutter/plugin/eventsource"
+)
+
+// DefaultRefreshRate is the maximum time the loop waits for events.
+//
+// 25 Millisecond is arbitrary value, not too high (adds too much delay to
+// platform messages) and not too low (heavy CPU consumption).
+// This value isn't related to FPS, as rendering events are process in a
+// waiting manner.
+// Platform message are fetched from the engine every time the rendering
+// event loop process rendering event (e.g.: moving the cursor on the
+// window), when no rendering event occur (e.g., window minimized) platform
+// message are fetch every 25ms.
+const DefaultRefreshRate = 25 * time.Millisecond
+
+// Forever is the timeout given to the wait function when the loop must wait
+// until it's woken up.
+const Forever time.Duration = math.MaxInt64
+
+// Loop schedules delayed tasks, run on the thread calling WaitForEvents.
+type Loop struct {
+	// store the task (event) by their priorities
+	priorityqueue *priorityqueue.PriorityQueue
+	clock         clock.Clock
+	// engineTime returns the current time of the engine, in nanoseconds.
+	engineTime func() uint64
+	// called when a task has been received, used to Wakeup the rendering event loop
+	postEmptyEvent func()
+	runTask        func(task interface{})
+
+	// timers and file descriptor sources registered by plugins, may be nil.
+	sources *eventsource.Sources
+
+	// timeout for non-Rendering events that needs to be processed in a polling
+	// manner, 0 when every event wakes the loop up.
+	refreshRate time.Duration
+}
+
+// New creates a Loop. Tasks are run by runTask, postEmptyEvent must wake up
+// the thread waiting in WaitForEvents.
+func New(
+	clk clock.Clock,
+	engineTime func() uint64,
+	postEmptyEvent func(),
+	runTask func(task interface{}),
+	sources *eventsource.Sources,
+) *Loop {
+	pq := priorityqueue.NewPriorityQueue()
+	heap.Init(pq)
+	return &Loop{
+		priorityqueue:  pq,
+		clock:          clk,
+		engineTime:     engineTime,
+		postEmptyEvent: postEmptyEvent,
+		runTask:        runTask,
+		sources:        sources,
+		refreshRate:    DefaultRefreshRate,
+	}
+}
+
+// SetRefreshRate sets the maximum time the loop waits for events,
+// DefaultRefreshRate by default. With a refresh rate of 0 the loop waits
+// until it's woken up or until the next task or timer expires, every producer
+// of events must then wake it up.
+func (l *Loop) SetRefreshRate(refreshRate time.Duration) {
+	l.refreshRate = refreshRate
+}
+
+// PostTask posts a task to be run at targetTimeNanos, in the time base of the
+// engine.
+func (l *Loop) PostTask(task interface{}, targetTimeNanos uint64) {
+	// the subtraction is done on signed durations, the target time may be in
+	// the past.
+	delay := time.Duration(targetTimeNanos) - time.Duration(l.engineTime())
+
+	l.priorityqueue.Lock()
+	item := &priorityqueue.Item{
+		Value:    task,
+		FireTime: l.clock.Now().Add(delay),
+	}
+	heap.Push(l.priorityqueue, item)
+	l.priorityqueue.Unlock()
+
+	l.postEmptyEvent()
+}
+
+// WaitForEvents runs the expired tasks and timers, then calls wait with the
+// time until the next task or timer expires, capped by the refresh rate.
+// The timeout is Forever when there is neither a refresh rate nor a pending
+// task or timer. wait must return early when postEmptyEvent is called. wait
+// isn't called when a task or timer is already expired.
+func (l *Loop) WaitForEvents(wait func(timeout time.Duration)) {
+	now := l.clock.Now()
+
+	// Do NOT service the tasks while holding onto the task queue mutex. We
+	// don't want other threads to block on posting tasks onto this thread
+	// till we are done processing expired tasks.
+	l.priorityqueue.Lock()
+	expiredTasks, next, hasTask := l.priorityqueue.PopExpired(now)
+	l.priorityqueue.Unlock()
+
+	// Fire expired tasks.
+	for _, item := range expiredTasks {
+		l.runTask(item.Value)
+	}
+
+	// Fire expired plugin timers.
+	if l.sources != nil {
+		l.sources.RunTimers(now)
+	}
+
+	// Sleep till the next task or timer needs to be processed. If a new task
+	// comes along, the wait will be resolved early because PostTask posts an
+	// empty event.
+	timeout := l.refreshRate
+	if timeout == 0 {
+		timeout = Forever
+	}
+	if hasTask && next.Sub(now) < timeout {
+		timeout = next.Sub(now)
+	}
+	if l.sources != nil {
+		if deadline, ok := l.sources.NextDeadline(); ok && deadline.Sub(now) < timeout {
+			timeout = deadline.Sub(now)
+		}
+	}
+	if timeout > 0 {
+		wait(timeout)
+	}
+}
@@ -0,0 +1,227 @@
+package eventloop
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/clock"
+	"github.com/go-flutter-desktop/go-flutter/plugin/eventsource"
+)
+
+// testLoop drives a Loop with a fake clock. The engine time is the fake clock
+// time, relative to the start of the test.
+type testLoop struct {
+	*Loop
+	clock   *clock.Fake
+	start   time.Time
+	ran     []interface{}
+	wakeups int
+}
+
+func newTestLoop(withSources bool) *testLoop {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := &testLoop{clock: clock.NewFake(start), start: start}
+	var sources *eventsource.Sources
+	if withSources {
+		sources = eventsource.New(func(f func()) { f() }, l.postEmptyEvent)
+		sources.SetNow(l.clock.Now)
+	}
+	l.Loop = New(l.clock, l.engineTime, l.postEmptyEvent, l.runTask, sources)
+	return l
+}
+
+func (l *testLoop) engineTime() uint64 {
+	return uint64(l.clock.Now().Sub(l.start))
+}
+
+func (l *testLoop) postEmptyEvent() { l.wakeups++ }
+
+func (l *testLoop) runTask(task interface{}) { l.ran = append(l.ran, task) }
+
+// postIn posts a task due in d, in engine time.
+func (l *testLoop) postIn(task interface{}, d time.Duration) {
+	l.PostTask(task, l.engineTime()+uint64(d))
+}
+
+// iterate runs one iteration of the loop and returns the tasks run and the
+// wait timeout, 0 when the loop didn't wait.
+func (l *testLoop) iterate() (ran []interface{}, timeout time.Duration) {
+	l.ran = nil
+	l.WaitForEvents(func(d time.Duration) { timeout = d })
+	return l.ran, timeout
+}
+
+func TestTaskOrdering(t *testing.T) {
+	l := newTestLoop(false)
+	l.postIn("c", 3*time.Millisecond)
+	l.postIn("a", time.Millisecond)
+	l.postIn("b1", 2*time.Millisecond)
+	l.postIn("b2", 2*time.Millisecond)
+	l.postIn("b3", 2*time.Millisecond)
+	if l.wakeups != 5 {
+		t.Fatalf("expected a wakeup per posted task, got %d", l.wakeups)
+	}
+
+	l.clock.Advance(10 * time.Millisecond)
+	ran, _ := l.iterate()
+	want := []interface{}{"a", "b1", "b2", "b3", "c"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Fatalf("expected tasks %v, got %v", want, ran)
+	}
+}
+
+func TestExpiryBatching(t *testing.T) {
+	l := newTestLoop(false)
+	l.postIn("now", 0)
+	l.postIn("past", 0)
+	l.clock.Advance(time.Millisecond)
+	l.postIn("soon", 5*time.Millisecond)
+	l.postIn("later", 10*time.Millisecond)
+
+	ran, timeout := l.iterate()
+	if !reflect.DeepEqual(ran, []interface{}{"now", "past"}) {
+		t.Fatalf("expected only the expired tasks to run, got %v", ran)
+	}
+	if timeout != 5*time.Millisecond {
+		t.Fatalf("expected to wait for the next task, got %v", timeout)
+	}
+
+	// nothing expired yet.
+	l.clock.Advance(4 * time.Millisecond)
+	ran, timeout = l.iterate()
+	if len(ran) != 0 || timeout != time.Millisecond {
+		t.Fatalf("expected no task and a 1ms wait, got %v and %v", ran, timeout)
+	}
+
+	// a task posted in the past runs with the next batch.
+	l.clock.Advance(time.Millisecond)
+	l.PostTask("overdue", 0)
+	ran, _ = l.iterate()
+	if !reflect.DeepEqual(ran, []interface{}{"overdue", "soon"}) {
+		t.Fatalf("expected the overdue and due tasks, got %v", ran)
+	}
+}
+
+func TestWakeupTiming(t *testing.T) {
+	l := newTestLoop(true)
+
+	// without tasks the loop waits for the refresh rate.
+	_, timeout := l.iterate()
+	if timeout != DefaultRefreshRate {
+		t.Fatalf("expected to wait %v when idle, got %v", DefaultRefreshRate, timeout)
+	}
+
+	// a far task doesn't extend the wait past the refresh rate.
+	l.postIn("far", time.Second)
+	_, timeout = l.iterate()
+	if timeout != DefaultRefreshRate {
+		t.Fatalf("expected to wait %v, got %v", DefaultRefreshRate, timeout)
+	}
+
+	// plugin timers shorten the wait, and run on time.
+	ticks := 0
+	l.sources.AddTimer(7*time.Millisecond, true, func() { ticks++ })
+	_, timeout = l.iterate()
+	if timeout != 7*time.Millisecond {
+		t.Fatalf("expected to wait for the timer, got %v", timeout)
+	}
+	l.clock.Advance(timeout)
+	_, timeout = l.iterate()
+	if ticks != 1 || timeout != 7*time.Millisecond {
+		t.Fatalf("expected a tick and a wait for the next one, got %d and %v", ticks, timeout)
+	}
+
+	// after a stall, the expired task and timer run in the same batch, and the
+	// missed ticks are skipped.
+	l.clock.Advance(time.Second)
+	ran, timeout := l.iterate()
+	if !reflect.DeepEqual(ran, []interface{}{"far"}) || ticks != 2 {
+		t.Fatalf("expected the far task and a tick, got %v and %d", ran, ticks)
+	}
+	if timeout != 7*time.Millisecond {
+		t.Fatalf("expected to wait for the next tick, got %v", timeout)
+	}
+}
+
+func TestIdleWithoutRefreshRate(t *testing.T) {
+	l := newTestLoop(true)
+	l.SetRefreshRate(0)
+
+	_, timeout := l.iterate()
+	if timeout != Forever {
+		t.Fatalf("expected to wait forever when idle, got %v", timeout)
+	}
+
+	// pending tasks and timers still bound the wait.
+	l.postIn("task", time.Second)
+	_, timeout = l.iterate()
+	if timeout != time.Second {
+		t.Fatalf("expected to wait for the task, got %v", timeout)
+	}
+	l.sources.AddTimer(time.Minute, false, func() {})
+	l.clock.Advance(time.Second)
+	ran, timeout := l.iterate()
+	if !reflect.DeepEqual(ran, []interface{}{"task"}) || timeout != time.Minute-time.Second {
+		t.Fatalf("expected the task to run and a wait for the timer, got %v and %v", ran, timeout)
+	}
+}
+
+// TestIdleWakeups runs a loop waiting on a channel, as glfw.WaitEvents does,
+// and counts its iterations while the application is idle.
+func TestIdleWakeups(t *testing.T) {
+	wake := make(chan struct{}, 1)
+	postEmptyEvent := func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	ran := make(chan interface{}, 1)
+	l := New(clock.System, func() uint64 { return uint64(time.Now().UnixNano()) },
+		postEmptyEvent, func(task interface{}) { ran <- task }, nil)
+	l.SetRefreshRate(0)
+
+	iterations := make(chan struct{}, 100)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		timer := time.NewTimer(time.Hour)
+		for {
+			l.WaitForEvents(func(timeout time.Duration) {
+				if timeout != Forever {
+					timer.Reset(timeout)
+				}
+				select {
+				case <-wake:
+				case <-timer.C:
+				case <-stop:
+				}
+			})
+			select {
+			case <-stop:
+				return
+			case iterations <- struct{}{}:
+			}
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if n := len(iterations); n != 0 {
+		t.Fatalf("expected no wakeup while idle, got %d", n)
+	}
+
+	l.PostTask("task", uint64(time.Now().UnixNano()))
+	select {
+	case task := <-ran:
+		if task != "task" {
+			t.Fatalf("unexpected task %v", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the posted task didn't wake the loop up")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := len(iterations); n != 1 {
+		t.Fatalf("expected a single wakeup for the posted task, got %d", n)
+	}
+}
@@ -0,0 +1,112 @@
+package imagetexture
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// minGIFDelay is the delay of the frames whose delay is shorter, as browsers
+// do: such GIFs are meant to be played at a "default" speed.
+const minGIFDelay = 100 * time.Millisecond
+
+// GIF plays the frames of an animated GIF, composited as specified by their
+// disposal methods.
+type GIF struct {
+	gif    *gif.GIF
+	bounds image.Rectangle
+
+	canvas *image.RGBA
+	// previous is the canvas to restore after a frame disposed with
+	// gif.DisposalPrevious.
+	previous *image.RGBA
+	index    int
+	loop     int
+}
+
+// NewGIF creates a GIF player.
+func NewGIF(g *gif.GIF) *GIF {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		// the logical screen size is optional, use the bounds of the frames.
+		for _, frame := range g.Image {
+			bounds = bounds.Union(frame.Bounds())
+		}
+	}
+	return &GIF{
+		gif:    g,
+		bounds: bounds,
+		canvas: image.NewRGBA(bounds),
+	}
+}
+
+// NextFrame returns the next frame and its delay. It returns io.EOF once the
+// GIF has been played LoopCount times, or after the frame of a still GIF.
+func (s *GIF) NextFrame() (image.Image, time.Duration, error) {
+	frames := s.gif.Image
+	if len(frames) == 0 {
+		return nil, 0, io.EOF
+	}
+	if s.index == len(frames) {
+		s.loop++
+		// LoopCount is 0 to loop forever, -1 to play once, n to play n+1
+		// times.
+		if len(frames) == 1 || s.gif.LoopCount < 0 ||
+			(s.gif.LoopCount > 0 && s.loop > s.gif.LoopCount) {
+			return nil, 0, io.EOF
+		}
+		s.index = 0
+		s.previous = nil
+		draw.Draw(s.canvas, s.bounds, image.Transparent, image.Point{}, draw.Src)
+	}
+
+	if s.index > 0 {
+		s.dispose(s.index - 1)
+	}
+	frame := frames[s.index]
+	if s.disposal(s.index) == gif.DisposalPrevious {
+		s.previous = cloneRGBA(s.canvas)
+	}
+	draw.Draw(s.canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+	delay := minGIFDelay
+	if s.index < len(s.gif.Delay) && s.gif.Delay[s.index] > 1 {
+		delay = time.Duration(s.gif.Delay[s.index]) * 10 * time.Millisecond
+	}
+	s.index++
+	return cloneRGBA(s.canvas), delay, nil
+}
+
+// dispose applies the disposal method of the frame at index.
+func (s *GIF) dispose(index int) {
+	switch s.disposal(index) {
+	case gif.DisposalBackground:
+		// the background is transparent, as in browsers.
+		r := s.gif.Image[index].Bounds()
+		draw.Draw(s.canvas, r, image.Transparent, image.Point{}, draw.Src)
+	case gif.DisposalPrevious:
+		if s.previous != nil {
+			s.canvas = s.previous
+			s.previous = nil
+		}
+	}
+}
+
+func (s *GIF) disposal(index int) byte {
+	if index < len(s.gif.Disposal) {
+		return s.gif.Disposal[index]
+	}
+	return gif.DisposalNone
+}
+
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	dst := &image.RGBA{
+		Pix:    make([]uint8, len(src.Pix)),
+		Stride: src.Stride,
+		Rect:   src.Rect,
+	}
+	copy(dst.Pix, src.Pix)
+	return dst
+}
@@ -0,0 +1,239 @@
+package imagetexture
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+var (
+	red   = color.RGBA{0xff, 0, 0, 0xff}
+	green = color.RGBA{0, 0xff, 0, 0xff}
+	blue  = color.RGBA{0, 0, 0xff, 0xff}
+	clear = color.RGBA{}
+)
+
+// halves returns a width x height image, red on the left half and blue on the
+// right one.
+func halves(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.SetRGBA(x, y, red)
+			} else {
+				img.SetRGBA(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+func expectPixel(t *testing.T, img *image.RGBA, x, y int, want color.RGBA) {
+	t.Helper()
+	if got := img.RGBAAt(x, y); got != want {
+		t.Errorf("pixel (%d, %d): expected %v, got %v", x, y, want, got)
+	}
+}
+
+func TestScaleNone(t *testing.T) {
+	src := halves(4, 2)
+	dst := Scale(src, 100, 100, None)
+	if dst != src {
+		t.Fatal("expected an RGBA image to be used as is")
+	}
+
+	// other images are converted, and moved to the origin.
+	nrgba := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	dst = Scale(nrgba, 100, 100, None)
+	if dst.Rect != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("expected the image size, got %v", dst.Rect)
+	}
+}
+
+func TestScaleFit(t *testing.T) {
+	// a 4x2 image fit in 8x8 is letterboxed: 8x4, centered vertically.
+	dst := Scale(halves(4, 2), 8, 8, Fit)
+	if dst.Rect != image.Rect(0, 0, 8, 8) {
+		t.Fatalf("expected the requested size, got %v", dst.Rect)
+	}
+	expectPixel(t, dst, 0, 1, clear)
+	expectPixel(t, dst, 0, 2, red)
+	expectPixel(t, dst, 3, 5, red)
+	expectPixel(t, dst, 4, 5, blue)
+	expectPixel(t, dst, 7, 6, clear)
+
+	// a 2x4 image fit in 8x8 is pillarboxed.
+	dst = Scale(halves(2, 4), 8, 8, Fit)
+	expectPixel(t, dst, 1, 0, clear)
+	expectPixel(t, dst, 2, 0, red)
+	expectPixel(t, dst, 5, 7, blue)
+	expectPixel(t, dst, 6, 7, clear)
+}
+
+func TestScaleFill(t *testing.T) {
+	// a 4x2 image filling 2x2 is cropped to its center 2x2 area.
+	dst := Scale(halves(4, 2), 2, 2, Fill)
+	if dst.Rect != image.Rect(0, 0, 2, 2) {
+		t.Fatalf("expected the requested size, got %v", dst.Rect)
+	}
+	expectPixel(t, dst, 0, 0, red)
+	expectPixel(t, dst, 1, 1, blue)
+
+	// a 4x2 image filling 2x4 keeps one of its center columns, stretched.
+	dst = Scale(halves(4, 2), 2, 4, Fill)
+	for y := 0; y < 4; y++ {
+		expectPixel(t, dst, 0, y, red)
+		expectPixel(t, dst, 1, y, red)
+	}
+
+	// an 8x2 image filling 4x2 keeps its center half.
+	dst = Scale(halves(8, 2), 4, 2, Fill)
+	expectPixel(t, dst, 1, 0, red)
+	expectPixel(t, dst, 2, 1, blue)
+}
+
+// frame returns a paletted frame of bounds filled with c.
+func frame(bounds image.Rectangle, c color.Color) *image.Paletted {
+	img := image.NewPaletted(bounds, palette.Plan9)
+	index := uint8(img.Palette.Index(c))
+	for i := range img.Pix {
+		img.Pix[i] = index
+	}
+	return img
+}
+
+func TestGIFDisposal(t *testing.T) {
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			frame(image.Rect(0, 0, 2, 2), red),
+			frame(image.Rect(0, 0, 1, 1), green),
+			frame(image.Rect(1, 1, 2, 2), blue),
+			frame(image.Rect(1, 0, 2, 1), green),
+		},
+		Delay:     []int{0, 5, 20, 10},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalPrevious, gif.DisposalNone},
+		LoopCount: -1,
+		Config:    image.Config{Width: 2, Height: 2},
+	}
+	s := NewGIF(g)
+
+	img, delay, _ := s.NextFrame()
+	if delay != minGIFDelay {
+		t.Fatalf("expected a 0 delay to be played at %v, got %v", minGIFDelay, delay)
+	}
+	first := img.(*image.RGBA)
+	expectPixel(t, first, 0, 0, red)
+
+	img, delay, _ = s.NextFrame()
+	if delay != 50*time.Millisecond {
+		t.Fatalf("expected a 50ms delay, got %v", delay)
+	}
+	expectPixel(t, img.(*image.RGBA), 0, 0, green)
+	expectPixel(t, img.(*image.RGBA), 1, 1, red)
+	// previous frames aren't modified.
+	expectPixel(t, first, 0, 0, red)
+
+	// the green pixel is cleared by DisposalBackground.
+	img, _, _ = s.NextFrame()
+	expectPixel(t, img.(*image.RGBA), 0, 0, clear)
+	expectPixel(t, img.(*image.RGBA), 1, 1, blue)
+
+	// the blue pixel is restored by DisposalPrevious.
+	img, _, _ = s.NextFrame()
+	expectPixel(t, img.(*image.RGBA), 1, 1, red)
+	expectPixel(t, img.(*image.RGBA), 1, 0, green)
+
+	if _, _, err := s.NextFrame(); err != io.EOF {
+		t.Fatalf("expected a GIF played once to end, got %v", err)
+	}
+}
+
+func TestGIFLoops(t *testing.T) {
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			frame(image.Rect(0, 0, 1, 1), red),
+			frame(image.Rect(0, 0, 1, 1), blue),
+		},
+		Delay:     []int{10, 10},
+		LoopCount: 1,
+	}
+	s := NewGIF(g)
+	var colors []color.RGBA
+	for {
+		img, _, err := s.NextFrame()
+		if err == io.EOF {
+			break
+		}
+		colors = append(colors, img.(*image.RGBA).RGBAAt(0, 0))
+		if len(colors) > 10 {
+			t.Fatal("expected the GIF to end")
+		}
+	}
+	want := []color.RGBA{red, blue, red, blue}
+	if fmt.Sprint(colors) != fmt.Sprint(want) {
+		t.Fatalf("expected the GIF to be played twice %v, got %v", want, colors)
+	}
+
+	still := NewGIF(&gif.GIF{Image: []*image.Paletted{frame(image.Rect(0, 0, 1, 1), red)}})
+	if _, _, err := still.NextFrame(); err != nil {
+		t.Fatalf("expected the frame of a still GIF, got %v", err)
+	}
+	if _, _, err := still.NextFrame(); err != io.EOF {
+		t.Fatalf("expected a still GIF to end after its frame, got %v", err)
+	}
+}
+
+func TestMJPEG(t *testing.T) {
+	var stream bytes.Buffer
+	w := multipart.NewWriter(&stream)
+	for _, c := range []color.RGBA{red, blue} {
+		part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"image/jpeg"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:], []uint8{c.R, c.G, c.B, c.A})
+		}
+		if err := jpeg.Encode(part, img, &jpeg.Options{Quality: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	s := NewMJPEG(&stream, w.Boundary(), 40*time.Millisecond)
+	for _, want := range []color.RGBA{red, blue} {
+		img, delay, err := s.NextFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delay != 40*time.Millisecond {
+			t.Fatalf("expected the frame duration, got %v", delay)
+		}
+		r, g, b, _ := img.At(4, 4).RGBA()
+		got := color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xff}
+		if absDiff(got.R, want.R) > 8 || absDiff(got.G, want.G) > 8 || absDiff(got.B, want.B) > 8 {
+			t.Fatalf("expected a %v frame, got %v", want, got)
+		}
+	}
+	if _, _, err := s.NextFrame(); err != io.EOF {
+		t.Fatalf("expected the end of the stream, got %v", err)
+	}
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
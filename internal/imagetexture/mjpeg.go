@@ -0,0 +1,47 @@
+package imagetexture
+
+import (
+	"image"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MJPEG decodes the JPEG frames of a multipart stream, as served with the
+// multipart/x-mixed-replace content type by IP cameras.
+type MJPEG struct {
+	parts         *multipart.Reader
+	frameDuration time.Duration
+}
+
+// NewMJPEG creates a MJPEG decoder reading the parts separated by boundary,
+// the boundary parameter of the Content-Type of the stream. Every frame is
+// displayed for frameDuration, a live stream is paced by its source with a
+// frameDuration of 0.
+func NewMJPEG(r io.Reader, boundary string, frameDuration time.Duration) *MJPEG {
+	return &MJPEG{
+		parts:         multipart.NewReader(r, boundary),
+		frameDuration: frameDuration,
+	}
+}
+
+// NextFrame reads and decodes the next frame, blocking until it's received.
+// It returns io.EOF at the end of the stream.
+func (s *MJPEG) NextFrame() (image.Image, time.Duration, error) {
+	part, err := s.parts.NextPart()
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to read the next MJPEG part")
+	}
+	defer part.Close()
+	img, err := jpeg.Decode(part)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to decode the MJPEG frame")
+	}
+	return img, s.frameDuration, nil
+}
@@ -0,0 +1,97 @@
+// Package imagetexture converts images to the pixel buffers of textures, and
+// decodes animated images and streams into frames. It doesn't depend on the
+// engine nor on OpenGL.
+package imagetexture
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Mode is how an image is scaled to the size requested for its texture.
+type Mode int
+
+const (
+	// Fit scales the image to fit in the requested size, keeping its aspect
+	// ratio. The remaining area is transparent.
+	Fit Mode = iota
+	// Fill scales the image to cover the requested size, keeping its aspect
+	// ratio. The image is cropped around its center.
+	Fill
+	// None keeps the image at its own size, the requested size is ignored.
+	None
+)
+
+// Scale converts src to RGBA pixels of width x height, according to mode.
+// With None, or when width or height is not positive, the result has the size
+// of src. The nearest pixel of src is sampled, Flutter filters the texture
+// when it's drawn.
+func Scale(src image.Image, width, height int, mode Mode) *image.RGBA {
+	rgba := toRGBA(src)
+	srcW, srcH := rgba.Rect.Dx(), rgba.Rect.Dy()
+	if mode == None || width <= 0 || height <= 0 || srcW == 0 || srcH == 0 ||
+		(width == srcW && height == srcH) {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	// The area of dst covered by src, and the area of src drawn.
+	dstRect := dst.Rect
+	srcRect := rgba.Rect
+	switch mode {
+	case Fit:
+		// scale by the smaller of width/srcW and height/srcH.
+		if width*srcH <= height*srcW {
+			h := srcH * width / srcW
+			dstRect = image.Rect(0, (height-h)/2, width, (height-h)/2+h)
+		} else {
+			w := srcW * height / srcH
+			dstRect = image.Rect((width-w)/2, 0, (width-w)/2+w, height)
+		}
+	case Fill:
+		// scale by the larger of width/srcW and height/srcH.
+		if width*srcH >= height*srcW {
+			h := height * srcW / width
+			srcRect = image.Rect(0, (srcH-h)/2, srcW, (srcH-h)/2+h).Add(rgba.Rect.Min)
+		} else {
+			w := width * srcH / height
+			srcRect = image.Rect((srcW-w)/2, 0, (srcW-w)/2+w, srcH).Add(rgba.Rect.Min)
+		}
+	}
+	if dstRect.Empty() || srcRect.Empty() {
+		return dst
+	}
+	scaleNearest(dst, dstRect, rgba, srcRect)
+	return dst
+}
+
+// toRGBA returns src as an *image.RGBA whose Pix starts at its first pixel,
+// converting it if needed.
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, src, b.Min, draw.Src)
+	return rgba
+}
+
+// scaleNearest scales the srcRect area of src to the dstRect area of dst.
+func scaleNearest(dst *image.RGBA, dstRect image.Rectangle, src *image.RGBA, srcRect image.Rectangle) {
+	dw, dh := dstRect.Dx(), dstRect.Dy()
+	sw, sh := srcRect.Dx(), srcRect.Dy()
+	// source column of each destination column, as a byte offset.
+	columns := make([]int, dw)
+	for x := range columns {
+		columns[x] = (srcRect.Min.X + (2*x+1)*sw/(2*dw)) * 4
+	}
+	for y := 0; y < dh; y++ {
+		sy := srcRect.Min.Y + (2*y+1)*sh/(2*dh)
+		srcRow := src.Pix[sy*src.Stride:]
+		dstRow := dst.Pix[(dstRect.Min.Y+y)*dst.Stride+dstRect.Min.X*4:]
+		for x, offset := range columns {
+			copy(dstRow[x*4:x*4+4], srcRow[offset:offset+4])
+		}
+	}
+}
@@ -8,42 +8,29 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Event corresponds to a Flutter (dart) compatible RawKeyEventData keyevent data.
-// Multi-platform keycode translation is handled within this package.
+// Event corresponds to a Flutter (dart) compatible RawKeyEventData keyevent
+// data, sent on the legacy flutter/keyevent channel along with the key data
+// of the embedder API.
 //
-// As input, go-flutter gets GLFW-keyevent who are only compatible with
-// RawKeyEventDataLinux. To fully support keyboard shortcut (like Command+C to
-// copy on darwin), the flutter framework expect the sent keyevent data to be
-// in the form of a RawKeyEventDataMacOs keyevent data.
-// This package maps the GLFW key-codes to the MacOS ones.
-//
-// On the flutter framework side:
-//    RawKeyEventDataMacOs data is received for darwin
-//    RawKeyEventDataLinux data is received for linux and windows
+// GLFW key events are sent as RawKeyEventDataLinux with the glfw toolkit, on
+// every platform. Shortcuts are matched by the framework from the key data,
+// which has the logical keys of the platform.
 type Event struct {
-	// Common
-	Keymap    string `json:"keymap"` // Linux/MacOS switch
-	Character string `json:"character"`
-	KeyCode   int    `json:"keyCode"`
-	Modifiers int    `json:"modifiers"`
-	Type      string `json:"type"`
-
-	// Linux
+	Keymap              string `json:"keymap"`
+	Character           string `json:"character"`
+	KeyCode             int    `json:"keyCode"`
+	Modifiers           int    `json:"modifiers"`
+	Type                string `json:"type"`
 	Toolkit             string `json:"toolkit,omitempty"`
 	ScanCode            int    `json:"scanCode,omitempty"`
 	UnicodeScalarValues uint32 `json:"unicodeScalarValues,omitempty"`
-
-	// MacOS
-	CharactersIgnoringModifiers string `json:"charactersIgnoringModifiers,omitempty"`
-	Characters                  string `json:"characters,omitempty"`
 }
 
 // Normalize takes a GLFW-based key and normalizes it by converting
-// the input to a keyboard.Event struct compatible with Flutter for the current
-// OS.
-//
-//    RawKeyEventDataMacOs data for darwin
-//    RawKeyEventDataLinux data for linux and windows
+// the input to a RawKeyEventDataLinux compatible keyboard.Event struct.
+// The Caps Lock and Num Lock bits of mods, with the values of the GLFW
+// modifiers of RawKeyEventDataLinux, are only set by GLFW with the
+// LockKeyMods input mode.
 func Normalize(key glfw.Key, scancode int, mods glfw.ModifierKey, action glfw.Action) (event Event, err error) {
 	var typeKey string
 	if action == glfw.Release {
@@ -68,13 +55,16 @@ func Normalize(key glfw.Key, scancode int, mods glfw.ModifierKey, action glfw.Ac
 	utf8 := glfw.GetKeyName(key, scancode)
 
 	event = Event{
+		Keymap:              "linux",
+		Toolkit:             "glfw",
 		Type:                typeKey,
 		Character:           utf8,
+		KeyCode:             int(key),
+		ScanCode:            scancode,
+		Modifiers:           int(mods),
 		UnicodeScalarValues: codepointFromGLFWKey([]rune(utf8)),
 	}
 
-	event.platfromNormalize(key, scancode, mods)
-
 	return event, nil
 }
 
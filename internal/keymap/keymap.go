@@ -0,0 +1,221 @@
+// Package keymap maps the GLFW key events to the physical and logical keys of
+// the Flutter key data protocol, PhysicalKeyboardKey and LogicalKeyboardKey.
+//
+// The physical key identifies the key position on the keyboard, it's a USB
+// HID usage code. It's derived from the scancode, whose meaning depends on the
+// platform, or from the GLFW key when the scancode is unknown.
+//
+// The logical key identifies the meaning of the key in the current layout:
+// the lowercase character it produces, or a Flutter value for the other keys.
+package keymap
+
+import (
+	"runtime"
+	"unicode"
+	"unicode/utf8"
+)
+
+// glfwToPhysical maps the GLFW keys to their physical key on a US keyboard,
+// used when the scancode is unknown.
+var glfwToPhysical = map[int]uint64{
+	glfwKeySpace:        PhysicalSpace,
+	glfwKeyApostrophe:   PhysicalQuote,
+	glfwKeyComma:        PhysicalComma,
+	glfwKeyMinus:        PhysicalMinus,
+	glfwKeyPeriod:       PhysicalPeriod,
+	glfwKeySlash:        PhysicalSlash,
+	glfwKey0:            PhysicalDigit0,
+	glfwKeySemicolon:    PhysicalSemicolon,
+	glfwKeyEqual:        PhysicalEqual,
+	glfwKeyLeftBracket:  PhysicalBracketLeft,
+	glfwKeyBackslash:    PhysicalBackslash,
+	glfwKeyRightBracket: PhysicalBracketRight,
+	glfwKeyGraveAccent:  PhysicalBackquote,
+	glfwKeyWorld1:       PhysicalIntlBackslash,
+	glfwKeyWorld2:       PhysicalIntlBackslash,
+	glfwKeyEscape:       PhysicalEscape,
+	glfwKeyEnter:        PhysicalEnter,
+	glfwKeyTab:          PhysicalTab,
+	glfwKeyBackspace:    PhysicalBackspace,
+	glfwKeyInsert:       PhysicalInsert,
+	glfwKeyDelete:       PhysicalDelete,
+	glfwKeyRight:        PhysicalArrowRight,
+	glfwKeyLeft:         PhysicalArrowLeft,
+	glfwKeyDown:         PhysicalArrowDown,
+	glfwKeyUp:           PhysicalArrowUp,
+	glfwKeyPageUp:       PhysicalPageUp,
+	glfwKeyPageDown:     PhysicalPageDown,
+	glfwKeyHome:         PhysicalHome,
+	glfwKeyEnd:          PhysicalEnd,
+	glfwKeyCapsLock:     PhysicalCapsLock,
+	glfwKeyScrollLock:   PhysicalScrollLock,
+	glfwKeyNumLock:      PhysicalNumLock,
+	glfwKeyPrintScreen:  PhysicalPrintScreen,
+	glfwKeyPause:        PhysicalPause,
+	glfwKeyKP0:          PhysicalNumpad0,
+	glfwKeyKPDecimal:    PhysicalNumpadDecimal,
+	glfwKeyKPDivide:     PhysicalNumpadDivide,
+	glfwKeyKPMultiply:   PhysicalNumpadMultiply,
+	glfwKeyKPSubtract:   PhysicalNumpadSubtract,
+	glfwKeyKPAdd:        PhysicalNumpadAdd,
+	glfwKeyKPEnter:      PhysicalNumpadEnter,
+	glfwKeyKPEqual:      PhysicalNumpadEqual,
+	glfwKeyLeftShift:    PhysicalShiftLeft,
+	glfwKeyLeftControl:  PhysicalControlLeft,
+	glfwKeyLeftAlt:      PhysicalAltLeft,
+	glfwKeyLeftSuper:    PhysicalMetaLeft,
+	glfwKeyRightShift:   PhysicalShiftRight,
+	glfwKeyRightControl: PhysicalControlRight,
+	glfwKeyRightAlt:     PhysicalAltRight,
+	glfwKeyRightSuper:   PhysicalMetaRight,
+	glfwKeyMenu:         PhysicalContextMenu,
+}
+
+// glfwToLogical maps the GLFW keys which don't produce a character, and the
+// numpad keys, to their logical key.
+var glfwToLogical = map[int]uint64{
+	glfwKeyEscape:       LogicalEscape,
+	glfwKeyEnter:        LogicalEnter,
+	glfwKeyTab:          LogicalTab,
+	glfwKeyBackspace:    LogicalBackspace,
+	glfwKeyInsert:       LogicalInsert,
+	glfwKeyDelete:       LogicalDelete,
+	glfwKeyRight:        LogicalArrowRight,
+	glfwKeyLeft:         LogicalArrowLeft,
+	glfwKeyDown:         LogicalArrowDown,
+	glfwKeyUp:           LogicalArrowUp,
+	glfwKeyPageUp:       LogicalPageUp,
+	glfwKeyPageDown:     LogicalPageDown,
+	glfwKeyHome:         LogicalHome,
+	glfwKeyEnd:          LogicalEnd,
+	glfwKeyCapsLock:     LogicalCapsLock,
+	glfwKeyScrollLock:   LogicalScrollLock,
+	glfwKeyNumLock:      LogicalNumLock,
+	glfwKeyPrintScreen:  LogicalPrintScreen,
+	glfwKeyPause:        LogicalPause,
+	glfwKeyKPDecimal:    LogicalNumpadDecimal,
+	glfwKeyKPDivide:     LogicalNumpadDivide,
+	glfwKeyKPMultiply:   LogicalNumpadMultiply,
+	glfwKeyKPSubtract:   LogicalNumpadSubtract,
+	glfwKeyKPAdd:        LogicalNumpadAdd,
+	glfwKeyKPEnter:      LogicalNumpadEnter,
+	glfwKeyKPEqual:      LogicalNumpadEqual,
+	glfwKeyLeftShift:    LogicalShiftLeft,
+	glfwKeyLeftControl:  LogicalControlLeft,
+	glfwKeyLeftAlt:      LogicalAltLeft,
+	glfwKeyLeftSuper:    LogicalMetaLeft,
+	glfwKeyRightShift:   LogicalShiftRight,
+	glfwKeyRightControl: LogicalControlRight,
+	glfwKeyRightAlt:     LogicalAltRight,
+	glfwKeyRightSuper:   LogicalMetaRight,
+	glfwKeyMenu:         LogicalContextMenu,
+}
+
+func init() {
+	for i := 0; i < 26; i++ {
+		glfwToPhysical[glfwKeyA+i] = PhysicalKeyA + uint64(i)
+	}
+	// the digit row starts with 1.
+	for i := 1; i <= 9; i++ {
+		glfwToPhysical[glfwKey0+i] = PhysicalDigit1 + uint64(i-1)
+	}
+	for i := 0; i < 12; i++ {
+		glfwToPhysical[glfwKeyF1+i] = PhysicalF1 + uint64(i)
+	}
+	for i := 0; i < 12; i++ {
+		glfwToPhysical[glfwKeyF12+1+i] = PhysicalF13 + uint64(i)
+	}
+	for i := 0; i < 25; i++ {
+		glfwToLogical[glfwKeyF1+i] = LogicalF1 + uint64(i)
+	}
+	// the numpad keys start with 1, except for their logical keys.
+	for i := 1; i <= 9; i++ {
+		glfwToPhysical[glfwKeyKP0+i] = PhysicalNumpad1 + uint64(i-1)
+	}
+	for i := 0; i <= 9; i++ {
+		glfwToLogical[glfwKeyKP0+i] = LogicalNumpad0 + uint64(i)
+	}
+}
+
+// scancodes maps the scancodes of the current platform to physical keys.
+var scancodes = scancodeTable(runtime.GOOS)
+
+// scancodeTable returns the scancode table of the platform.
+func scancodeTable(goos string) map[int]uint64 {
+	switch goos {
+	case "windows":
+		return windowsScancodes
+	case "darwin":
+		return macosScancodes
+	default:
+		return xkbScancodes
+	}
+}
+
+// Physical returns the physical key of a GLFW key event. Keys unknown to
+// Flutter are mapped to the GLFW plane.
+func Physical(key, scancode int) uint64 {
+	return physical(scancodes, key, scancode)
+}
+
+func physical(scancodes map[int]uint64, key, scancode int) uint64 {
+	if physical, ok := scancodes[scancode]; ok {
+		return physical
+	}
+	if physical, ok := glfwToPhysical[key]; ok {
+		return physical
+	}
+	return glfwPlane | uint64(uint32(scancode))
+}
+
+// Logical returns the logical key of a GLFW key event. name is the character
+// produced by the key in the current layout, without modifiers, as returned
+// by glfw.GetKeyName, empty for the keys which don't produce a character.
+func Logical(key int, name string) uint64 {
+	if logical, ok := glfwToLogical[key]; ok {
+		return logical
+	}
+	if r, size := utf8.DecodeRuneInString(name); r != utf8.RuneError && size == len(name) {
+		return unicodePlane | uint64(unicode.ToLower(r))
+	}
+	if key == glfwKeySpace || (key > glfwKeySpace && key <= glfwKeyGraveAccent) {
+		// printable keys of the US layout.
+		return unicodePlane | uint64(unicode.ToLower(rune(key)))
+	}
+	return glfwPlane | uint64(uint32(key))
+}
+
+// usShifted maps the symbols of the US layout to the symbols Shift produces.
+// The key names given by GLFW are unshifted, the shifted symbols of other
+// layouts aren't known.
+var usShifted = map[rune]rune{
+	'1': '!', '2': '@', '3': '#', '4': '$', '5': '%',
+	'6': '^', '7': '&', '8': '*', '9': '(', '0': ')',
+	'-': '_', '=': '+', '[': '{', ']': '}', '\\': '|',
+	';': ':', '\'': '"', ',': '<', '.': '>', '/': '?',
+	'`': '~',
+}
+
+// Character returns the character produced by a key whose name is given, as
+// for Logical, according to the state of the Shift and Caps Lock modifiers.
+// Shifted symbols are those of the US layout. Keys producing no character, or
+// producing a control character, return "".
+func Character(key int, name string, shift, capsLock bool) string {
+	if key == glfwKeySpace {
+		return " "
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	if r == utf8.RuneError || size != len(name) || unicode.IsControl(r) {
+		return ""
+	}
+	if _, ok := glfwToLogical[key]; ok && !(key >= glfwKeyKP0 && key <= glfwKeyKPAdd) {
+		return ""
+	}
+	if unicode.IsLetter(r) && shift != capsLock {
+		r = unicode.ToUpper(r)
+	}
+	if shifted, ok := usShifted[r]; ok && shift && !(key >= glfwKeyKP0 && key <= glfwKeyKPAdd) {
+		r = shifted
+	}
+	return string(r)
+}
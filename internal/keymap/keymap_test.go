@@ -0,0 +1,251 @@
+package keymap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPhysical(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		key      int
+		scancode int
+		want     uint64
+	}{
+		{"xkb letter", "linux", glfwKeyA, 38, PhysicalKeyA},
+		{"xkb letter of an AZERTY layout", "linux", 'Q', 38, PhysicalKeyA},
+		{"xkb digit", "linux", glfwKey0 + 1, 10, PhysicalDigit1},
+		{"xkb right control", "linux", glfwKeyRightControl, 105, PhysicalControlRight},
+		{"xkb numpad enter", "linux", glfwKeyKPEnter, 104, PhysicalNumpadEnter},
+		{"xkb F13", "linux", glfwKeyF12 + 1, 191, PhysicalF13},
+		{"windows letter", "windows", glfwKeyZ, 0x2c, PhysicalKeyZ},
+		{"windows extended arrow", "windows", glfwKeyUp, 0x148, PhysicalArrowUp},
+		{"windows numpad 8", "windows", glfwKeyKP0 + 8, 0x48, PhysicalNumpad1 + 7},
+		{"windows pause and num lock", "windows", glfwKeyPause, 0x45, PhysicalPause},
+		{"windows num lock", "windows", glfwKeyNumLock, 0x145, PhysicalNumLock},
+		{"macos letter", "darwin", glfwKeyA, 0x00, PhysicalKeyA},
+		{"macos command", "darwin", glfwKeyLeftSuper, 0x37, PhysicalMetaLeft},
+		{"macos ISO section", "darwin", glfwKeyWorld1, 0x0a, PhysicalIntlBackslash},
+		{"unknown scancode, known key", "linux", glfwKeyEscape, 1000, PhysicalEscape},
+		{"unknown scancode and key", "linux", -1, 1000, glfwPlane | 1000},
+	}
+	for _, test := range tests {
+		got := physical(scancodeTable(test.goos), test.key, test.scancode)
+		if got != test.want {
+			t.Errorf("%s: expected 0x%x, got 0x%x", test.name, test.want, got)
+		}
+	}
+}
+
+func TestScancodeTablesAreBijective(t *testing.T) {
+	for _, goos := range []string{"linux", "windows", "darwin"} {
+		seen := make(map[uint64]int)
+		for scancode, physical := range scancodeTable(goos) {
+			if other, ok := seen[physical]; ok {
+				t.Errorf("%s: scancodes %d and %d map to 0x%x", goos, scancode, other, physical)
+			}
+			seen[physical] = scancode
+			if physical&^0xff != hidUsagePage {
+				t.Errorf("%s: scancode %d maps to 0x%x, out of the keyboard page", goos, scancode, physical)
+			}
+		}
+	}
+}
+
+func TestLogical(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     int
+		keyName string
+		want    uint64
+	}{
+		{"letter", glfwKeyA, "a", 'a'},
+		{"letter of an AZERTY layout", glfwKeyA, "q", 'q'},
+		{"uppercase name", glfwKeyA, "A", 'a'},
+		{"non ASCII", glfwKeySemicolon, "é", 'é'},
+		{"digit", glfwKey0, "0", '0'},
+		{"no name, US fallback", glfwKeyA, "", 'a'},
+		{"space", glfwKeySpace, "", ' '},
+		{"enter", glfwKeyEnter, "", LogicalEnter},
+		{"escape", glfwKeyEscape, "", LogicalEscape},
+		{"arrow", glfwKeyLeft, "", LogicalArrowLeft},
+		{"F5", glfwKeyF1 + 4, "", LogicalF1 + 4},
+		{"F25", glfwKeyF25, "", LogicalF1 + 24},
+		{"numpad digit", glfwKeyKP0 + 7, "7", LogicalNumpad0 + 7},
+		{"numpad enter", glfwKeyKPEnter, "", LogicalNumpadEnter},
+		{"right shift", glfwKeyRightShift, "", LogicalShiftRight},
+		{"left super", glfwKeyLeftSuper, "", LogicalMetaLeft},
+		{"unknown key", 400, "", glfwPlane | 400},
+	}
+	for _, test := range tests {
+		got := Logical(test.key, test.keyName)
+		if got != test.want {
+			t.Errorf("%s: expected 0x%x, got 0x%x", test.name, test.want, got)
+		}
+	}
+}
+
+func TestCharacter(t *testing.T) {
+	tests := []struct {
+		name            string
+		key             int
+		keyName         string
+		shift, capsLock bool
+		want            string
+	}{
+		{"letter", glfwKeyA, "a", false, false, "a"},
+		{"shifted letter", glfwKeyA, "a", true, false, "A"},
+		{"caps lock letter", glfwKeyA, "a", false, true, "A"},
+		{"shifted caps lock letter", glfwKeyA, "a", true, true, "a"},
+		{"shifted digit", glfwKey0 + 1, "1", true, false, "!"},
+		{"shifted zero", glfwKey0, "0", true, false, ")"},
+		{"caps lock digit", glfwKey0 + 1, "1", false, true, "1"},
+		{"shifted minus", glfwKeyMinus, "-", true, false, "_"},
+		{"shifted slash", glfwKeySlash, "/", true, false, "?"},
+		{"shifted backslash", glfwKeyBackslash, "\\", true, false, "|"},
+		{"shifted apostrophe", glfwKeyApostrophe, "'", true, false, "\""},
+		{"shifted grave accent", glfwKeyGraveAccent, "`", true, false, "~"},
+		{"shifted letter of another layout", glfwKeyA, "\u00e9", true, false, "\u00c9"},
+		{"shifted unknown symbol", glfwKeyBackslash, "<", true, false, "<"},
+		{"shifted numpad digit", glfwKeyKP0 + 1, "1", true, false, "1"},
+		{"space", glfwKeySpace, "", false, false, " "},
+		{"numpad digit", glfwKeyKP0 + 1, "1", false, false, "1"},
+		{"enter", glfwKeyEnter, "", false, false, ""},
+		{"escape named by the layout", glfwKeyEscape, "\x1b", false, false, ""},
+	}
+	for _, test := range tests {
+		got := Character(test.key, test.keyName, test.shift, test.capsLock)
+		if got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, got)
+		}
+	}
+}
+
+func TestStateSequences(t *testing.T) {
+	s := NewState()
+	a := PhysicalKeyA
+
+	events := s.Handle(EventDown, a, 'a', "a", 0)
+	want := []Event{{Type: EventDown, Physical: a, Logical: 'a', Character: "a"}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+
+	// a second down event, e.g., after a lost up event, is a repeat with the
+	// logical key of the first one.
+	events = s.Handle(EventDown, a, 'q', "q", 0)
+	want = []Event{{Type: EventRepeat, Physical: a, Logical: 'a', Character: "q"}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	if !reflect.DeepEqual(s.Pressed(), map[uint64]uint64{a: 'a'}) {
+		t.Fatalf("unexpected pressed keys %v", s.Pressed())
+	}
+
+	events = s.Handle(EventUp, a, 'q', "", 0)
+	want = []Event{{Type: EventUp, Physical: a, Logical: 'a'}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+
+	// an up event of a released key is an empty event.
+	events = s.Handle(EventUp, a, 'a', "", 0)
+	if !reflect.DeepEqual(events, []Event{{}}) {
+		t.Fatalf("expected an empty event, got %v", events)
+	}
+
+	// a repeat of a released key is a down event.
+	events = s.Handle(EventRepeat, a, 'a', "a", 0)
+	if len(events) != 1 || events[0].Type != EventDown {
+		t.Fatalf("expected a down event, got %v", events)
+	}
+}
+
+func TestStateReleasesModifiers(t *testing.T) {
+	s := NewState()
+	s.Handle(EventDown, PhysicalShiftLeft, LogicalShiftLeft, "", 0)
+	s.Handle(EventDown, PhysicalControlRight, LogicalControlRight, "", ModShift)
+
+	// the shift key is still held, the release of control was missed.
+	events := s.Handle(EventDown, PhysicalKeyA, 'a', "A", ModShift)
+	want := []Event{
+		{Type: EventUp, Physical: PhysicalControlRight, Logical: LogicalControlRight, Synthesized: true},
+		{Type: EventDown, Physical: PhysicalKeyA, Logical: 'a', Character: "A"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+
+	// the modifier of the key itself isn't checked: GLFW may report the state
+	// before the event.
+	events = s.Handle(EventUp, PhysicalShiftLeft, LogicalShiftLeft, "", 0)
+	want = []Event{{Type: EventUp, Physical: PhysicalShiftLeft, Logical: LogicalShiftLeft}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+}
+
+func TestStateSyncsModifiers(t *testing.T) {
+	s := NewState()
+
+	// control was pressed before the window got the focus.
+	events := s.Sync(ModControl)
+	want := []Event{{Type: EventDown, Physical: PhysicalControlLeft, Logical: LogicalControlLeft, Synthesized: true}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	if events = s.Sync(ModControl); len(events) != 0 {
+		t.Fatalf("expected no events, got %v", events)
+	}
+
+	events = s.Handle(EventDown, PhysicalKeyA, 'a', "a", 0)
+	want = []Event{
+		{Type: EventUp, Physical: PhysicalControlLeft, Logical: LogicalControlLeft, Synthesized: true},
+		{Type: EventDown, Physical: PhysicalKeyA, Logical: 'a', Character: "a"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+}
+
+func TestStateSyncsLocks(t *testing.T) {
+	s := NewState()
+
+	// caps lock was enabled outside of the window.
+	events := s.Handle(EventDown, PhysicalKeyA, 'a', "A", ModCapsLock)
+	want := []Event{
+		{Type: EventDown, Physical: PhysicalCapsLock, Logical: LogicalCapsLock, Synthesized: true},
+		{Type: EventUp, Physical: PhysicalCapsLock, Logical: LogicalCapsLock, Synthesized: true},
+		{Type: EventDown, Physical: PhysicalKeyA, Logical: 'a', Character: "A"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	s.Handle(EventUp, PhysicalKeyA, 'a', "", ModCapsLock)
+
+	// the lock key toggles the lock mode, whatever its own modifiers.
+	s.Handle(EventDown, PhysicalCapsLock, LogicalCapsLock, "", ModCapsLock)
+	s.Handle(EventUp, PhysicalCapsLock, LogicalCapsLock, "", 0)
+	if events = s.Sync(0); len(events) != 0 {
+		t.Fatalf("expected no events, got %v", events)
+	}
+}
+
+func TestStateReleaseAll(t *testing.T) {
+	s := NewState()
+	s.Handle(EventDown, PhysicalKeyA, 'a', "a", 0)
+	s.Handle(EventDown, PhysicalAltLeft, LogicalAltLeft, "", 0)
+
+	events := s.ReleaseAll()
+	want := []Event{
+		{Type: EventUp, Physical: PhysicalKeyA, Logical: 'a', Synthesized: true},
+		{Type: EventUp, Physical: PhysicalAltLeft, Logical: LogicalAltLeft, Synthesized: true},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	if pressed := s.Pressed(); len(pressed) != 0 {
+		t.Fatalf("expected no pressed keys, got %v", pressed)
+	}
+}
@@ -0,0 +1,183 @@
+package keymap
+
+// GLFW key codes, as defined by glfw3.h. They are duplicated so that the
+// tables can be tested without GLFW.
+const (
+	glfwKeySpace        = 32
+	glfwKeyApostrophe   = 39
+	glfwKeyComma        = 44
+	glfwKeyMinus        = 45
+	glfwKeyPeriod       = 46
+	glfwKeySlash        = 47
+	glfwKey0            = 48
+	glfwKey9            = 57
+	glfwKeySemicolon    = 59
+	glfwKeyEqual        = 61
+	glfwKeyA            = 65
+	glfwKeyZ            = 90
+	glfwKeyLeftBracket  = 91
+	glfwKeyBackslash    = 92
+	glfwKeyRightBracket = 93
+	glfwKeyGraveAccent  = 96
+	glfwKeyWorld1       = 161
+	glfwKeyWorld2       = 162
+	glfwKeyEscape       = 256
+	glfwKeyEnter        = 257
+	glfwKeyTab          = 258
+	glfwKeyBackspace    = 259
+	glfwKeyInsert       = 260
+	glfwKeyDelete       = 261
+	glfwKeyRight        = 262
+	glfwKeyLeft         = 263
+	glfwKeyDown         = 264
+	glfwKeyUp           = 265
+	glfwKeyPageUp       = 266
+	glfwKeyPageDown     = 267
+	glfwKeyHome         = 268
+	glfwKeyEnd          = 269
+	glfwKeyCapsLock     = 280
+	glfwKeyScrollLock   = 281
+	glfwKeyNumLock      = 282
+	glfwKeyPrintScreen  = 283
+	glfwKeyPause        = 284
+	glfwKeyF1           = 290
+	glfwKeyF12          = 301
+	glfwKeyF24          = 313
+	glfwKeyF25          = 314
+	glfwKeyKP0          = 320
+	glfwKeyKP9          = 329
+	glfwKeyKPDecimal    = 330
+	glfwKeyKPDivide     = 331
+	glfwKeyKPMultiply   = 332
+	glfwKeyKPSubtract   = 333
+	glfwKeyKPAdd        = 334
+	glfwKeyKPEnter      = 335
+	glfwKeyKPEqual      = 336
+	glfwKeyLeftShift    = 340
+	glfwKeyLeftControl  = 341
+	glfwKeyLeftAlt      = 342
+	glfwKeyLeftSuper    = 343
+	glfwKeyRightShift   = 344
+	glfwKeyRightControl = 345
+	glfwKeyRightAlt     = 346
+	glfwKeyRightSuper   = 347
+	glfwKeyMenu         = 348
+)
+
+// Planes of the keys, the high 32 bits of their value. See the
+// LogicalKeyboardKey documentation.
+const (
+	unicodePlane     = 0x00000000000
+	unprintablePlane = 0x00100000000
+	flutterPlane     = 0x00200000000
+	// glfwPlane holds the keys without a Flutter value, by GLFW key code or
+	// scancode.
+	glfwPlane = 0x01800000000
+)
+
+// hidUsagePage is the USB HID usage page of the keyboard keys, the physical
+// keys are the usage codes of the page.
+const hidUsagePage = 0x00070000
+
+// USB HID usage IDs of the keyboard page, the PhysicalKeyboardKey values.
+const (
+	PhysicalKeyA           uint64 = hidUsagePage | 0x04
+	PhysicalKeyZ           uint64 = hidUsagePage | 0x1d
+	PhysicalDigit1         uint64 = hidUsagePage | 0x1e
+	PhysicalDigit0         uint64 = hidUsagePage | 0x27
+	PhysicalEnter          uint64 = hidUsagePage | 0x28
+	PhysicalEscape         uint64 = hidUsagePage | 0x29
+	PhysicalBackspace      uint64 = hidUsagePage | 0x2a
+	PhysicalTab            uint64 = hidUsagePage | 0x2b
+	PhysicalSpace          uint64 = hidUsagePage | 0x2c
+	PhysicalMinus          uint64 = hidUsagePage | 0x2d
+	PhysicalEqual          uint64 = hidUsagePage | 0x2e
+	PhysicalBracketLeft    uint64 = hidUsagePage | 0x2f
+	PhysicalBracketRight   uint64 = hidUsagePage | 0x30
+	PhysicalBackslash      uint64 = hidUsagePage | 0x31
+	PhysicalSemicolon      uint64 = hidUsagePage | 0x33
+	PhysicalQuote          uint64 = hidUsagePage | 0x34
+	PhysicalBackquote      uint64 = hidUsagePage | 0x35
+	PhysicalComma          uint64 = hidUsagePage | 0x36
+	PhysicalPeriod         uint64 = hidUsagePage | 0x37
+	PhysicalSlash          uint64 = hidUsagePage | 0x38
+	PhysicalCapsLock       uint64 = hidUsagePage | 0x39
+	PhysicalF1             uint64 = hidUsagePage | 0x3a
+	PhysicalF12            uint64 = hidUsagePage | 0x45
+	PhysicalPrintScreen    uint64 = hidUsagePage | 0x46
+	PhysicalScrollLock     uint64 = hidUsagePage | 0x47
+	PhysicalPause          uint64 = hidUsagePage | 0x48
+	PhysicalInsert         uint64 = hidUsagePage | 0x49
+	PhysicalHome           uint64 = hidUsagePage | 0x4a
+	PhysicalPageUp         uint64 = hidUsagePage | 0x4b
+	PhysicalDelete         uint64 = hidUsagePage | 0x4c
+	PhysicalEnd            uint64 = hidUsagePage | 0x4d
+	PhysicalPageDown       uint64 = hidUsagePage | 0x4e
+	PhysicalArrowRight     uint64 = hidUsagePage | 0x4f
+	PhysicalArrowLeft      uint64 = hidUsagePage | 0x50
+	PhysicalArrowDown      uint64 = hidUsagePage | 0x51
+	PhysicalArrowUp        uint64 = hidUsagePage | 0x52
+	PhysicalNumLock        uint64 = hidUsagePage | 0x53
+	PhysicalNumpadDivide   uint64 = hidUsagePage | 0x54
+	PhysicalNumpadMultiply uint64 = hidUsagePage | 0x55
+	PhysicalNumpadSubtract uint64 = hidUsagePage | 0x56
+	PhysicalNumpadAdd      uint64 = hidUsagePage | 0x57
+	PhysicalNumpadEnter    uint64 = hidUsagePage | 0x58
+	PhysicalNumpad1        uint64 = hidUsagePage | 0x59
+	PhysicalNumpad0        uint64 = hidUsagePage | 0x62
+	PhysicalNumpadDecimal  uint64 = hidUsagePage | 0x63
+	PhysicalIntlBackslash  uint64 = hidUsagePage | 0x64
+	PhysicalContextMenu    uint64 = hidUsagePage | 0x65
+	PhysicalNumpadEqual    uint64 = hidUsagePage | 0x67
+	PhysicalF13            uint64 = hidUsagePage | 0x68
+	PhysicalF24            uint64 = hidUsagePage | 0x73
+	PhysicalControlLeft    uint64 = hidUsagePage | 0xe0
+	PhysicalShiftLeft      uint64 = hidUsagePage | 0xe1
+	PhysicalAltLeft        uint64 = hidUsagePage | 0xe2
+	PhysicalMetaLeft       uint64 = hidUsagePage | 0xe3
+	PhysicalControlRight   uint64 = hidUsagePage | 0xe4
+	PhysicalShiftRight     uint64 = hidUsagePage | 0xe5
+	PhysicalAltRight       uint64 = hidUsagePage | 0xe6
+	PhysicalMetaRight      uint64 = hidUsagePage | 0xe7
+)
+
+// LogicalKeyboardKey values of the keys which don't produce a character.
+const (
+	LogicalBackspace      uint64 = unprintablePlane | 0x008
+	LogicalTab            uint64 = unprintablePlane | 0x009
+	LogicalEnter          uint64 = unprintablePlane | 0x00d
+	LogicalEscape         uint64 = unprintablePlane | 0x01b
+	LogicalDelete         uint64 = unprintablePlane | 0x07f
+	LogicalCapsLock       uint64 = unprintablePlane | 0x104
+	LogicalNumLock        uint64 = unprintablePlane | 0x10a
+	LogicalScrollLock     uint64 = unprintablePlane | 0x10c
+	LogicalArrowDown      uint64 = unprintablePlane | 0x301
+	LogicalArrowLeft      uint64 = unprintablePlane | 0x302
+	LogicalArrowRight     uint64 = unprintablePlane | 0x303
+	LogicalArrowUp        uint64 = unprintablePlane | 0x304
+	LogicalEnd            uint64 = unprintablePlane | 0x305
+	LogicalHome           uint64 = unprintablePlane | 0x306
+	LogicalPageDown       uint64 = unprintablePlane | 0x307
+	LogicalPageUp         uint64 = unprintablePlane | 0x308
+	LogicalInsert         uint64 = unprintablePlane | 0x407
+	LogicalContextMenu    uint64 = unprintablePlane | 0x505
+	LogicalPause          uint64 = unprintablePlane | 0x509
+	LogicalPrintScreen    uint64 = unprintablePlane | 0x608
+	LogicalF1             uint64 = unprintablePlane | 0x801
+	LogicalControlLeft    uint64 = flutterPlane | 0x100
+	LogicalControlRight   uint64 = flutterPlane | 0x101
+	LogicalShiftLeft      uint64 = flutterPlane | 0x102
+	LogicalShiftRight     uint64 = flutterPlane | 0x103
+	LogicalAltLeft        uint64 = flutterPlane | 0x104
+	LogicalAltRight       uint64 = flutterPlane | 0x105
+	LogicalMetaLeft       uint64 = flutterPlane | 0x106
+	LogicalMetaRight      uint64 = flutterPlane | 0x107
+	LogicalNumpadEnter    uint64 = flutterPlane | 0x20d
+	LogicalNumpadMultiply uint64 = flutterPlane | 0x22a
+	LogicalNumpadAdd      uint64 = flutterPlane | 0x22b
+	LogicalNumpadSubtract uint64 = flutterPlane | 0x22d
+	LogicalNumpadDecimal  uint64 = flutterPlane | 0x22e
+	LogicalNumpadDivide   uint64 = flutterPlane | 0x22f
+	LogicalNumpad0        uint64 = flutterPlane | 0x230
+	LogicalNumpadEqual    uint64 = flutterPlane | 0x23d
+)
@@ -0,0 +1,90 @@
+package keymap
+
+import (
+	"fmt"
+	"time"
+)
+
+// Pending holds the key events sent to the framework which wait for a reply
+// telling whether a widget handled them. The fallbacks of the unhandled
+// events, e.g., the text input shortcuts, are run in the order the events
+// were sent: an event replied to before the previous ones waits for them.
+//
+// Pending isn't safe for concurrent use, its events must be replied to on
+// the goroutine which adds them.
+type Pending struct {
+	timeout time.Duration
+	post    func(func())
+	events  []*PendingEvent
+}
+
+// PendingEvent is a key event waiting for its reply.
+type PendingEvent struct {
+	pending  *Pending
+	replied  bool
+	handled  bool
+	timer    *time.Timer
+	fallback func()
+}
+
+// NewPending creates a Pending whose events are considered unhandled when
+// they aren't replied to within timeout. post must run a function on the
+// goroutine using the Pending, it's called by the timeouts.
+func NewPending(timeout time.Duration, post func(func())) *Pending {
+	return &Pending{timeout: timeout, post: post}
+}
+
+// Add adds an event, sent after the pending ones. fallback is run if no
+// widget handled the event, it may be nil.
+func (p *Pending) Add(fallback func()) *PendingEvent {
+	e := &PendingEvent{pending: p, fallback: fallback}
+	p.events = append(p.events, e)
+	e.timer = time.AfterFunc(p.timeout, func() {
+		p.post(func() {
+			if !e.replied {
+				fmt.Printf("go-flutter: no reply to the key event after %v, the key is considered unhandled\n", p.timeout)
+			}
+			e.Reply(false)
+		})
+	})
+	return e
+}
+
+// Run runs f once the fallbacks of the pending events have been run, right
+// away when no event is pending. It keeps the effects of the keys in their
+// order, e.g., a character typed after Enter is inserted after the newline
+// added by the fallback of Enter.
+func (p *Pending) Run(f func()) {
+	if len(p.events) == 0 {
+		f()
+		return
+	}
+	p.events = append(p.events, &PendingEvent{pending: p, replied: true, fallback: f})
+}
+
+// Len returns the number of events whose fallback hasn't been settled.
+func (p *Pending) Len() int {
+	return len(p.events)
+}
+
+// Reply records whether a widget handled the event, and runs the fallbacks of
+// the unhandled events which have been replied to, in the order they were
+// sent. A reply received after the timeout, or a second reply, is ignored.
+func (e *PendingEvent) Reply(handled bool) {
+	if e.replied {
+		return
+	}
+	e.replied = true
+	e.handled = handled
+	e.timer.Stop()
+
+	p := e.pending
+	for len(p.events) > 0 && p.events[0].replied {
+		event := p.events[0]
+		p.events[0] = nil
+		p.events = p.events[1:]
+		if !event.handled && event.fallback != nil {
+			event.fallback()
+		}
+	}
+}
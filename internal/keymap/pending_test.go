@@ -0,0 +1,104 @@
+package keymap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPendingOrder(t *testing.T) {
+	p := NewPending(time.Hour, func(func()) { t.Error("unexpected timeout") })
+
+	var fallbacks []int
+	fallback := func(i int) func() {
+		return func() { fallbacks = append(fallbacks, i) }
+	}
+	first := p.Add(fallback(1))
+	second := p.Add(fallback(2))
+	third := p.Add(nil)
+	fourth := p.Add(fallback(4))
+
+	// replies received out of order wait for the previous events.
+	second.Reply(false)
+	fourth.Reply(false)
+	if len(fallbacks) != 0 {
+		t.Fatalf("expected the fallbacks to wait for the first reply, got %v", fallbacks)
+	}
+	third.Reply(false)
+	first.Reply(true)
+	if !reflect.DeepEqual(fallbacks, []int{2, 4}) {
+		t.Fatalf("expected the fallbacks of the unhandled events in order, got %v", fallbacks)
+	}
+	if p.Len() != 0 {
+		t.Fatalf("expected no pending event, got %d", p.Len())
+	}
+
+	// a second reply is ignored.
+	second.Reply(false)
+	if !reflect.DeepEqual(fallbacks, []int{2, 4}) {
+		t.Fatalf("expected a second reply to be ignored, got %v", fallbacks)
+	}
+}
+
+func TestPendingTimeout(t *testing.T) {
+	posted := make(chan func(), 1)
+	p := NewPending(10*time.Millisecond, func(f func()) { posted <- f })
+
+	var fallbacks []int
+	late := p.Add(func() { fallbacks = append(fallbacks, 1) })
+	next := p.Add(func() { fallbacks = append(fallbacks, 2) })
+	next.Reply(false)
+
+	select {
+	case f := <-posted:
+		f()
+	case <-time.After(time.Second):
+		t.Fatal("expected the timeout to be posted")
+	}
+	if !reflect.DeepEqual(fallbacks, []int{1, 2}) {
+		t.Fatalf("expected the timed out event to be unhandled, got %v", fallbacks)
+	}
+
+	// the reply received after the timeout is ignored.
+	late.Reply(true)
+	if !reflect.DeepEqual(fallbacks, []int{1, 2}) {
+		t.Fatalf("expected the late reply to be ignored, got %v", fallbacks)
+	}
+
+	// replied events don't time out.
+	p.Add(nil).Reply(true)
+	select {
+	case <-posted:
+		t.Fatal("expected the timeout of a replied event to be stopped")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPendingRun(t *testing.T) {
+	p := NewPending(time.Hour, func(func()) { t.Error("unexpected timeout") })
+
+	var text string
+	p.Run(func() { text += "a" })
+	if text != "a" {
+		t.Fatalf("expected the character to be typed right away, got %q", text)
+	}
+
+	// the reply to Enter is received after the next character is typed.
+	enter := p.Add(func() { text += "\n" })
+	p.Run(func() { text += "b" })
+	if text != "a" {
+		t.Fatalf("expected the character to wait for the reply to Enter, got %q", text)
+	}
+	enter.Reply(false)
+	if text != "a\nb" {
+		t.Fatalf("expected the characters in key order, got %q", text)
+	}
+
+	// the character is typed after a handled key too.
+	handled := p.Add(func() { text += "!" })
+	p.Run(func() { text += "c" })
+	handled.Reply(true)
+	if text != "a\nbc" || p.Len() != 0 {
+		t.Fatalf("expected the character after the handled key, got %q", text)
+	}
+}
@@ -0,0 +1,362 @@
+package keymap
+
+// xkbScancodes maps the XKB keycodes, the evdev codes offset by 8, to
+// physical keys. GLFW gives them as scancodes on X11 and Wayland.
+var xkbScancodes = map[int]uint64{
+	9:   hidUsagePage | 0x29, // Escape
+	10:  hidUsagePage | 0x1e, // Digit1
+	11:  hidUsagePage | 0x1f, // Digit2
+	12:  hidUsagePage | 0x20, // Digit3
+	13:  hidUsagePage | 0x21, // Digit4
+	14:  hidUsagePage | 0x22, // Digit5
+	15:  hidUsagePage | 0x23, // Digit6
+	16:  hidUsagePage | 0x24, // Digit7
+	17:  hidUsagePage | 0x25, // Digit8
+	18:  hidUsagePage | 0x26, // Digit9
+	19:  hidUsagePage | 0x27, // Digit0
+	20:  hidUsagePage | 0x2d, // Minus
+	21:  hidUsagePage | 0x2e, // Equal
+	22:  hidUsagePage | 0x2a, // Backspace
+	23:  hidUsagePage | 0x2b, // Tab
+	24:  hidUsagePage | 0x14, // KeyQ
+	25:  hidUsagePage | 0x1a, // KeyW
+	26:  hidUsagePage | 0x08, // KeyE
+	27:  hidUsagePage | 0x15, // KeyR
+	28:  hidUsagePage | 0x17, // KeyT
+	29:  hidUsagePage | 0x1c, // KeyY
+	30:  hidUsagePage | 0x18, // KeyU
+	31:  hidUsagePage | 0x0c, // KeyI
+	32:  hidUsagePage | 0x12, // KeyO
+	33:  hidUsagePage | 0x13, // KeyP
+	34:  hidUsagePage | 0x2f, // BracketLeft
+	35:  hidUsagePage | 0x30, // BracketRight
+	36:  hidUsagePage | 0x28, // Enter
+	37:  hidUsagePage | 0xe0, // ControlLeft
+	38:  hidUsagePage | 0x04, // KeyA
+	39:  hidUsagePage | 0x16, // KeyS
+	40:  hidUsagePage | 0x07, // KeyD
+	41:  hidUsagePage | 0x09, // KeyF
+	42:  hidUsagePage | 0x0a, // KeyG
+	43:  hidUsagePage | 0x0b, // KeyH
+	44:  hidUsagePage | 0x0d, // KeyJ
+	45:  hidUsagePage | 0x0e, // KeyK
+	46:  hidUsagePage | 0x0f, // KeyL
+	47:  hidUsagePage | 0x33, // Semicolon
+	48:  hidUsagePage | 0x34, // Quote
+	49:  hidUsagePage | 0x35, // Backquote
+	50:  hidUsagePage | 0xe1, // ShiftLeft
+	51:  hidUsagePage | 0x31, // Backslash
+	52:  hidUsagePage | 0x1d, // KeyZ
+	53:  hidUsagePage | 0x1b, // KeyX
+	54:  hidUsagePage | 0x06, // KeyC
+	55:  hidUsagePage | 0x19, // KeyV
+	56:  hidUsagePage | 0x05, // KeyB
+	57:  hidUsagePage | 0x11, // KeyN
+	58:  hidUsagePage | 0x10, // KeyM
+	59:  hidUsagePage | 0x36, // Comma
+	60:  hidUsagePage | 0x37, // Period
+	61:  hidUsagePage | 0x38, // Slash
+	62:  hidUsagePage | 0xe5, // ShiftRight
+	63:  hidUsagePage | 0x55, // NumpadMultiply
+	64:  hidUsagePage | 0xe2, // AltLeft
+	65:  hidUsagePage | 0x2c, // Space
+	66:  hidUsagePage | 0x39, // CapsLock
+	67:  hidUsagePage | 0x3a, // F1
+	68:  hidUsagePage | 0x3b, // F2
+	69:  hidUsagePage | 0x3c, // F3
+	70:  hidUsagePage | 0x3d, // F4
+	71:  hidUsagePage | 0x3e, // F5
+	72:  hidUsagePage | 0x3f, // F6
+	73:  hidUsagePage | 0x40, // F7
+	74:  hidUsagePage | 0x41, // F8
+	75:  hidUsagePage | 0x42, // F9
+	76:  hidUsagePage | 0x43, // F10
+	77:  hidUsagePage | 0x53, // NumLock
+	78:  hidUsagePage | 0x47, // ScrollLock
+	79:  hidUsagePage | 0x5f, // Numpad7
+	80:  hidUsagePage | 0x60, // Numpad8
+	81:  hidUsagePage | 0x61, // Numpad9
+	82:  hidUsagePage | 0x56, // NumpadSubtract
+	83:  hidUsagePage | 0x5c, // Numpad4
+	84:  hidUsagePage | 0x5d, // Numpad5
+	85:  hidUsagePage | 0x5e, // Numpad6
+	86:  hidUsagePage | 0x57, // NumpadAdd
+	87:  hidUsagePage | 0x59, // Numpad1
+	88:  hidUsagePage | 0x5a, // Numpad2
+	89:  hidUsagePage | 0x5b, // Numpad3
+	90:  hidUsagePage | 0x62, // Numpad0
+	91:  hidUsagePage | 0x63, // NumpadDecimal
+	94:  hidUsagePage | 0x64, // IntlBackslash
+	95:  hidUsagePage | 0x44, // F11
+	96:  hidUsagePage | 0x45, // F12
+	104: hidUsagePage | 0x58, // NumpadEnter
+	105: hidUsagePage | 0xe4, // ControlRight
+	106: hidUsagePage | 0x54, // NumpadDivide
+	107: hidUsagePage | 0x46, // PrintScreen
+	108: hidUsagePage | 0xe6, // AltRight
+	110: hidUsagePage | 0x4a, // Home
+	111: hidUsagePage | 0x52, // ArrowUp
+	112: hidUsagePage | 0x4b, // PageUp
+	113: hidUsagePage | 0x50, // ArrowLeft
+	114: hidUsagePage | 0x4f, // ArrowRight
+	115: hidUsagePage | 0x4d, // End
+	116: hidUsagePage | 0x51, // ArrowDown
+	117: hidUsagePage | 0x4e, // PageDown
+	118: hidUsagePage | 0x49, // Insert
+	119: hidUsagePage | 0x4c, // Delete
+	125: hidUsagePage | 0x67, // NumpadEqual
+	127: hidUsagePage | 0x48, // Pause
+	133: hidUsagePage | 0xe3, // MetaLeft
+	134: hidUsagePage | 0xe7, // MetaRight
+	135: hidUsagePage | 0x65, // ContextMenu
+	191: hidUsagePage | 0x68, // F13
+	192: hidUsagePage | 0x69, // F14
+	193: hidUsagePage | 0x6a, // F15
+	194: hidUsagePage | 0x6b, // F16
+	195: hidUsagePage | 0x6c, // F17
+	196: hidUsagePage | 0x6d, // F18
+	197: hidUsagePage | 0x6e, // F19
+	198: hidUsagePage | 0x6f, // F20
+	199: hidUsagePage | 0x70, // F21
+	200: hidUsagePage | 0x71, // F22
+	201: hidUsagePage | 0x72, // F23
+	202: hidUsagePage | 0x73, // F24
+}
+
+// windowsScancodes maps the Windows scancodes to physical keys. GLFW sets the
+// bit 0x100 for the extended scancodes, prefixed with 0xe0.
+var windowsScancodes = map[int]uint64{
+	0x001: hidUsagePage | 0x29, // Escape
+	0x002: hidUsagePage | 0x1e, // Digit1
+	0x003: hidUsagePage | 0x1f, // Digit2
+	0x004: hidUsagePage | 0x20, // Digit3
+	0x005: hidUsagePage | 0x21, // Digit4
+	0x006: hidUsagePage | 0x22, // Digit5
+	0x007: hidUsagePage | 0x23, // Digit6
+	0x008: hidUsagePage | 0x24, // Digit7
+	0x009: hidUsagePage | 0x25, // Digit8
+	0x00a: hidUsagePage | 0x26, // Digit9
+	0x00b: hidUsagePage | 0x27, // Digit0
+	0x00c: hidUsagePage | 0x2d, // Minus
+	0x00d: hidUsagePage | 0x2e, // Equal
+	0x00e: hidUsagePage | 0x2a, // Backspace
+	0x00f: hidUsagePage | 0x2b, // Tab
+	0x010: hidUsagePage | 0x14, // KeyQ
+	0x011: hidUsagePage | 0x1a, // KeyW
+	0x012: hidUsagePage | 0x08, // KeyE
+	0x013: hidUsagePage | 0x15, // KeyR
+	0x014: hidUsagePage | 0x17, // KeyT
+	0x015: hidUsagePage | 0x1c, // KeyY
+	0x016: hidUsagePage | 0x18, // KeyU
+	0x017: hidUsagePage | 0x0c, // KeyI
+	0x018: hidUsagePage | 0x12, // KeyO
+	0x019: hidUsagePage | 0x13, // KeyP
+	0x01a: hidUsagePage | 0x2f, // BracketLeft
+	0x01b: hidUsagePage | 0x30, // BracketRight
+	0x01c: hidUsagePage | 0x28, // Enter
+	0x01d: hidUsagePage | 0xe0, // ControlLeft
+	0x01e: hidUsagePage | 0x04, // KeyA
+	0x01f: hidUsagePage | 0x16, // KeyS
+	0x020: hidUsagePage | 0x07, // KeyD
+	0x021: hidUsagePage | 0x09, // KeyF
+	0x022: hidUsagePage | 0x0a, // KeyG
+	0x023: hidUsagePage | 0x0b, // KeyH
+	0x024: hidUsagePage | 0x0d, // KeyJ
+	0x025: hidUsagePage | 0x0e, // KeyK
+	0x026: hidUsagePage | 0x0f, // KeyL
+	0x027: hidUsagePage | 0x33, // Semicolon
+	0x028: hidUsagePage | 0x34, // Quote
+	0x029: hidUsagePage | 0x35, // Backquote
+	0x02a: hidUsagePage | 0xe1, // ShiftLeft
+	0x02b: hidUsagePage | 0x31, // Backslash
+	0x02c: hidUsagePage | 0x1d, // KeyZ
+	0x02d: hidUsagePage | 0x1b, // KeyX
+	0x02e: hidUsagePage | 0x06, // KeyC
+	0x02f: hidUsagePage | 0x19, // KeyV
+	0x030: hidUsagePage | 0x05, // KeyB
+	0x031: hidUsagePage | 0x11, // KeyN
+	0x032: hidUsagePage | 0x10, // KeyM
+	0x033: hidUsagePage | 0x36, // Comma
+	0x034: hidUsagePage | 0x37, // Period
+	0x035: hidUsagePage | 0x38, // Slash
+	0x036: hidUsagePage | 0xe5, // ShiftRight
+	0x037: hidUsagePage | 0x55, // NumpadMultiply
+	0x038: hidUsagePage | 0xe2, // AltLeft
+	0x039: hidUsagePage | 0x2c, // Space
+	0x03a: hidUsagePage | 0x39, // CapsLock
+	0x03b: hidUsagePage | 0x3a, // F1
+	0x03c: hidUsagePage | 0x3b, // F2
+	0x03d: hidUsagePage | 0x3c, // F3
+	0x03e: hidUsagePage | 0x3d, // F4
+	0x03f: hidUsagePage | 0x3e, // F5
+	0x040: hidUsagePage | 0x3f, // F6
+	0x041: hidUsagePage | 0x40, // F7
+	0x042: hidUsagePage | 0x41, // F8
+	0x043: hidUsagePage | 0x42, // F9
+	0x044: hidUsagePage | 0x43, // F10
+	0x045: hidUsagePage | 0x48, // Pause
+	0x046: hidUsagePage | 0x47, // ScrollLock
+	0x047: hidUsagePage | 0x5f, // Numpad7
+	0x048: hidUsagePage | 0x60, // Numpad8
+	0x049: hidUsagePage | 0x61, // Numpad9
+	0x04a: hidUsagePage | 0x56, // NumpadSubtract
+	0x04b: hidUsagePage | 0x5c, // Numpad4
+	0x04c: hidUsagePage | 0x5d, // Numpad5
+	0x04d: hidUsagePage | 0x5e, // Numpad6
+	0x04e: hidUsagePage | 0x57, // NumpadAdd
+	0x04f: hidUsagePage | 0x59, // Numpad1
+	0x050: hidUsagePage | 0x5a, // Numpad2
+	0x051: hidUsagePage | 0x5b, // Numpad3
+	0x052: hidUsagePage | 0x62, // Numpad0
+	0x053: hidUsagePage | 0x63, // NumpadDecimal
+	0x056: hidUsagePage | 0x64, // IntlBackslash
+	0x057: hidUsagePage | 0x44, // F11
+	0x058: hidUsagePage | 0x45, // F12
+	0x059: hidUsagePage | 0x67, // NumpadEqual
+	0x064: hidUsagePage | 0x68, // F13
+	0x065: hidUsagePage | 0x69, // F14
+	0x066: hidUsagePage | 0x6a, // F15
+	0x067: hidUsagePage | 0x6b, // F16
+	0x068: hidUsagePage | 0x6c, // F17
+	0x069: hidUsagePage | 0x6d, // F18
+	0x06a: hidUsagePage | 0x6e, // F19
+	0x06b: hidUsagePage | 0x6f, // F20
+	0x06c: hidUsagePage | 0x70, // F21
+	0x06d: hidUsagePage | 0x71, // F22
+	0x06e: hidUsagePage | 0x72, // F23
+	0x076: hidUsagePage | 0x73, // F24
+	0x11c: hidUsagePage | 0x58, // NumpadEnter
+	0x11d: hidUsagePage | 0xe4, // ControlRight
+	0x135: hidUsagePage | 0x54, // NumpadDivide
+	0x137: hidUsagePage | 0x46, // PrintScreen
+	0x138: hidUsagePage | 0xe6, // AltRight
+	0x145: hidUsagePage | 0x53, // NumLock
+	0x147: hidUsagePage | 0x4a, // Home
+	0x148: hidUsagePage | 0x52, // ArrowUp
+	0x149: hidUsagePage | 0x4b, // PageUp
+	0x14b: hidUsagePage | 0x50, // ArrowLeft
+	0x14d: hidUsagePage | 0x4f, // ArrowRight
+	0x14f: hidUsagePage | 0x4d, // End
+	0x150: hidUsagePage | 0x51, // ArrowDown
+	0x151: hidUsagePage | 0x4e, // PageDown
+	0x152: hidUsagePage | 0x49, // Insert
+	0x153: hidUsagePage | 0x4c, // Delete
+	0x15b: hidUsagePage | 0xe3, // MetaLeft
+	0x15c: hidUsagePage | 0xe7, // MetaRight
+	0x15d: hidUsagePage | 0x65, // ContextMenu
+}
+
+// macosScancodes maps the macOS virtual key codes to physical keys.
+var macosScancodes = map[int]uint64{
+	0x00: hidUsagePage | 0x04, // KeyA
+	0x01: hidUsagePage | 0x16, // KeyS
+	0x02: hidUsagePage | 0x07, // KeyD
+	0x03: hidUsagePage | 0x09, // KeyF
+	0x04: hidUsagePage | 0x0b, // KeyH
+	0x05: hidUsagePage | 0x0a, // KeyG
+	0x06: hidUsagePage | 0x1d, // KeyZ
+	0x07: hidUsagePage | 0x1b, // KeyX
+	0x08: hidUsagePage | 0x06, // KeyC
+	0x09: hidUsagePage | 0x19, // KeyV
+	0x0a: hidUsagePage | 0x64, // IntlBackslash
+	0x0b: hidUsagePage | 0x05, // KeyB
+	0x0c: hidUsagePage | 0x14, // KeyQ
+	0x0d: hidUsagePage | 0x1a, // KeyW
+	0x0e: hidUsagePage | 0x08, // KeyE
+	0x0f: hidUsagePage | 0x15, // KeyR
+	0x10: hidUsagePage | 0x1c, // KeyY
+	0x11: hidUsagePage | 0x17, // KeyT
+	0x12: hidUsagePage | 0x1e, // Digit1
+	0x13: hidUsagePage | 0x1f, // Digit2
+	0x14: hidUsagePage | 0x20, // Digit3
+	0x15: hidUsagePage | 0x21, // Digit4
+	0x16: hidUsagePage | 0x23, // Digit6
+	0x17: hidUsagePage | 0x22, // Digit5
+	0x18: hidUsagePage | 0x2e, // Equal
+	0x19: hidUsagePage | 0x26, // Digit9
+	0x1a: hidUsagePage | 0x24, // Digit7
+	0x1b: hidUsagePage | 0x2d, // Minus
+	0x1c: hidUsagePage | 0x25, // Digit8
+	0x1d: hidUsagePage | 0x27, // Digit0
+	0x1e: hidUsagePage | 0x30, // BracketRight
+	0x1f: hidUsagePage | 0x12, // KeyO
+	0x20: hidUsagePage | 0x18, // KeyU
+	0x21: hidUsagePage | 0x2f, // BracketLeft
+	0x22: hidUsagePage | 0x0c, // KeyI
+	0x23: hidUsagePage | 0x13, // KeyP
+	0x24: hidUsagePage | 0x28, // Enter
+	0x25: hidUsagePage | 0x0f, // KeyL
+	0x26: hidUsagePage | 0x0d, // KeyJ
+	0x27: hidUsagePage | 0x34, // Quote
+	0x28: hidUsagePage | 0x0e, // KeyK
+	0x29: hidUsagePage | 0x33, // Semicolon
+	0x2a: hidUsagePage | 0x31, // Backslash
+	0x2b: hidUsagePage | 0x36, // Comma
+	0x2c: hidUsagePage | 0x38, // Slash
+	0x2d: hidUsagePage | 0x11, // KeyN
+	0x2e: hidUsagePage | 0x10, // KeyM
+	0x2f: hidUsagePage | 0x37, // Period
+	0x30: hidUsagePage | 0x2b, // Tab
+	0x31: hidUsagePage | 0x2c, // Space
+	0x32: hidUsagePage | 0x35, // Backquote
+	0x33: hidUsagePage | 0x2a, // Backspace
+	0x35: hidUsagePage | 0x29, // Escape
+	0x36: hidUsagePage | 0xe7, // MetaRight
+	0x37: hidUsagePage | 0xe3, // MetaLeft
+	0x38: hidUsagePage | 0xe1, // ShiftLeft
+	0x39: hidUsagePage | 0x39, // CapsLock
+	0x3a: hidUsagePage | 0xe2, // AltLeft
+	0x3b: hidUsagePage | 0xe0, // ControlLeft
+	0x3c: hidUsagePage | 0xe5, // ShiftRight
+	0x3d: hidUsagePage | 0xe6, // AltRight
+	0x3e: hidUsagePage | 0xe4, // ControlRight
+	0x40: hidUsagePage | 0x6c, // F17
+	0x41: hidUsagePage | 0x63, // NumpadDecimal
+	0x43: hidUsagePage | 0x55, // NumpadMultiply
+	0x45: hidUsagePage | 0x57, // NumpadAdd
+	0x47: hidUsagePage | 0x53, // NumLock
+	0x4b: hidUsagePage | 0x54, // NumpadDivide
+	0x4c: hidUsagePage | 0x58, // NumpadEnter
+	0x4e: hidUsagePage | 0x56, // NumpadSubtract
+	0x4f: hidUsagePage | 0x6d, // F18
+	0x50: hidUsagePage | 0x6e, // F19
+	0x51: hidUsagePage | 0x67, // NumpadEqual
+	0x52: hidUsagePage | 0x62, // Numpad0
+	0x53: hidUsagePage | 0x59, // Numpad1
+	0x54: hidUsagePage | 0x5a, // Numpad2
+	0x55: hidUsagePage | 0x5b, // Numpad3
+	0x56: hidUsagePage | 0x5c, // Numpad4
+	0x57: hidUsagePage | 0x5d, // Numpad5
+	0x58: hidUsagePage | 0x5e, // Numpad6
+	0x59: hidUsagePage | 0x5f, // Numpad7
+	0x5a: hidUsagePage | 0x6f, // F20
+	0x5b: hidUsagePage | 0x60, // Numpad8
+	0x5c: hidUsagePage | 0x61, // Numpad9
+	0x60: hidUsagePage | 0x3e, // F5
+	0x61: hidUsagePage | 0x3f, // F6
+	0x62: hidUsagePage | 0x40, // F7
+	0x63: hidUsagePage | 0x3c, // F3
+	0x64: hidUsagePage | 0x41, // F8
+	0x65: hidUsagePage | 0x42, // F9
+	0x67: hidUsagePage | 0x44, // F11
+	0x69: hidUsagePage | 0x68, // F13
+	0x6a: hidUsagePage | 0x6b, // F16
+	0x6b: hidUsagePage | 0x69, // F14
+	0x6d: hidUsagePage | 0x43, // F10
+	0x6e: hidUsagePage | 0x65, // ContextMenu
+	0x6f: hidUsagePage | 0x45, // F12
+	0x71: hidUsagePage | 0x6a, // F15
+	0x72: hidUsagePage | 0x49, // Insert
+	0x73: hidUsagePage | 0x4a, // Home
+	0x74: hidUsagePage | 0x4b, // PageUp
+	0x75: hidUsagePage | 0x4c, // Delete
+	0x76: hidUsagePage | 0x3d, // F4
+	0x77: hidUsagePage | 0x4d, // End
+	0x78: hidUsagePage | 0x3b, // F2
+	0x79: hidUsagePage | 0x4e, // PageDown
+	0x7a: hidUsagePage | 0x3a, // F1
+	0x7b: hidUsagePage | 0x50, // ArrowLeft
+	0x7c: hidUsagePage | 0x4f, // ArrowRight
+	0x7d: hidUsagePage | 0x51, // ArrowDown
+	0x7e: hidUsagePage | 0x52, // ArrowUp
+}
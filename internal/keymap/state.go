@@ -0,0 +1,232 @@
+package keymap
+
+import "sort"
+
+// EventType is the type of a key event, with the values of the embedder
+// FlutterKeyEventType.
+type EventType int
+
+// Key event types.
+const (
+	EventUp EventType = iota + 1
+	EventDown
+	EventRepeat
+)
+
+// Event is a key event of the Flutter key data protocol. An event whose
+// Physical and Logical keys are 0 is an empty event.
+type Event struct {
+	Type        EventType
+	Physical    uint64
+	Logical     uint64
+	Character   string
+	Synthesized bool
+}
+
+// Modifiers are the modifier bits of the GLFW key events.
+type Modifiers int
+
+// Modifier bits, with the values of the GLFW_MOD constants.
+const (
+	ModShift    Modifiers = 0x1
+	ModControl  Modifiers = 0x2
+	ModAlt      Modifiers = 0x4
+	ModSuper    Modifiers = 0x8
+	ModCapsLock Modifiers = 0x10
+	ModNumLock  Modifiers = 0x20
+)
+
+// modifierKeys maps the modifier bits to the physical and logical keys
+// setting them, left key first.
+var modifierKeys = []struct {
+	mod     Modifiers
+	keys    [2]uint64
+	logical [2]uint64
+}{
+	{ModShift, [2]uint64{PhysicalShiftLeft, PhysicalShiftRight}, [2]uint64{LogicalShiftLeft, LogicalShiftRight}},
+	{ModControl, [2]uint64{PhysicalControlLeft, PhysicalControlRight}, [2]uint64{LogicalControlLeft, LogicalControlRight}},
+	{ModAlt, [2]uint64{PhysicalAltLeft, PhysicalAltRight}, [2]uint64{LogicalAltLeft, LogicalAltRight}},
+	{ModSuper, [2]uint64{PhysicalMetaLeft, PhysicalMetaRight}, [2]uint64{LogicalMetaLeft, LogicalMetaRight}},
+}
+
+// lockKeys maps the lock bits to the keys toggling them.
+var lockKeys = []struct {
+	mod      Modifiers
+	physical uint64
+	logical  uint64
+}{
+	{ModCapsLock, PhysicalCapsLock, LogicalCapsLock},
+	{ModNumLock, PhysicalNumLock, LogicalNumLock},
+}
+
+// State records the pressed keys, to send regular event sequences to the
+// framework: a down event, zero or more repeat events and an up event, with
+// the same logical key. It isn't safe for concurrent use.
+//
+// The framework toggles its lock modes on the down events of the lock keys,
+// State records them to keep them in sync with the lock bits of the native
+// events, which GLFW only reports with its LockKeyMods input mode.
+type State struct {
+	// pressed maps the pressed physical keys to their logical key.
+	pressed map[uint64]uint64
+	// locks are the lock modes enabled in the framework.
+	locks Modifiers
+}
+
+// NewState creates a State without pressed keys.
+func NewState() *State {
+	return &State{pressed: make(map[uint64]uint64)}
+}
+
+// Handle records a native key event and returns the events to send, at least
+// one:
+//   - a down event for a pressed key is converted to a repeat event,
+//   - a repeat event for a released key is converted to a down event,
+//   - an up event for a released key is converted to an empty event.
+//
+// The events of a key keep the logical key of its down event. mods are the
+// modifiers of the native event, the modifier and lock state is synchronized
+// with them before the event, as described by Sync.
+func (s *State) Handle(typ EventType, physical, logical uint64, character string, mods Modifiers) []Event {
+	events := s.sync(physical, mods)
+	event := Event{
+		Type:      typ,
+		Physical:  physical,
+		Logical:   logical,
+		Character: character,
+	}
+	pressedLogical, pressed := s.pressed[physical]
+	switch typ {
+	case EventDown, EventRepeat:
+		if pressed {
+			event.Type = EventRepeat
+			event.Logical = pressedLogical
+		} else {
+			event.Type = EventDown
+			s.press(physical, logical)
+		}
+	case EventUp:
+		if !pressed {
+			if len(events) > 0 {
+				return events
+			}
+			return []Event{{}}
+		}
+		event.Logical = pressedLogical
+		event.Character = ""
+		delete(s.pressed, physical)
+	}
+	return append(events, event)
+}
+
+// Sync synchronizes the state with the modifiers of a native event which
+// isn't a key event, e.g., a mouse button event, and returns the synthesized
+// events:
+//   - the up events of the pressed modifier keys whose bit isn't set, e.g.,
+//     the releases missed by GLFW while the window was unfocused,
+//   - the down event of the left modifier key of the bits set without a
+//     pressed key,
+//   - a down and an up event of the lock keys whose lock mode differs from
+//     the lock bits.
+func (s *State) Sync(mods Modifiers) []Event {
+	return s.sync(0, mods)
+}
+
+// sync implements Sync before a key event. The modifier bits of GLFW don't
+// reliably include the key of the event itself, whose modifier or lock mode
+// isn't checked.
+func (s *State) sync(physical uint64, mods Modifiers) []Event {
+	var events []Event
+	for _, modifier := range modifierKeys {
+		if physical == modifier.keys[0] || physical == modifier.keys[1] {
+			continue
+		}
+		if mods&modifier.mod != 0 {
+			_, left := s.pressed[modifier.keys[0]]
+			_, right := s.pressed[modifier.keys[1]]
+			if !left && !right {
+				events = append(events, Event{
+					Type:        EventDown,
+					Physical:    modifier.keys[0],
+					Logical:     modifier.logical[0],
+					Synthesized: true,
+				})
+				s.press(modifier.keys[0], modifier.logical[0])
+			}
+			continue
+		}
+		for _, key := range modifier.keys {
+			if logical, ok := s.pressed[key]; ok {
+				events = append(events, Event{
+					Type:        EventUp,
+					Physical:    key,
+					Logical:     logical,
+					Synthesized: true,
+				})
+				delete(s.pressed, key)
+			}
+		}
+	}
+
+	for _, lock := range lockKeys {
+		if physical == lock.physical || mods&lock.mod == s.locks&lock.mod {
+			continue
+		}
+		if _, pressed := s.pressed[lock.physical]; pressed {
+			continue
+		}
+		events = append(events,
+			Event{Type: EventDown, Physical: lock.physical, Logical: lock.logical, Synthesized: true},
+			Event{Type: EventUp, Physical: lock.physical, Logical: lock.logical, Synthesized: true},
+		)
+		s.locks ^= lock.mod
+	}
+	return events
+}
+
+// ReleaseAll releases the pressed keys, e.g., when the window loses the
+// focus, and returns their synthesized up events.
+func (s *State) ReleaseAll() []Event {
+	keys := make([]uint64, 0, len(s.pressed))
+	for physical := range s.pressed {
+		keys = append(keys, physical)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	events := make([]Event, 0, len(keys))
+	for _, physical := range keys {
+		events = append(events, Event{
+			Type:        EventUp,
+			Physical:    physical,
+			Logical:     s.pressed[physical],
+			Synthesized: true,
+		})
+		delete(s.pressed, physical)
+	}
+	return events
+}
+
+// Locks returns the lock modes enabled in the framework.
+func (s *State) Locks() Modifiers {
+	return s.locks
+}
+
+// press records a down event, toggling the lock mode of the lock keys.
+func (s *State) press(physical, logical uint64) {
+	s.pressed[physical] = logical
+	for _, lock := range lockKeys {
+		if physical == lock.physical {
+			s.locks ^= lock.mod
+		}
+	}
+}
+
+// Pressed returns the pressed keys, mapping their physical key to their
+// logical key.
+func (s *State) Pressed() map[uint64]uint64 {
+	pressed := make(map[uint64]uint64, len(s.pressed))
+	for physical, logical := range s.pressed {
+		pressed[physical] = logical
+	}
+	return pressed
+}
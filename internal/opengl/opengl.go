@@ -11,12 +11,19 @@ import (
 
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/pkg/errors"
 )
 
 // const exposed to go-flutter
 const (
 	TEXTURE2D = gl.TEXTURE_2D
 	RGBA8     = gl.RGBA8
+
+	// pixel formats accepted by TexImage2DFormat
+	RGBA = gl.RGBA
+	BGRA = gl.BGRA
+	RED  = gl.RED
+	RG   = gl.RG
 )
 
 // Init opengl
@@ -68,6 +75,93 @@ func TexImage2D(width, height int32, pixels unsafe.Pointer) {
 	)
 }
 
+// TexImage2DFormat specifies a two-dimensional texture image from pixels in
+// the given format, whose rows are rowLength pixels apart. Formats with one
+// or two components are stored as R8 and RG8 textures, the other ones as
+// RGBA8.
+func TexImage2DFormat(width, height, rowLength int32, format uint32, pixels unsafe.Pointer) {
+	internalFormat := int32(gl.RGBA8)
+	switch format {
+	case gl.RED:
+		internalFormat = gl.R8
+	case gl.RG:
+		internalFormat = gl.RG8
+	}
+
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, rowLength)
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		internalFormat,
+		width,
+		height,
+		0,
+		format,
+		gl.UNSIGNED_BYTE,
+		pixels,
+	)
+	// restore the defaults expected by the engine
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+}
+
+// CreateFramebuffer creates a framebuffer
+func CreateFramebuffer(fbo *uint32) {
+	gl.GenFramebuffers(1, fbo)
+}
+
+// AllocateTexture allocates the storage of a width x height RGBA8 texture,
+// leaving its content undefined.
+func AllocateTexture(texture uint32, width, height int32) {
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// BindFramebufferTexture binds the framebuffer, rendering to texture, and
+// sets the viewport to the texture dimensions.
+func BindFramebufferTexture(fbo uint32, texture uint32, width, height int32) error {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture, 0)
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return errors.Errorf("incomplete framebuffer, status 0x%x", status)
+	}
+	gl.Viewport(0, 0, width, height)
+	return nil
+}
+
+// UnbindFramebuffer binds the default framebuffer.
+func UnbindFramebuffer() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// DeleteFramebuffer deletes a framebuffer
+func DeleteFramebuffer(fbo *uint32) {
+	gl.DeleteFramebuffers(1, fbo)
+	*fbo = 0
+}
+
+// FenceSync creates a fence signaled once the previous commands completed,
+// and flushes the commands so the fence can be waited on from other
+// contexts.
+func FenceSync() uintptr {
+	sync := gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	gl.Flush()
+	return sync
+}
+
+// WaitSync makes the server wait for the fence before executing the next
+// commands of the current context, the caller isn't blocked.
+func WaitSync(sync uintptr) {
+	gl.WaitSync(sync, 0, gl.TIMEOUT_IGNORED)
+}
+
+// DeleteSync deletes a fence
+func DeleteSync(sync uintptr) {
+	gl.DeleteSync(sync)
+}
+
 // GLFWWindowHint sets hints for the next call to CreateWindow.
 func GLFWWindowHint() {
 	glfw.WindowHint(glfw.ContextVersionMajor, 3)
@@ -9,12 +9,20 @@ package opengl
 
 import (
 	"unsafe"
+
+	"github.com/pkg/errors"
 )
 
 // const exposed to go-flutter
 const (
 	TEXTURE2D = 0
 	RGBA8     = 0
+
+	// pixel formats accepted by TexImage2DFormat
+	RGBA = 0
+	BGRA = 1
+	RED  = 2
+	RG   = 3
 )
 
 // Init opengl
@@ -38,5 +46,56 @@ func TexImage2D(width, height int32, pixels unsafe.Pointer) {
 	panic("go-flutter: go-flutter wasn't compiled with support for external texture plugin.")
 }
 
+// TexImage2DFormat specifies a two-dimensional texture image
+func TexImage2DFormat(width, height, rowLength int32, format uint32, pixels unsafe.Pointer) {
+	panic("go-flutter: go-flutter wasn't compiled with support for external texture plugin.")
+}
+
+// YUVPlane is a plane of a YUV image.
+type YUVPlane struct {
+	Width, Height, RowLength int32
+	Pixels                   unsafe.Pointer
+}
+
+// YUVConverter converts YUV images to RGBA textures.
+type YUVConverter struct{}
+
+// NewYUVConverter creates a YUVConverter
+func NewYUVConverter() (*YUVConverter, error) {
+	return nil, errors.New("go-flutter wasn't compiled with support for external texture plugin")
+}
+
+// Convert renders the YUV planes into texture
+func (c *YUVConverter) Convert(texture uint32, width, height int32, planes []YUVPlane) {}
+
+// Delete releases the resources of the converter
+func (c *YUVConverter) Delete() {}
+
+// CreateFramebuffer creates a framebuffer
+func CreateFramebuffer(fbo *uint32) {}
+
+// AllocateTexture allocates the storage of a texture
+func AllocateTexture(texture uint32, width, height int32) {}
+
+// BindFramebufferTexture binds the framebuffer
+func BindFramebufferTexture(fbo uint32, texture uint32, width, height int32) error {
+	return errors.New("go-flutter wasn't compiled with support for external texture plugin")
+}
+
+// UnbindFramebuffer binds the default framebuffer.
+func UnbindFramebuffer() {}
+
+// DeleteFramebuffer deletes a framebuffer
+func DeleteFramebuffer(fbo *uint32) {}
+
+// FenceSync creates a fence
+func FenceSync() uintptr { return 0 }
+
+// WaitSync waits for a fence
+func WaitSync(sync uintptr) {}
+
+// DeleteSync deletes a fence
+func DeleteSync(sync uintptr) {}
+
 // GLFWWindowHint sets hints for the next call to CreateWindow.
 func GLFWWindowHint() {}
@@ -0,0 +1,246 @@
+// +build !openglnone
+
+package opengl
+
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/pkg/errors"
+)
+
+const yuvVertexShader = `#version 330 core
+out vec2 uv;
+void main() {
+	// a triangle covering the viewport, without vertex buffer
+	vec2 pos = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+	uv = pos;
+	gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
+` + "\x00"
+
+// yuvFragmentShader converts BT.601 limited range YUV to RGB.
+const yuvFragmentShader = `#version 330 core
+in vec2 uv;
+out vec4 color;
+uniform sampler2D planeY;
+uniform sampler2D planeU;
+uniform sampler2D planeV;
+uniform int interleaved;
+void main() {
+	float y = 1.164383 * (texture(planeY, uv).r - 0.0625);
+	vec2 c;
+	if (interleaved == 1) {
+		c = texture(planeU, uv).rg;
+	} else {
+		c = vec2(texture(planeU, uv).r, texture(planeV, uv).r);
+	}
+	c -= 0.5;
+	color = vec4(
+		y + 1.596027 * c.y,
+		y - 0.391762 * c.x - 0.812968 * c.y,
+		y + 2.017232 * c.x,
+		1.0);
+}
+` + "\x00"
+
+// YUVPlane is a plane of a YUV image.
+type YUVPlane struct {
+	// Width and Height of the plane, in samples.
+	Width, Height int32
+	// RowLength is the number of samples between the start of two rows.
+	RowLength int32
+	Pixels    unsafe.Pointer
+}
+
+// YUVConverter converts YUV images to RGBA textures with a shader. It must be
+// used with the OpenGL context it was created with.
+type YUVConverter struct {
+	program     uint32
+	vao         uint32
+	fbo         uint32
+	planes      [3]uint32
+	interleaved int32
+}
+
+// NewYUVConverter creates a YUVConverter, an OpenGL context must be current.
+func NewYUVConverter() (*YUVConverter, error) {
+	program, err := linkProgram(yuvVertexShader, yuvFragmentShader)
+	if err != nil {
+		return nil, err
+	}
+	c := &YUVConverter{program: program}
+	c.interleaved = gl.GetUniformLocation(program, gl.Str("interleaved\x00"))
+	gl.GenVertexArrays(1, &c.vao)
+	gl.GenFramebuffers(1, &c.fbo)
+	gl.GenTextures(3, &c.planes[0])
+	for _, texture := range c.planes {
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	var previous int32
+	gl.GetIntegerv(gl.CURRENT_PROGRAM, &previous)
+	gl.UseProgram(program)
+	for i, name := range []string{"planeY\x00", "planeU\x00", "planeV\x00"} {
+		gl.Uniform1i(gl.GetUniformLocation(program, gl.Str(name)), int32(i))
+	}
+	gl.UseProgram(uint32(previous))
+	return c, nil
+}
+
+// Convert renders the YUV planes into texture, which is (re)allocated as a
+// width x height RGBA8 texture. Two planes are Y and interleaved UV (NV12),
+// three planes are Y, U and V (I420).
+//
+// The OpenGL state modified by the conversion is restored, the context is
+// shared with the engine.
+func (c *YUVConverter) Convert(texture uint32, width, height int32, planes []YUVPlane) {
+	state := saveState()
+	defer state.restore()
+
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.Disable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.STENCIL_TEST)
+	gl.Disable(gl.CULL_FACE)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+
+	for i, plane := range planes {
+		format := uint32(gl.RED)
+		if len(planes) == 2 && i == 1 {
+			format = gl.RG
+		}
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
+		gl.BindTexture(gl.TEXTURE_2D, c.planes[i])
+		TexImage2DFormat(plane.Width, plane.Height, plane.RowLength, format, plane.Pixels)
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, c.fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture, 0)
+	gl.Viewport(0, 0, width, height)
+	gl.UseProgram(c.program)
+	if len(planes) == 2 {
+		gl.Uniform1i(c.interleaved, 1)
+	} else {
+		gl.Uniform1i(c.interleaved, 0)
+	}
+	gl.BindVertexArray(c.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, 0, 0)
+}
+
+// Delete releases the OpenGL resources of the converter.
+func (c *YUVConverter) Delete() {
+	gl.DeleteTextures(3, &c.planes[0])
+	gl.DeleteFramebuffers(1, &c.fbo)
+	gl.DeleteVertexArrays(1, &c.vao)
+	gl.DeleteProgram(c.program)
+}
+
+// glState is the part of the OpenGL state modified by the YUVConverter.
+type glState struct {
+	drawFramebuffer, readFramebuffer int32
+	viewport                         [4]int32
+	program                          int32
+	vertexArray                      int32
+	activeTexture                    int32
+	textures                         [3]int32
+	capabilities                     map[uint32]bool
+}
+
+func saveState() *glState {
+	s := &glState{capabilities: make(map[uint32]bool)}
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &s.drawFramebuffer)
+	gl.GetIntegerv(gl.READ_FRAMEBUFFER_BINDING, &s.readFramebuffer)
+	gl.GetIntegerv(gl.VIEWPORT, &s.viewport[0])
+	gl.GetIntegerv(gl.CURRENT_PROGRAM, &s.program)
+	gl.GetIntegerv(gl.VERTEX_ARRAY_BINDING, &s.vertexArray)
+	gl.GetIntegerv(gl.ACTIVE_TEXTURE, &s.activeTexture)
+	for i := range s.textures {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
+		gl.GetIntegerv(gl.TEXTURE_BINDING_2D, &s.textures[i])
+	}
+	for _, capability := range []uint32{gl.SCISSOR_TEST, gl.BLEND, gl.DEPTH_TEST, gl.STENCIL_TEST, gl.CULL_FACE} {
+		s.capabilities[capability] = gl.IsEnabled(capability)
+	}
+	return s
+}
+
+func (s *glState) restore() {
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(s.drawFramebuffer))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(s.readFramebuffer))
+	gl.Viewport(s.viewport[0], s.viewport[1], s.viewport[2], s.viewport[3])
+	gl.UseProgram(uint32(s.program))
+	gl.BindVertexArray(uint32(s.vertexArray))
+	for i, texture := range s.textures {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
+		gl.BindTexture(gl.TEXTURE_2D, uint32(texture))
+	}
+	gl.ActiveTexture(uint32(s.activeTexture))
+	for capability, enabled := range s.capabilities {
+		if enabled {
+			gl.Enable(capability)
+		} else {
+			gl.Disable(capability)
+		}
+	}
+}
+
+func linkProgram(vertexSource, fragmentSource string) (uint32, error) {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, errors.Wrap(err, "vertex shader")
+	}
+	defer gl.DeleteShader(vertexShader)
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, errors.Wrap(err, "fragment shader")
+	}
+	defer gl.DeleteShader(fragmentShader)
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+	gl.LinkProgram(program)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(program)
+		return 0, errors.Errorf("failed to link program: %v", log)
+	}
+	return program, nil
+}
+
+func compileShader(source string, shaderType uint32) (uint32, error) {
+	shader := gl.CreateShader(shaderType)
+	csources, free := gl.Strs(source)
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
+		return 0, errors.Errorf("failed to compile shader: %v", log)
+	}
+	return shader, nil
+}
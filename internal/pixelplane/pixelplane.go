@@ -0,0 +1,117 @@
+// Package pixelplane computes the layout of the planes of the pixel buffers
+// given to the external textures.
+package pixelplane
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Format is the layout of the pixels of a buffer, with the values of
+// flutter.PixelFormat.
+type Format int
+
+// Pixel formats.
+const (
+	RGBA Format = iota
+	BGRA
+	NV12
+	I420
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case RGBA:
+		return "RGBA"
+	case BGRA:
+		return "BGRA"
+	case NV12:
+		return "NV12"
+	case I420:
+		return "I420"
+	default:
+		return "unknown"
+	}
+}
+
+// Plane is the layout of a plane of a buffer.
+type Plane struct {
+	// Offset of the plane in the buffer
+	Offset int
+	// Width and Height of the plane, in samples
+	Width, Height int
+	// RowLength is the number of samples between the start of two rows
+	RowLength int
+	// bytes per sample
+	SampleSize int
+}
+
+// End returns the offset following the last sample of the plane.
+func (p Plane) End() int {
+	if p.Height == 0 {
+		return p.Offset
+	}
+	return p.Offset + ((p.Height-1)*p.RowLength+p.Width)*p.SampleSize
+}
+
+// Layout returns the planes of a buffer of size bytes, holding pixels of
+// width by height in format. stride is the number of bytes between the start
+// of two rows of the first plane, the rows are tightly packed when it's 0.
+func Layout(format Format, width, height, stride, size int) ([]Plane, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.Errorf("invalid dimensions %dx%d", width, height)
+	}
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+
+	var planes []Plane
+	switch format {
+	case RGBA, BGRA:
+		if stride == 0 {
+			stride = width * 4
+		}
+		if stride%4 != 0 || stride < width*4 {
+			return nil, errors.Errorf("invalid stride %d for %s pixels of width %d", stride, format, width)
+		}
+		planes = []Plane{
+			{Width: width, Height: height, RowLength: stride / 4, SampleSize: 4},
+		}
+
+	case NV12:
+		if stride == 0 {
+			stride = chromaWidth * 2
+		}
+		if stride%2 != 0 || stride < width || stride < chromaWidth*2 {
+			return nil, errors.Errorf("invalid stride %d for %s pixels of width %d", stride, format, width)
+		}
+		planes = []Plane{
+			{Width: width, Height: height, RowLength: stride, SampleSize: 1},
+			{Offset: stride * height, Width: chromaWidth, Height: chromaHeight, RowLength: stride / 2, SampleSize: 2},
+		}
+
+	case I420:
+		if stride == 0 {
+			stride = width
+		}
+		// the chroma rows of odd widths hold the rounded up half
+		chromaStride := (stride + 1) / 2
+		if stride < width {
+			return nil, errors.Errorf("invalid stride %d for %s pixels of width %d", stride, format, width)
+		}
+		uOffset := stride * height
+		vOffset := uOffset + chromaStride*chromaHeight
+		planes = []Plane{
+			{Width: width, Height: height, RowLength: stride, SampleSize: 1},
+			{Offset: uOffset, Width: chromaWidth, Height: chromaHeight, RowLength: chromaStride, SampleSize: 1},
+			{Offset: vOffset, Width: chromaWidth, Height: chromaHeight, RowLength: chromaStride, SampleSize: 1},
+		}
+
+	default:
+		return nil, errors.Errorf("unknown pixel format %d", format)
+	}
+
+	if end := planes[len(planes)-1].End(); size < end {
+		return nil, errors.Errorf("%s pixels of %dx%d with stride %d need %d bytes, got %d",
+			format, width, height, stride, end, size)
+	}
+	return planes, nil
+}
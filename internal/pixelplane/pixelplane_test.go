@@ -0,0 +1,83 @@
+package pixelplane
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayout(t *testing.T) {
+	for _, test := range []struct {
+		name                        string
+		format                      Format
+		width, height, stride, size int
+		want                        []Plane
+	}{
+		{
+			name: "RGBA", format: RGBA, width: 3, height: 2, size: 24,
+			want: []Plane{{Width: 3, Height: 2, RowLength: 3, SampleSize: 4}},
+		},
+		{
+			name: "BGRA padded", format: BGRA, width: 3, height: 2, stride: 16, size: 28,
+			want: []Plane{{Width: 3, Height: 2, RowLength: 4, SampleSize: 4}},
+		},
+		{
+			name: "NV12", format: NV12, width: 4, height: 2, size: 12,
+			want: []Plane{
+				{Width: 4, Height: 2, RowLength: 4, SampleSize: 1},
+				{Offset: 8, Width: 2, Height: 1, RowLength: 2, SampleSize: 2},
+			},
+		},
+		{
+			name: "I420", format: I420, width: 4, height: 4, size: 24,
+			want: []Plane{
+				{Width: 4, Height: 4, RowLength: 4, SampleSize: 1},
+				{Offset: 16, Width: 2, Height: 2, RowLength: 2, SampleSize: 1},
+				{Offset: 20, Width: 2, Height: 2, RowLength: 2, SampleSize: 1},
+			},
+		},
+		{
+			name: "I420 odd width", format: I420, width: 3, height: 3, size: 17,
+			want: []Plane{
+				{Width: 3, Height: 3, RowLength: 3, SampleSize: 1},
+				{Offset: 9, Width: 2, Height: 2, RowLength: 2, SampleSize: 1},
+				{Offset: 13, Width: 2, Height: 2, RowLength: 2, SampleSize: 1},
+			},
+		},
+		{
+			name: "I420 odd width with stride", format: I420, width: 3, height: 3, stride: 3, size: 17,
+			want: []Plane{
+				{Width: 3, Height: 3, RowLength: 3, SampleSize: 1},
+				{Offset: 9, Width: 2, Height: 2, RowLength: 2, SampleSize: 1},
+				{Offset: 13, Width: 2, Height: 2, RowLength: 2, SampleSize: 1},
+			},
+		},
+		{
+			name: "I420 padded", format: I420, width: 3, height: 2, stride: 8, size: 24,
+			want: []Plane{
+				{Width: 3, Height: 2, RowLength: 8, SampleSize: 1},
+				{Offset: 16, Width: 2, Height: 1, RowLength: 4, SampleSize: 1},
+				{Offset: 20, Width: 2, Height: 1, RowLength: 4, SampleSize: 1},
+			},
+		},
+	} {
+		planes, err := Layout(test.format, test.width, test.height, test.stride, test.size)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.want, planes, test.name)
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	for name, args := range map[string][5]int{
+		"empty":        {int(RGBA), 0, 2, 0, 16},
+		"short stride": {int(RGBA), 3, 2, 8, 24},
+		"odd NV12":     {int(NV12), 4, 2, 5, 12},
+		"short I420":   {int(I420), 3, 3, 2, 17},
+		"small buffer": {int(I420), 3, 3, 0, 16},
+		"unknown":      {42, 2, 2, 0, 16},
+	} {
+		_, err := Layout(Format(args[0]), args[1], args[2], args[3], args[4])
+		assert.Error(t, err, name)
+	}
+}
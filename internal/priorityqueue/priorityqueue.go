@@ -1,24 +1,25 @@
 package priorityqueue
 
 import (
+	"container/heap"
 	"sync"
 	"time"
-
-	"github.com/go-flutter-desktop/go-flutter/embedder"
 )
 
 // An Item is something we manage in a priority queue.
 type Item struct {
-	Value    embedder.FlutterTask // The value of the item
-	FireTime time.Time            // The priority of the item in the queue.
+	Value    interface{} // The value of the item, e.g., an embedder.FlutterTask
+	FireTime time.Time   // The priority of the item in the queue.
 
 	// The index is needed by update and is maintained by the heap.Interface methods.
-	index int // The index of the item in the heap.
+	index int    // The index of the item in the heap.
+	seq   uint64 // Orders the items with the same FireTime.
 }
 
 // A PriorityQueue implements heap.Interface and holds Items.
 type PriorityQueue struct {
 	queue []*Item
+	seq   uint64
 	sync.Mutex
 }
 
@@ -33,7 +34,11 @@ func (pq *PriorityQueue) Len() int { return len(pq.queue) }
 
 func (pq *PriorityQueue) Less(i, j int) bool {
 	// We want Pop to give us the lowest, not highest, priority so we use lower
-	// than here.
+	// than here. Items with the same priority are popped in the order they
+	// were pushed.
+	if pq.queue[i].FireTime.Equal(pq.queue[j].FireTime) {
+		return pq.queue[i].seq < pq.queue[j].seq
+	}
 	return pq.queue[i].FireTime.Before(pq.queue[j].FireTime)
 }
 
@@ -48,6 +53,8 @@ func (pq *PriorityQueue) Push(x interface{}) {
 	n := len(pq.queue)
 	item := x.(*Item)
 	item.index = n
+	pq.seq++
+	item.seq = pq.seq
 	pq.queue = append(pq.queue, item)
 }
 
@@ -60,3 +67,19 @@ func (pq *PriorityQueue) Pop() interface{} {
 	pq.queue = old[0 : n-1]
 	return item
 }
+
+// PopExpired removes and returns the items whose FireTime isn't after now, in
+// order, and returns the FireTime of the next item. hasNext is false when the
+// queue is empty. The caller must hold the lock.
+func (pq *PriorityQueue) PopExpired(now time.Time) (expired []*Item, next time.Time, hasNext bool) {
+	for pq.Len() > 0 {
+		top := pq.queue[0]
+		// If this item (and all items after this) has not yet expired, there
+		// is nothing more to do.
+		if top.FireTime.After(now) {
+			return expired, top.FireTime, true
+		}
+		expired = append(expired, heap.Pop(pq).(*Item))
+	}
+	return expired, time.Time{}, false
+}
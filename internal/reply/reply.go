@@ -0,0 +1,159 @@
+// Package reply makes sure that the platform messages sent by Flutter get
+// at most one reply, and reports the messages left without a reply.
+package reply
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// Tracker tracks the messages waiting for a reply.
+type Tracker struct {
+	timeout time.Duration
+	// report prints the messages without a reply, replaced by the tests.
+	report func(format string, args ...interface{})
+
+	lock    sync.Mutex
+	pending map[*Sender]struct{}
+	closed  bool
+}
+
+// NewTracker creates a Tracker reporting the messages still waiting for a
+// reply after timeout. Handlers may reply from any goroutine, after they
+// return, the messages aren't replied to by the Tracker until Close.
+func NewTracker(timeout time.Duration) *Tracker {
+	return &Tracker{
+		timeout: timeout,
+		report: func(format string, args ...interface{}) {
+			fmt.Printf(format, args...)
+		},
+		pending: make(map[*Sender]struct{}),
+	}
+}
+
+// Sender is the plugin.ResponseSender of a message. Extra replies are
+// dropped and reported.
+type Sender struct {
+	tracker *Tracker
+	channel string
+	send    func(binaryReply []byte)
+
+	// guarded by the lock of the tracker
+	replied bool
+	timer   *time.Timer
+}
+
+// NewSender tracks a message received on channel, send passes the reply to
+// the engine. send is called at most once, and never after Close. A nil send
+// is given for the messages which expect no reply, they aren't tracked.
+func (t *Tracker) NewSender(channel string, send func(binaryReply []byte)) *Sender {
+	s := &Sender{
+		tracker: t,
+		channel: channel,
+		send:    send,
+	}
+	t.lock.Lock()
+	if t.closed || send == nil {
+		s.replied = true
+	} else {
+		t.pending[s] = struct{}{}
+		s.timer = time.AfterFunc(t.timeout, s.reportMissing)
+	}
+	t.lock.Unlock()
+	return s
+}
+
+// reportMissing reports the message when it's still waiting for a reply.
+func (s *Sender) reportMissing() {
+	s.tracker.lock.Lock()
+	missing := !s.replied
+	s.tracker.lock.Unlock()
+	if missing {
+		s.tracker.report("go-flutter: no response was sent after %v for a message on channel '%s'\n", s.tracker.timeout, s.channel)
+	}
+}
+
+var _ plugin.ResponseSender = &Sender{}
+
+// Send sends the reply, the first time it is called.
+func (s *Sender) Send(binaryReply []byte) {
+	if s.send == nil {
+		return // quick path when no response should be sent
+	}
+	if !s.reply() {
+		if s.tracker.isClosed() {
+			return
+		}
+		s.tracker.report("go-flutter: multiple responses sent for a message on channel '%s', only the first one was used\n", s.channel)
+		return
+	}
+	s.send(binaryReply)
+}
+
+// reply marks the message as replied, it reports whether it wasn't already.
+func (s *Sender) reply() bool {
+	s.tracker.lock.Lock()
+	defer s.tracker.lock.Unlock()
+	if s.replied {
+		return false
+	}
+	s.replied = true
+	s.timer.Stop()
+	delete(s.tracker.pending, s)
+	return true
+}
+
+func (t *Tracker) isClosed() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.closed
+}
+
+// Handle calls the handler of a message, or fallback for the channels
+// without handler. A nil reply, which triggers the dart
+// MissingPluginException, is sent when there is no handler. The handler may
+// reply after it returns.
+func Handle(channel string, binaryMessage []byte, handler, fallback plugin.ChannelHandlerFunc, s *Sender) {
+	if handler == nil {
+		handler = fallback
+	}
+	if handler == nil {
+		fmt.Println("go-flutter: no handler found for channel " + channel)
+		s.Send(nil)
+		return
+	}
+	err := handler(binaryMessage, s)
+	if err != nil {
+		fmt.Printf("go-flutter: handling message on channel "+channel+" failed: %v\n", err)
+	}
+}
+
+// Pending returns the number of messages waiting for a reply.
+func (t *Tracker) Pending() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return len(t.pending)
+}
+
+// Close reports the messages still waiting for a reply and replies nil to
+// them, before the engine shuts down. The later replies are dropped.
+func (t *Tracker) Close() {
+	t.lock.Lock()
+	t.closed = true
+	pending := make([]*Sender, 0, len(t.pending))
+	for s := range t.pending {
+		s.replied = true
+		s.timer.Stop()
+		pending = append(pending, s)
+	}
+	t.pending = nil
+	t.lock.Unlock()
+
+	for _, s := range pending {
+		t.report("go-flutter: no response was sent for a message on channel '%s' before the shutdown\n", s.channel)
+		s.send(nil)
+	}
+}
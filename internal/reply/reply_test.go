@@ -0,0 +1,166 @@
+package reply
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// sent records the replies passed to the engine.
+type sent struct {
+	replies [][]byte
+}
+
+func (s *sent) send(binaryReply []byte) {
+	s.replies = append(s.replies, binaryReply)
+}
+
+// newTestTracker creates a Tracker whose reports are recorded.
+func newTestTracker(timeout time.Duration) (*Tracker, *reports) {
+	tracker := NewTracker(timeout)
+	r := &reports{}
+	tracker.report = r.report
+	return tracker, r
+}
+
+// reports records the reports of a Tracker.
+type reports struct {
+	lock    sync.Mutex
+	reports []string
+}
+
+func (r *reports) report(format string, args ...interface{}) {
+	r.lock.Lock()
+	r.reports = append(r.reports, fmt.Sprintf(format, args...))
+	r.lock.Unlock()
+}
+
+func (r *reports) get() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.reports...)
+}
+
+func TestSenderReply(t *testing.T) {
+	tracker, reports := newTestTracker(time.Hour)
+	engine := &sent{}
+	s := tracker.NewSender("ch", engine.send)
+	assert.Equal(t, 1, tracker.Pending())
+
+	s.Send([]byte("reply"))
+	assert.Equal(t, [][]byte{[]byte("reply")}, engine.replies)
+	assert.Equal(t, 0, tracker.Pending())
+	assert.Empty(t, reports.get())
+}
+
+func TestSenderDuplicateReply(t *testing.T) {
+	tracker, reports := newTestTracker(time.Hour)
+	engine := &sent{}
+	s := tracker.NewSender("ch", engine.send)
+
+	s.Send([]byte("first"))
+	s.Send([]byte("second"))
+	assert.Equal(t, [][]byte{[]byte("first")}, engine.replies)
+	require.Len(t, reports.get(), 1)
+	assert.Contains(t, reports.get()[0], "multiple responses sent for a message on channel 'ch'")
+}
+
+func TestSenderMissingReply(t *testing.T) {
+	tracker, reports := newTestTracker(10 * time.Millisecond)
+	engine := &sent{}
+	s := tracker.NewSender("ch", engine.send)
+
+	// the missing reply is reported, not sent.
+	require.Eventually(t, func() bool { return len(reports.get()) == 1 }, time.Second, time.Millisecond)
+	assert.Contains(t, reports.get()[0], "no response was sent after 10ms for a message on channel 'ch'")
+	assert.Empty(t, engine.replies)
+	assert.Equal(t, 1, tracker.Pending())
+
+	// the late reply still reaches the engine.
+	s.Send([]byte("late"))
+	assert.Equal(t, [][]byte{[]byte("late")}, engine.replies)
+	assert.Equal(t, 0, tracker.Pending())
+	assert.Len(t, reports.get(), 1)
+}
+
+func TestTrackerClose(t *testing.T) {
+	tracker, reports := newTestTracker(time.Hour)
+	engine := &sent{}
+	pending := tracker.NewSender("pending", engine.send)
+	replied := tracker.NewSender("replied", engine.send)
+	replied.Send([]byte("reply"))
+
+	tracker.Close()
+	assert.Equal(t, [][]byte{[]byte("reply"), nil}, engine.replies)
+	require.Len(t, reports.get(), 1)
+	assert.Contains(t, reports.get()[0], "no response was sent for a message on channel 'pending' before the shutdown")
+
+	// no reply reaches the engine after Close
+	pending.Send([]byte("late"))
+	after := tracker.NewSender("ch", engine.send)
+	after.Send([]byte("after"))
+	assert.Equal(t, [][]byte{[]byte("reply"), nil}, engine.replies)
+	assert.Len(t, reports.get(), 1)
+}
+
+func TestHandle(t *testing.T) {
+	tracker, _ := newTestTracker(time.Hour)
+	engine := &sent{}
+	handler := func(binaryMessage []byte, r plugin.ResponseSender) error {
+		r.Send(append([]byte("handler "), binaryMessage...))
+		return nil
+	}
+	fallback := func(binaryMessage []byte, r plugin.ResponseSender) error {
+		r.Send(append([]byte("fallback "), binaryMessage...))
+		return nil
+	}
+
+	Handle("ch", []byte("a"), handler, fallback, tracker.NewSender("ch", engine.send))
+	Handle("unknown", []byte("b"), nil, fallback, tracker.NewSender("unknown", engine.send))
+	Handle("unknown", []byte("c"), nil, nil, tracker.NewSender("unknown", engine.send))
+	assert.Equal(t, [][]byte{[]byte("handler a"), []byte("fallback b"), nil}, engine.replies)
+	assert.Equal(t, 0, tracker.Pending())
+}
+
+func TestHandleAsyncReply(t *testing.T) {
+	tracker, reports := newTestTracker(time.Hour)
+	engine := &sent{}
+
+	// the handler returning without replying isn't replied to.
+	var later plugin.ResponseSender
+	Handle("ch", nil, func(binaryMessage []byte, r plugin.ResponseSender) error {
+		later = r
+		return nil
+	}, nil, tracker.NewSender("ch", engine.send))
+	Handle("ch", nil, func(binaryMessage []byte, r plugin.ResponseSender) error {
+		return errors.New("failed")
+	}, nil, tracker.NewSender("ch", engine.send))
+	assert.Empty(t, engine.replies)
+	assert.Equal(t, 2, tracker.Pending())
+
+	done := make(chan struct{})
+	go func() {
+		later.Send([]byte("async"))
+		close(done)
+	}()
+	<-done
+	assert.Equal(t, [][]byte{[]byte("async")}, engine.replies)
+	assert.Equal(t, 1, tracker.Pending())
+	assert.Empty(t, reports.get())
+}
+
+func TestSenderWithoutResponse(t *testing.T) {
+	tracker, reports := newTestTracker(0)
+	s := tracker.NewSender("ch", nil)
+	assert.Equal(t, 0, tracker.Pending())
+	s.Send([]byte("ignored"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, reports.get())
+}
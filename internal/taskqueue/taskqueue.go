@@ -0,0 +1,115 @@
+// Package taskqueue provides a lock-free queue of functions posted by any
+// goroutine and run, in batches, by a single consumer such as the platform
+// thread.
+package taskqueue
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Queue is an intrusive multi-producer single-consumer queue of tasks.
+//
+// Producers never block on Post. The wakeup function given to New is called
+// when a task is posted to a queue that isn't already waiting for a Drain, so
+// a burst of tasks only wakes the consumer once.
+type Queue struct {
+	// head is the last pushed node, swapped by producers.
+	head unsafe.Pointer // *node
+	// tail is the next node to pop, only accessed by the consumer.
+	tail *node
+	stub node
+
+	// pending is 1 when the wakeup function has been called and the queue
+	// hasn't been drained since.
+	pending uint32
+	wakeup  func()
+}
+
+type node struct {
+	next unsafe.Pointer // *node
+	f    func()
+}
+
+// New creates a Queue. wakeup is called by producers to signal the consumer
+// that tasks are waiting; it must not block.
+func New(wakeup func()) *Queue {
+	q := &Queue{wakeup: wakeup}
+	q.head = unsafe.Pointer(&q.stub)
+	q.tail = &q.stub
+	return q
+}
+
+// Post adds f to the queue and returns immediately.
+func (q *Queue) Post(f func()) {
+	q.push(&node{f: f})
+	// The node is linked before the flag is checked: if the consumer cleared
+	// the flag before the link, this call wakes it up again.
+	if atomic.CompareAndSwapUint32(&q.pending, 0, 1) && q.wakeup != nil {
+		q.wakeup()
+	}
+}
+
+// Do posts f and waits until it has been run.
+func (q *Queue) Do(f func()) {
+	done := make(chan struct{})
+	q.Post(func() {
+		defer close(done)
+		f()
+	})
+	<-done
+}
+
+// Drain runs the posted tasks, in order, and returns the number of tasks run.
+// Tasks posted while draining are run too. Drain must only be called by the
+// consumer.
+func (q *Queue) Drain() int {
+	atomic.StoreUint32(&q.pending, 0)
+	count := 0
+	for {
+		n := q.pop()
+		if n == nil {
+			return count
+		}
+		f := n.f
+		n.f = nil
+		f()
+		count++
+	}
+}
+
+func (q *Queue) push(n *node) {
+	prev := (*node)(atomic.SwapPointer(&q.head, unsafe.Pointer(n)))
+	atomic.StorePointer(&prev.next, unsafe.Pointer(n))
+}
+
+// pop returns the next node, or nil when the queue is empty or when the next
+// node is being linked by a producer. In the latter case the producer calls
+// wakeup once the node is linked.
+func (q *Queue) pop() *node {
+	tail := q.tail
+	next := (*node)(atomic.LoadPointer(&tail.next))
+	if tail == &q.stub {
+		if next == nil {
+			return nil
+		}
+		q.tail = next
+		tail = next
+		next = (*node)(atomic.LoadPointer(&tail.next))
+	}
+	if next != nil {
+		q.tail = next
+		return tail
+	}
+	if tail != (*node)(atomic.LoadPointer(&q.head)) {
+		return nil
+	}
+	atomic.StorePointer(&q.stub.next, nil)
+	q.push(&q.stub)
+	next = (*node)(atomic.LoadPointer(&tail.next))
+	if next != nil {
+		q.tail = next
+		return tail
+	}
+	return nil
+}
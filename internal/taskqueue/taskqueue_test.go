@@ -0,0 +1,250 @@
+package taskqueue
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/tasker"
+)
+
+func TestQueueOrderAndWakeups(t *testing.T) {
+	var wakeups int32
+	q := New(func() { atomic.AddInt32(&wakeups, 1) })
+
+	var got []int
+	for i := 0; i < 100; i++ {
+		i := i
+		q.Post(func() { got = append(got, i) })
+	}
+	if wakeups != 1 {
+		t.Fatalf("expected a single wakeup for a burst of tasks, got %d", wakeups)
+	}
+	if n := q.Drain(); n != 100 {
+		t.Fatalf("expected 100 tasks to run, got %d", n)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("tasks ran out of order: %v", got)
+		}
+	}
+	if n := q.Drain(); n != 0 {
+		t.Fatalf("expected an empty queue, got %d tasks", n)
+	}
+
+	q.Post(func() {})
+	if wakeups != 2 {
+		t.Fatalf("expected a wakeup after the queue was drained, got %d", wakeups)
+	}
+}
+
+func TestQueueConcurrentProducers(t *testing.T) {
+	const producers = 8
+	const tasks = 10000
+
+	wake := make(chan struct{}, 1)
+	q := New(func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	})
+
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	done := make(chan struct{})
+	var total int
+	go func() {
+		for total < producers*tasks {
+			select {
+			case <-wake:
+			case <-time.After(time.Second):
+				t.Errorf("missed wakeup, %d tasks run", total)
+				close(done)
+				return
+			}
+			total += q.Drain()
+		}
+		close(done)
+	}()
+
+	for p := 0; p < producers; p++ {
+		p := p
+		go func() {
+			for i := 0; i < tasks; i++ {
+				i := i
+				q.Post(func() {
+					if last[p] != i-1 {
+						t.Errorf("producer %d: task %d ran after %d", p, i, last[p])
+					}
+					last[p] = i
+				})
+			}
+		}()
+	}
+	<-done
+}
+
+func TestQueueDo(t *testing.T) {
+	q := New(nil)
+	ran := false
+	go func() {
+		for !ran {
+			q.Drain()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	q.Do(func() { ran = true })
+	if !ran {
+		t.Fatal("Do returned before the task ran")
+	}
+}
+
+// platformLoop imitates the main loop: it waits for a wakeup, like
+// glfw.WaitEventsTimeout, then runs the pending tasks.
+type platformLoop struct {
+	wake      chan struct{}
+	stop      chan struct{}
+	stopped   sync.WaitGroup
+	latencies []time.Duration
+}
+
+func newPlatformLoop(run func()) *platformLoop {
+	l := &platformLoop{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+	l.stopped.Add(1)
+	go func() {
+		defer l.stopped.Done()
+		for {
+			select {
+			case <-l.wake:
+			case <-time.After(25 * time.Millisecond):
+			case <-l.stop:
+				return
+			}
+			run()
+		}
+	}()
+	return l
+}
+
+func (l *platformLoop) postEmptyEvent() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// task returns a task recording its latency, it must run on the loop.
+func (l *platformLoop) task(wg *sync.WaitGroup) func() {
+	posted := time.Now()
+	return func() {
+		l.latencies = append(l.latencies, time.Since(posted))
+		wg.Done()
+	}
+}
+
+func (l *platformLoop) close() {
+	close(l.stop)
+	l.stopped.Wait()
+}
+
+func (l *platformLoop) reportP99(b *testing.B) {
+	sort.Slice(l.latencies, func(i, j int) bool { return l.latencies[i] < l.latencies[j] })
+	if len(l.latencies) > 0 {
+		p99 := l.latencies[len(l.latencies)*99/100]
+		b.ReportMetric(float64(p99.Nanoseconds()), "p99-ns")
+	}
+}
+
+// The Send benchmarks measure the throughput of messenger.Send, the caller
+// of the previous design blocks until the platform thread ran the task.
+
+func BenchmarkTaskerSend(b *testing.B) {
+	tk := tasker.New()
+	l := newPlatformLoop(tk.ExecuteTasks)
+	var wg sync.WaitGroup
+	wg.Add(b.N)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			taskerSend(tk, l, l.task(&wg))
+		}
+	})
+	wg.Wait()
+	b.StopTimer()
+	l.close()
+}
+
+func BenchmarkQueueSend(b *testing.B) {
+	var q *Queue
+	l := newPlatformLoop(func() { q.Drain() })
+	q = New(l.postEmptyEvent)
+	var wg sync.WaitGroup
+	wg.Add(b.N)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Post(l.task(&wg))
+		}
+	})
+	wg.Wait()
+	b.StopTimer()
+	l.close()
+}
+
+// The RoundTrip benchmarks wait for each task to run before posting the next
+// one, as SendWithReply does, and report the p99 latency between the post
+// and the run of a task.
+
+func BenchmarkTaskerRoundTrip(b *testing.B) {
+	tk := tasker.New()
+	l := newPlatformLoop(tk.ExecuteTasks)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			taskerSend(tk, l, l.task(&wg))
+			wg.Wait()
+		}
+	})
+	b.StopTimer()
+	l.close()
+	l.reportP99(b)
+}
+
+func BenchmarkQueueRoundTrip(b *testing.B) {
+	var q *Queue
+	l := newPlatformLoop(func() { q.Drain() })
+	q = New(l.postEmptyEvent)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			q.Post(l.task(&wg))
+			wg.Wait()
+		}
+	})
+	b.StopTimer()
+	l.close()
+	l.reportP99(b)
+}
+
+// taskerSend is the previous implementation of messenger.Send.
+func taskerSend(tk *tasker.Tasker, l *platformLoop, task func()) {
+	errc := make(chan error)
+	l.postEmptyEvent()
+	go tk.Do(func() {
+		task()
+		errc <- nil
+	})
+	<-errc
+}
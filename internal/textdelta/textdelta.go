@@ -0,0 +1,129 @@
+// Package textdelta records the changes of the text being edited as the
+// TextEditingDeltas of the Flutter delta model, the offsets of which are in
+// UTF-16 code units.
+package textdelta
+
+// Delta is a change of the editing state, sent to the clients of the delta
+// model with TextInputClient.updateEditingStateWithDeltas. The text of
+// OldText in the UTF-16 range [DeltaStart, DeltaEnd) is replaced by
+// DeltaText:
+//   - an insertion has an empty range,
+//   - a deletion has an empty DeltaText,
+//   - a replacement has both,
+//   - a non-text update, which only changes the selection or the composing
+//     range, has a range of -1.
+type Delta struct {
+	OldText                string `json:"oldText"`
+	DeltaText              string `json:"deltaText"`
+	DeltaStart             int    `json:"deltaStart"`
+	DeltaEnd               int    `json:"deltaEnd"`
+	SelectionBase          int    `json:"selectionBase"`
+	SelectionExtent        int    `json:"selectionExtent"`
+	SelectionAffinity      string `json:"selectionAffinity"`
+	SelectionIsDirectional bool   `json:"selectionIsDirectional"`
+	ComposingBase          int    `json:"composingBase"`
+	ComposingExtent        int    `json:"composingExtent"`
+}
+
+// Selection is the selection and the composing range of the editing state,
+// in UTF-16 code units.
+type Selection struct {
+	Base            int
+	Extent          int
+	Affinity        string
+	IsDirectional   bool
+	ComposingBase   int
+	ComposingExtent int
+}
+
+// Replace returns s with its UTF-16 range [start, end) replaced by text.
+func Replace(s string, start, end int, text string) string {
+	startByte := ByteOffset(s, start)
+	endByte := startByte + ByteOffset(s[startByte:], end-start)
+	return s[:startByte] + text + s[endByte:]
+}
+
+// Batch holds the deltas of the changes made since the last update sent to
+// the framework.
+type Batch struct {
+	deltas []Delta
+}
+
+// Replace returns s with its UTF-16 range [start, end) replaced by text, and
+// records the change. The selection of the delta is a caret after text, until
+// it's set by Flush.
+func (b *Batch) Replace(s string, start, end int, text string) string {
+	caret := start + utf16Len(text)
+	b.deltas = append(b.deltas, Delta{
+		OldText:           s,
+		DeltaText:         text,
+		DeltaStart:        start,
+		DeltaEnd:          end,
+		SelectionBase:     caret,
+		SelectionExtent:   caret,
+		SelectionAffinity: "TextAffinity.downstream",
+		ComposingBase:     -1,
+		ComposingExtent:   -1,
+	})
+	return Replace(s, start, end, text)
+}
+
+// Reset drops the recorded changes.
+func (b *Batch) Reset() {
+	b.deltas = nil
+}
+
+// Flush returns the changes recorded since the last Flush, text being the
+// current text, and resets the Batch. Only the last delta carries the
+// current selection and composing range. Without change, a single non-text
+// update is returned.
+func (b *Batch) Flush(text string, selection Selection) []Delta {
+	deltas := b.deltas
+	b.deltas = nil
+	if len(deltas) == 0 {
+		deltas = append(deltas, Delta{
+			OldText:    text,
+			DeltaStart: -1,
+			DeltaEnd:   -1,
+		})
+	}
+	last := &deltas[len(deltas)-1]
+	last.SelectionBase = selection.Base
+	last.SelectionExtent = selection.Extent
+	last.SelectionAffinity = selection.Affinity
+	last.SelectionIsDirectional = selection.IsDirectional
+	last.ComposingBase = selection.ComposingBase
+	last.ComposingExtent = selection.ComposingExtent
+	return deltas
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r > 0xffff {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// ByteOffset converts an offset in the UTF-16 code units of s to a
+// byte offset, on a code point boundary.
+func ByteOffset(s string, offset int) int {
+	units := 0
+	for i, r := range s {
+		if units >= offset {
+			return i
+		}
+		if r > 0xffff {
+			// a surrogate pair
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return len(s)
+}
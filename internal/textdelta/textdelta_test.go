@@ -0,0 +1,138 @@
+package textdelta
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	type edit struct {
+		start, end int
+		text       string
+	}
+	tests := []struct {
+		name      string
+		text      string
+		edits     []edit
+		selection Selection
+		wantText  string
+		want      []Delta
+	}{
+		{
+			name:      "insertion",
+			text:      "ac",
+			edits:     []edit{{1, 1, "b"}},
+			selection: Selection{Base: 2, Extent: 2, Affinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+			wantText:  "abc",
+			want: []Delta{
+				{OldText: "ac", DeltaText: "b", DeltaStart: 1, DeltaEnd: 1, SelectionBase: 2, SelectionExtent: 2, SelectionAffinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+			},
+		},
+		{
+			name:      "deletion",
+			text:      "abc",
+			edits:     []edit{{1, 2, ""}},
+			selection: Selection{Base: 1, Extent: 1, Affinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+			wantText:  "ac",
+			want: []Delta{
+				{OldText: "abc", DeltaText: "", DeltaStart: 1, DeltaEnd: 2, SelectionBase: 1, SelectionExtent: 1, SelectionAffinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+			},
+		},
+		{
+			name:      "replacement of a selection",
+			text:      "hello world",
+			edits:     []edit{{6, 11, "there"}},
+			selection: Selection{Base: 11, Extent: 11, Affinity: "TextAffinity.upstream", ComposingBase: -1, ComposingExtent: -1},
+			wantText:  "hello there",
+			want: []Delta{
+				{OldText: "hello world", DeltaText: "there", DeltaStart: 6, DeltaEnd: 11, SelectionBase: 11, SelectionExtent: 11, SelectionAffinity: "TextAffinity.upstream", ComposingBase: -1, ComposingExtent: -1},
+			},
+		},
+		{
+			name:      "non-text update",
+			text:      "abc",
+			selection: Selection{Base: 0, Extent: 3, Affinity: "TextAffinity.downstream", IsDirectional: true, ComposingBase: -1, ComposingExtent: -1},
+			wantText:  "abc",
+			want: []Delta{
+				{OldText: "abc", DeltaStart: -1, DeltaEnd: -1, SelectionBase: 0, SelectionExtent: 3, SelectionAffinity: "TextAffinity.downstream", SelectionIsDirectional: true, ComposingBase: -1, ComposingExtent: -1},
+			},
+		},
+		{
+			// the emoji is a surrogate pair, 2 UTF-16 code units.
+			name:      "surrogate pair",
+			text:      "a\U0001F600c",
+			edits:     []edit{{1, 3, "\U0001F601\U0001F602"}},
+			selection: Selection{Base: 5, Extent: 5, Affinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+			wantText:  "a\U0001F601\U0001F602c",
+			want: []Delta{
+				{OldText: "a\U0001F600c", DeltaText: "\U0001F601\U0001F602", DeltaStart: 1, DeltaEnd: 3, SelectionBase: 5, SelectionExtent: 5, SelectionAffinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+			},
+		},
+		{
+			// only the last delta carries the selection and composing range.
+			name:      "several deltas",
+			text:      "ét\U0001F600",
+			edits:     []edit{{2, 4, ""}, {0, 1, "E"}, {2, 2, "é"}},
+			selection: Selection{Base: 1, Extent: 3, Affinity: "TextAffinity.downstream", ComposingBase: 2, ComposingExtent: 3},
+			wantText:  "Eté",
+			want: []Delta{
+				{OldText: "ét\U0001F600", DeltaText: "", DeltaStart: 2, DeltaEnd: 4, SelectionBase: 2, SelectionExtent: 2, SelectionAffinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+				{OldText: "ét", DeltaText: "E", DeltaStart: 0, DeltaEnd: 1, SelectionBase: 1, SelectionExtent: 1, SelectionAffinity: "TextAffinity.downstream", ComposingBase: -1, ComposingExtent: -1},
+				{OldText: "Et", DeltaText: "é", DeltaStart: 2, DeltaEnd: 2, SelectionBase: 1, SelectionExtent: 3, SelectionAffinity: "TextAffinity.downstream", ComposingBase: 2, ComposingExtent: 3},
+			},
+		},
+	}
+	for _, test := range tests {
+		var b Batch
+		text := test.text
+		for _, e := range test.edits {
+			text = b.Replace(text, e.start, e.end, e.text)
+		}
+		if text != test.wantText {
+			t.Errorf("%s: expected the text %q, got %q", test.name, test.wantText, text)
+		}
+		got := b.Flush(text, test.selection)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected the deltas\n%+v\ngot\n%+v", test.name, test.want, got)
+			continue
+		}
+		// each delta applies to the text left by the previous one.
+		previous := test.text
+		for i, d := range got {
+			if d.OldText != previous {
+				t.Errorf("%s: expected the old text of delta %d to be %q, got %q", test.name, i, previous, d.OldText)
+			}
+			if d.DeltaStart >= 0 {
+				previous = Replace(d.OldText, d.DeltaStart, d.DeltaEnd, d.DeltaText)
+			}
+		}
+		if previous != text {
+			t.Errorf("%s: expected the deltas to end with %q, got %q", test.name, text, previous)
+		}
+		// the batch is empty after a flush.
+		if next := b.Flush(text, test.selection); len(next) != 1 || next[0].DeltaStart != -1 {
+			t.Errorf("%s: expected a non-text update after the flush, got %+v", test.name, next)
+		}
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	tests := []struct {
+		s      string
+		offset int
+		want   int
+	}{
+		{"abc", 0, 0},
+		{"abc", 2, 2},
+		{"abc", 5, 3},
+		{"éa", 1, 2},
+		{"\U0001F600a", 2, 4},
+		// an offset inside a surrogate pair ends on the next code point.
+		{"\U0001F600a", 1, 4},
+	}
+	for _, test := range tests {
+		if got := ByteOffset(test.s, test.offset); got != test.want {
+			t.Errorf("ByteOffset(%q, %d): expected %d, got %d", test.s, test.offset, test.want, got)
+		}
+	}
+}
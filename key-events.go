@@ -3,26 +3,72 @@ package flutter
 import (
 	"encoding/json"
 	"fmt"
+	"runtime/debug"
+	"time"
 
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
 	"github.com/go-flutter-desktop/go-flutter/internal/keyboard"
+	"github.com/go-flutter-desktop/go-flutter/internal/keymap"
 	"github.com/go-flutter-desktop/go-flutter/plugin"
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const keyEventChannelName = "flutter/keyevent"
 
-// keyeventPlugin implements flutter.Plugin and handles method calls to
-// flutter/keyevent channel.
-// The sent keyevents are RawKeyEventDataLinux on linux and windows
-// The sent keyevents are RawKeyEventDataMacOs on darwin (needs a conversion layer)
+const keyboardChannelName = "flutter/keyboard"
+
+// keyEventReplyTimeout is how long the framework has to reply to a key event,
+// afterwards the event is considered unhandled.
+const keyEventReplyTimeout = 500 * time.Millisecond
+
+// keyeventPlugin implements flutter.Plugin and sends the key events to the
+// framework. Every GLFW key event is sent as key data, with the
+// FlutterEngineSendKeyEvent embedder API, then as a RawKeyEventDataLinux on
+// the legacy flutter/keyevent channel.
+//
+// The framework replies to the raw event with whether a widget handled the
+// key, in the keyDataThenRawKeyData mode the key data is always reported as
+// unhandled. The framework pairs the key data with the next raw event, both
+// are sent on the main thread, one right after the other. The fallback of
+// unhandled events, e.g., the text input shortcuts, is run once the reply is
+// received, in the order of the events.
+//
+// The pressed keys are recorded per window. They are released when the window
+// loses the focus, GLFW may not report their releases, and the modifier and
+// lock state is synchronized with the modifier bits of the next events.
+//
+// It also handles method calls to the flutter/keyboard channel, which query
+// the pressed keys.
 type keyeventPlugin struct {
-	channel *plugin.BasicMessageChannel
+	engine     *embedder.FlutterEngine
+	mainThread *MainThread
+	messenger  *messenger
+
+	// states record the pressed keys of each window, only accessed on the
+	// main thread.
+	states map[*glfw.Window]*keymap.State
+	// pending holds the events waiting for a reply, in the order they were
+	// sent, only accessed on the main thread.
+	pending *keymap.Pending
 }
 
-var defaultKeyeventsPlugin = &keyeventPlugin{}
+// keyEventReply is the reply of the framework to a raw key event
+type keyEventReply struct {
+	Handled bool `json:"handled"`
+}
+
+var defaultKeyeventsPlugin = &keyeventPlugin{
+	states: make(map[*glfw.Window]*keymap.State),
+}
 
 func (p *keyeventPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
-	p.channel = plugin.NewBasicMessageChannel(messenger, keyEventChannelName, keyEventJSONMessageCodec{})
+	p.pending = keymap.NewPending(keyEventReplyTimeout, func(f func()) {
+		p.mainThread.RunOnMainThreadAsync(f)
+	})
+
+	keyboardChannel := plugin.NewMethodChannel(messenger, keyboardChannelName, plugin.StandardMethodCodec{})
+	keyboardChannel.HandleFuncSync("getKeyboardState", p.handleGetKeyboardState)
 	return nil
 }
 
@@ -33,20 +79,169 @@ func (j keyEventJSONMessageCodec) EncodeMessage(message interface{}) (binaryMess
 	return json.Marshal(message)
 }
 
-// send-only channel
+// DecodeMessage decodes the keyEventReply of the framework.
 func (j keyEventJSONMessageCodec) DecodeMessage(binaryMessage []byte) (message interface{}, err error) {
-	return message, err
+	if len(binaryMessage) == 0 {
+		return nil, nil
+	}
+	var reply keyEventReply
+	err = json.Unmarshal(binaryMessage, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// handleGetKeyboardState replies with the pressed keys, a map of their
+// physical key to their logical key.
+func (p *keyeventPlugin) handleGetKeyboardState(arguments interface{}) (reply interface{}, err error) {
+	state := make(map[interface{}]interface{})
+	for _, windowState := range p.states {
+		for physical, logical := range windowState.Pressed() {
+			state[int64(physical)] = int64(logical)
+		}
+	}
+	return state, nil
 }
 
-func (p *keyeventPlugin) sendKeyEvent(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	event, err := keyboard.Normalize(key, scancode, mods, action)
+// windowState returns the key state of a window.
+func (p *keyeventPlugin) windowState(window *glfw.Window) *keymap.State {
+	state, ok := p.states[window]
+	if !ok {
+		state = keymap.NewState()
+		p.states[window] = state
+	}
+	return state
+}
+
+// glfwFocusCallback releases the pressed keys when the window loses the focus.
+// When it gains the focus, the modifier and lock state is synchronized with
+// the modifier keys known to be held by GLFW, the lock bits are only known
+// from the next key or mouse button event.
+func (p *keyeventPlugin) glfwFocusCallback(window *glfw.Window, focused bool) {
+	if !focused {
+		p.sendKeyData(p.windowState(window).ReleaseAll())
+		return
+	}
+
+	var mods keymap.Modifiers
+	for _, modifier := range []struct {
+		mod  keymap.Modifiers
+		keys [2]glfw.Key
+	}{
+		{keymap.ModShift, [2]glfw.Key{glfw.KeyLeftShift, glfw.KeyRightShift}},
+		{keymap.ModControl, [2]glfw.Key{glfw.KeyLeftControl, glfw.KeyRightControl}},
+		{keymap.ModAlt, [2]glfw.Key{glfw.KeyLeftAlt, glfw.KeyRightAlt}},
+		{keymap.ModSuper, [2]glfw.Key{glfw.KeyLeftSuper, glfw.KeyRightSuper}},
+	} {
+		if window.GetKey(modifier.keys[0]) == glfw.Press || window.GetKey(modifier.keys[1]) == glfw.Press {
+			mods |= modifier.mod
+		}
+	}
+	state := p.windowState(window)
+	// keep the lock modes, they aren't known yet.
+	mods |= state.Locks()
+	p.sendKeyData(state.Sync(mods))
+}
+
+// syncModifiers synchronizes the modifier and lock state with the modifiers
+// of a GLFW event which isn't a key event.
+func (p *keyeventPlugin) syncModifiers(window *glfw.Window, mods glfw.ModifierKey) {
+	p.sendKeyData(p.windowState(window).Sync(keymap.Modifiers(mods)))
+}
+
+// sendKeyEvent sends a GLFW key event to the framework. fallback is called on
+// the main thread if no widget handled the key, it may be nil.
+func (p *keyeventPlugin) sendKeyEvent(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey, fallback func()) {
+	var eventType keymap.EventType
+	switch action {
+	case glfw.Press:
+		eventType = keymap.EventDown
+	case glfw.Repeat:
+		eventType = keymap.EventRepeat
+	case glfw.Release:
+		eventType = keymap.EventUp
+	default:
+		fmt.Printf("go-flutter: unknown key event type: %v\n", action)
+		return
+	}
+
+	name := keyName(key, scancode)
+	events := p.windowState(window).Handle(
+		eventType,
+		keymap.Physical(int(key), scancode),
+		keymap.Logical(int(key), name),
+		keymap.Character(int(key), name, mods&glfw.ModShift != 0, mods&glfw.ModCapsLock != 0),
+		keymap.Modifiers(mods),
+	)
+	p.sendKeyData(events)
+
+	pending := p.pending.Add(fallback)
+	rawEvent, err := keyboard.Normalize(key, scancode, mods, action)
 	if err != nil {
 		fmt.Printf("go-flutter: failed to Normalize key event: %v", err)
+		pending.Reply(false)
 		return
 	}
+	p.sendRawKeyEvent(rawEvent, pending)
+}
 
-	err = p.channel.Send(event)
+// sendRawKeyEvent sends a raw key event on the flutter/keyevent channel, and
+// replies to the pending event with whether a widget handled it. It must be
+// called on the main thread, right after the key data of the event.
+func (p *keyeventPlugin) sendRawKeyEvent(rawEvent interface{}, pending *keymap.PendingEvent) {
+	codec := keyEventJSONMessageCodec{}
+	binaryMessage, err := codec.EncodeMessage(rawEvent)
 	if err != nil {
-		fmt.Printf("go-flutter: Failed to send raw_keyboard event %v: %v\n", event, err)
+		fmt.Printf("go-flutter: failed to encode raw_keyboard event %v: %v\n", rawEvent, err)
+		pending.Reply(false)
+		return
+	}
+	err = p.messenger.SendWithReplyHandler(keyEventChannelName, binaryMessage, func(binaryReply []byte) {
+		reply, err := codec.DecodeMessage(binaryReply)
+		if err != nil {
+			fmt.Printf("go-flutter: failed to decode the reply to raw_keyboard event %v: %v\n", rawEvent, err)
+		}
+		handled := false
+		if r, ok := reply.(keyEventReply); ok {
+			handled = r.Handled
+		}
+		pending.Reply(handled)
+	})
+	if err != nil {
+		fmt.Printf("go-flutter: Failed to send raw_keyboard event %v: %v\n", rawEvent, err)
+		pending.Reply(false)
+	}
+}
+
+// sendKeyData sends key events with the embedder API.
+func (p *keyeventPlugin) sendKeyData(events []keymap.Event) {
+	for _, event := range events {
+		err := p.engine.SendKeyEvent(embedder.KeyEvent{
+			Type:        embedder.KeyEventType(event.Type),
+			Physical:    event.Physical,
+			Logical:     event.Logical,
+			Character:   event.Character,
+			Synthesized: event.Synthesized,
+		}, nil)
+		if err != nil {
+			fmt.Printf("go-flutter: Failed to send key event %v: %v\n", event, err)
+		}
 	}
 }
+
+// keyName returns the character produced by the key in the current layout,
+// empty for the keys which don't produce a character.
+func keyName(key glfw.Key, scancode int) (name string) {
+	defer func() {
+		p := recover()
+		if p != nil {
+			fmt.Printf("go-flutter: recovered from panic while getting the key name: %v\n", p)
+			debug.PrintStack()
+			name = ""
+		}
+	}()
+
+	// This function call can fail with panic()
+	return glfw.GetKeyName(key, scancode)
+}
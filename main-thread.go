@@ -0,0 +1,48 @@
+package flutter
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/internal/currentthread"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+)
+
+// MainThread runs functions on the platform thread, the thread running the
+// GLFW event loop and the Flutter platform task runner. GLFW window calls and
+// most engine calls must be made on that thread.
+//
+// Plugins receive the MainThread by implementing PluginMainThread.
+type MainThread struct {
+	tasks    *taskqueue.Queue
+	threadID currentthread.ThreadID
+}
+
+// newMainThread must be called on the main thread.
+func newMainThread(tasks *taskqueue.Queue) *MainThread {
+	return &MainThread{
+		tasks:    tasks,
+		threadID: currentthread.ID(),
+	}
+}
+
+// IsMainThread reports whether the caller is running on the main thread.
+func (m *MainThread) IsMainThread() bool {
+	return currentthread.Equal(currentthread.ID(), m.threadID)
+}
+
+// RunOnMainThread runs f on the main thread and waits for it to return. When
+// called from the main thread, f is run directly.
+//
+// RunOnMainThread never returns when called once the application has stopped.
+func (m *MainThread) RunOnMainThread(f func()) {
+	if m.IsMainThread() {
+		f()
+		return
+	}
+	m.tasks.Do(f)
+}
+
+// RunOnMainThreadAsync schedules f to be run on the main thread and returns
+// immediately. Functions are run in the order they were scheduled, even when
+// called from the main thread.
+func (m *MainThread) RunOnMainThreadAsync(f func()) {
+	m.tasks.Post(f)
+}
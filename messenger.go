@@ -4,29 +4,56 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/go-flutter-desktop/go-flutter/embedder"
-	"github.com/go-flutter-desktop/go-flutter/internal/tasker"
+	"github.com/go-flutter-desktop/go-flutter/internal/reply"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
 	"github.com/go-flutter-desktop/go-flutter/plugin"
 )
 
+// missingReplyTimeout is how long a handler has to reply to a message before
+// the missing reply is reported.
+const missingReplyTimeout = 10 * time.Second
+
 type messenger struct {
+	// lastMessageID is accessed atomically, it is the first field to be 64-bit
+	// aligned on 32-bit platforms.
+	lastMessageID uint64
+
 	engine *embedder.FlutterEngine
 
 	channels     map[string]plugin.ChannelHandlerFunc
 	channelsLock sync.RWMutex
 
-	// engineTasker holds tasks which must be executed in the engine thread
-	engineTasker *tasker.Tasker
+	// platformTasks holds tasks which must be executed in the engine thread
+	platformTasks *taskqueue.Queue
+
+	// interceptors are called for every message and reply, they are set
+	// before the engine is started.
+	interceptors plugin.InterceptorChain
+	// fallbackHandler handles the messages sent on channels without handler.
+	fallbackHandler plugin.ChannelHandlerFunc
+	// replies tracks the messages waiting for a reply.
+	replies *reply.Tracker
+	// replyCallbacks keeps the callbacks of SendWithReplyHandler alive until
+	// they are called, only accessed on the platform thread.
+	replyCallbacks map[*embedder.DataCallback]struct{}
 }
 
 var _ plugin.BinaryMessenger = &messenger{}
 
-func newMessenger(engine *embedder.FlutterEngine) *messenger {
+func newMessenger(engine *embedder.FlutterEngine, platformTasks *taskqueue.Queue) *messenger {
 	return &messenger{
-		engine:       engine,
-		channels:     make(map[string]plugin.ChannelHandlerFunc),
-		engineTasker: tasker.New(),
+		engine:        engine,
+		channels:      make(map[string]plugin.ChannelHandlerFunc),
+		platformTasks: platformTasks,
+		replies:       reply.NewTracker(missingReplyTimeout),
+
+		replyCallbacks: make(map[*embedder.DataCallback]struct{}),
 	}
 }
 
@@ -35,6 +62,14 @@ func newMessenger(engine *embedder.FlutterEngine) *messenger {
 // NOTE: If no value are returned by the flutter handler, the function will
 // wait forever. In case you don't want to wait for reply, use Send.
 func (m *messenger) SendWithReply(channel string, binaryMessage []byte) (binaryReply []byte, err error) {
+	intercepted, err := m.intercept(plugin.OutgoingMessage, channel, binaryMessage)
+	if err != nil {
+		return nil, err
+	}
+	if intercepted != nil {
+		channel, binaryMessage = intercepted.Channel, intercepted.Data
+	}
+
 	reply := make(chan []byte)
 	defer close(reply)
 	callbackHandle := &embedder.DataCallback{
@@ -58,44 +93,101 @@ func (m *messenger) SendWithReply(channel string, binaryMessage []byte) (binaryR
 	if m.engine.TaskRunnerRunOnCurrentThread() {
 		err = m.engine.SendPlatformMessage(msg)
 	} else {
-		replyErr := make(chan error)
-		defer close(replyErr)
-
-		postEmptyEvent()
-		go m.engineTasker.Do(func() {
+		replyErr := make(chan error, 1)
+		m.platformTasks.Post(func() {
 			replyErr <- m.engine.SendPlatformMessage(msg)
 		})
 		err = <-replyErr
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	// wait for a reply and return
-	return <-reply, nil
+	binaryReply = <-reply
+	if intercepted != nil {
+		binaryReply = m.interceptors.InterceptReply(intercepted, binaryReply)
+	}
+	return binaryReply, nil
+}
+
+// SendWithReplyHandler pushes a binary message on a channel to the Flutter
+// side, without waiting for the reply: handler is called with it on the
+// platform thread. Unlike SendWithReply, it must be called on the platform
+// thread, the messages are then passed to the engine in the order they are
+// sent.
+func (m *messenger) SendWithReplyHandler(channel string, binaryMessage []byte, handler func(binaryReply []byte)) error {
+	if !m.engine.TaskRunnerRunOnCurrentThread() {
+		return errors.New("SendWithReplyHandler must be called on the platform thread")
+	}
+	intercepted, err := m.intercept(plugin.OutgoingMessage, channel, binaryMessage)
+	if err != nil {
+		return err
+	}
+	if intercepted != nil {
+		channel, binaryMessage = intercepted.Channel, intercepted.Data
+	}
+
+	var responseHandle embedder.PlatformMessageResponseHandle
+	callbackHandle := &embedder.DataCallback{}
+	callbackHandle.Handle = func(binaryReply []byte) {
+		delete(m.replyCallbacks, callbackHandle)
+		m.engine.ReleasePlatformMessageResponseHandle(responseHandle)
+		if intercepted != nil {
+			binaryReply = m.interceptors.InterceptReply(intercepted, binaryReply)
+		}
+		handler(binaryReply)
+	}
+	responseHandle, err = m.engine.CreatePlatformMessageResponseHandle(callbackHandle)
+	if err != nil {
+		return err
+	}
+	m.replyCallbacks[callbackHandle] = struct{}{}
+
+	err = m.engine.SendPlatformMessage(&embedder.PlatformMessage{
+		Channel:        channel,
+		Message:        binaryMessage,
+		ResponseHandle: responseHandle,
+	})
+	if err != nil {
+		delete(m.replyCallbacks, callbackHandle)
+		m.engine.ReleasePlatformMessageResponseHandle(responseHandle)
+		return err
+	}
+	return nil
 }
 
 // Send pushes a binary message on a channel to the Flutter side without
 // expecting replies.
+// When called outside of the platform thread, Send doesn't wait for the
+// message to be passed to the engine, it sends a copy of binaryMessage.
+// Messages are sent in order, and failures are logged.
 func (m *messenger) Send(channel string, binaryMessage []byte) (err error) {
+	intercepted, err := m.intercept(plugin.OutgoingMessage, channel, binaryMessage)
+	if err != nil {
+		return err
+	}
+	if intercepted != nil {
+		channel, binaryMessage = intercepted.Channel, intercepted.Data
+	}
+
 	msg := &embedder.PlatformMessage{
 		Channel: channel,
 		Message: binaryMessage,
 	}
 
 	if m.engine.TaskRunnerRunOnCurrentThread() {
-		err = m.engine.SendPlatformMessage(msg)
-	} else {
-		replyErr := make(chan error)
-		defer close(replyErr)
-
-		postEmptyEvent()
-		go m.engineTasker.Do(func() {
-			replyErr <- m.engine.SendPlatformMessage(msg)
-		})
-		err = <-replyErr
-	}
-	if err != nil {
-		return err
+		return m.engine.SendPlatformMessage(msg)
 	}
 
+	// the caller may reuse its buffer once Send returns
+	msg.Message = append([]byte(nil), binaryMessage...)
+	m.platformTasks.Post(func() {
+		err := m.engine.SendPlatformMessage(msg)
+		if err != nil {
+			fmt.Printf("go-flutter: failed to send message on channel '%s': %v\n", channel, err)
+		}
+	})
 	return nil
 }
 
@@ -110,47 +202,68 @@ func (m *messenger) SetChannelHandler(channel string, channelHandler plugin.Chan
 	m.channelsLock.Unlock()
 }
 
-func (m *messenger) handlePlatformMessage(message *embedder.PlatformMessage) {
-	m.channelsLock.RLock()
-	channelHander := m.channels[message.Channel]
-	m.channelsLock.RUnlock()
-
-	if channelHander == nil {
-		// print a log, but continue on to send a nil reply when required
-		fmt.Println("go-flutter: no handler found for channel " + message.Channel)
-		return
+// intercept passes a message through the interceptors. It returns a nil
+// Message when there are no interceptors.
+func (m *messenger) intercept(direction plugin.MessageDirection, channel string, binaryMessage []byte) (*plugin.Message, error) {
+	if len(m.interceptors) == 0 {
+		return nil, nil
 	}
-
-	var err error
-	err = channelHander(message.Message, responseSender{
-		engine:       m.engine,
-		message:      message,
-		engineTasker: m.engineTasker,
-	})
+	message := &plugin.Message{
+		ID:        atomic.AddUint64(&m.lastMessageID, 1),
+		Direction: direction,
+		Channel:   channel,
+		Data:      binaryMessage,
+		Time:      time.Now(),
+	}
+	err := m.interceptors.InterceptMessage(message)
 	if err != nil {
-		fmt.Printf("go-flutter: handling message on channel "+message.Channel+" failed: %v\n", err)
+		return nil, err
 	}
+	return message, nil
 }
 
-type responseSender struct {
-	engine       *embedder.FlutterEngine
-	message      *embedder.PlatformMessage
-	engineTasker *tasker.Tasker
-}
-
-func (r responseSender) Send(binaryReply []byte) {
-	if !r.message.ExpectsResponse() {
-		return // quick path when no response should be sent
+func (m *messenger) handlePlatformMessage(message *embedder.PlatformMessage) {
+	channel, binaryMessage := message.Channel, message.Message
+	intercepted, err := m.intercept(plugin.IncomingMessage, channel, binaryMessage)
+	r := m.newResponseSender(message, intercepted)
+	if err != nil {
+		fmt.Printf("go-flutter: rejected message on channel %s: %v\n", channel, err)
+		r.Send(nil)
+		return
+	}
+	if intercepted != nil {
+		channel, binaryMessage = intercepted.Channel, intercepted.Data
 	}
 
-	// TODO: detect multiple responses on the same message and spam the log
-	// about it.
+	m.channelsLock.RLock()
+	channelHander := m.channels[channel]
+	m.channelsLock.RUnlock()
 
-	postEmptyEvent()
-	go r.engineTasker.Do(func() {
-		err := r.engine.SendPlatformMessageResponse(r.message.ResponseHandle, binaryReply)
-		if err != nil {
-			fmt.Printf("go-flutter: failed sending response for message on channel '%s': %v", r.message.Channel, err)
+	reply.Handle(channel, binaryMessage, channelHander, m.fallbackHandler, r)
+}
+
+// newResponseSender creates the ResponseSender of a platform message. The
+// reply is passed through the interceptors, and sent on the platform thread.
+func (m *messenger) newResponseSender(message *embedder.PlatformMessage, intercepted *plugin.Message) *reply.Sender {
+	if !message.ExpectsResponse() {
+		return m.replies.NewSender(message.Channel, nil)
+	}
+	return m.replies.NewSender(message.Channel, func(binaryReply []byte) {
+		if intercepted != nil {
+			binaryReply = m.interceptors.InterceptReply(intercepted, binaryReply)
 		}
+		m.platformTasks.Post(func() {
+			err := m.engine.SendPlatformMessageResponse(message.ResponseHandle, binaryReply)
+			if err != nil {
+				fmt.Printf("go-flutter: failed sending response for message on channel '%s': %v\n", message.Channel, err)
+			}
+		})
 	})
 }
+
+// close replies to the messages still waiting for a reply, before the engine
+// shuts down. It must be called on the platform thread.
+func (m *messenger) close() {
+	m.replies.Close()
+	m.platformTasks.Drain()
+}
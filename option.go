@@ -5,8 +5,13 @@ import (
 	"image"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/go-flutter-desktop/go-flutter/internal/execpath"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/clipboard"
+	"github.com/go-flutter-desktop/go-flutter/plugin/ime"
+	"github.com/go-flutter-desktop/go-flutter/plugin/recording"
 )
 
 type config struct {
@@ -23,12 +28,45 @@ type config struct {
 	windowAlwaysOnTop       bool
 	windowTransparent       bool
 
+	renderThread bool
+	idlePolicy   idlePolicy
+
+	clipboard              clipboard.Clipboard
+	clipboardWatchInterval time.Duration
+
+	dropFilters []DropFilterFunc
+
+	inputMethod         ime.Backend
+	inputMethodDisabled bool
+
 	backOnEscape bool
 
 	forcePixelRatio float64
 	scrollAmount    float64
 
 	plugins []Plugin
+
+	messageInterceptors plugin.InterceptorChain
+	fallbackHandler     plugin.ChannelHandlerFunc
+	// messageRecordings are the files of RecordMessages, closed when Run
+	// returns.
+	messageRecordings []messageRecording
+}
+
+// messageRecording is a file recording the platform messages.
+type messageRecording struct {
+	file   *os.File
+	writer *recording.Writer
+}
+
+// close closes the recording file, reporting the write errors.
+func (r messageRecording) close() {
+	if err := r.writer.Err(); err != nil {
+		fmt.Printf("go-flutter: failed to record messages to %s: %v\n", r.file.Name(), err)
+	}
+	if err := r.file.Close(); err != nil {
+		fmt.Printf("go-flutter: failed to close message recording file: %v\n", err)
+	}
 }
 
 type windowDimensions struct {
@@ -66,6 +104,8 @@ func newApplicationConfig() config {
 		windowTransparent: false,
 		scrollAmount:      100.0,
 
+		clipboardWatchInterval: defaultClipboardWatchInterval,
+
 		backOnEscape: true,
 
 		// Sane configuration values for the engine.
@@ -230,6 +270,18 @@ func WindowAlwaysOnTop(enabled bool) Option {
 	}
 }
 
+// RenderThread runs the render (raster) tasks of the engine on a dedicated
+// thread, instead of the main thread. Busy rasterization then no longer
+// delays input processing and platform messages.
+//
+// When enabled, the OpenGL context of the window is owned by the render
+// thread, plugins using PluginGLFW must not make it current.
+func RenderThread(enabled bool) Option {
+	return func(c *config) {
+		c.renderThread = enabled
+	}
+}
+
 // AddPlugin adds a plugin to the flutter application.
 func AddPlugin(p Plugin) Option {
 	return func(c *config) {
@@ -237,6 +289,54 @@ func AddPlugin(p Plugin) Option {
 	}
 }
 
+// AddMessageInterceptor adds an interceptor to the messenger. Interceptors
+// are called for every platform message, in both directions, and for their
+// replies. They are called in the order they were added.
+func AddMessageInterceptor(interceptor plugin.MessageInterceptor) Option {
+	return func(c *config) {
+		c.messageInterceptors = append(c.messageInterceptors, interceptor)
+	}
+}
+
+// ChannelAllowlist restricts the platform messages to the given channels,
+// messages on other channels are rejected. The "flutter/*" channels, used by
+// the framework, and the "go-flutter/*" channels, used by the built-in
+// plugins, are always allowed.
+//
+// See plugin.NewChannelAllowlist for the syntax of channel names.
+func ChannelAllowlist(channels ...string) Option {
+	allowed := append([]string{"flutter/*", "go-flutter/*"}, channels...)
+	return AddMessageInterceptor(plugin.NewChannelAllowlist(allowed...))
+}
+
+// FallbackChannelHandler sets the handler of the messages sent on channels
+// which have no handler. By default, these messages are answered with a nil
+// reply, which triggers the dart MissingPluginException.
+func FallbackChannelHandler(handler plugin.ChannelHandlerFunc) Option {
+	return func(c *config) {
+		c.fallbackHandler = handler
+	}
+}
+
+// RecordMessages records the platform messages, and their replies, to the
+// file at path. The recording can be read with the plugin/recording package,
+// for example to replay it against a plugin.
+//
+// The recorder is a message interceptor, messages rejected by interceptors
+// added before it are not recorded. The file is closed when Run returns.
+func RecordMessages(path string) Option {
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("go-flutter: failed to create message recording file: %v\n", err)
+		os.Exit(1)
+	}
+	writer := recording.NewWriter(f)
+	return func(c *config) {
+		c.messageInterceptors = append(c.messageInterceptors, writer)
+		c.messageRecordings = append(c.messageRecordings, messageRecording{file: f, writer: writer})
+	}
+}
+
 // VirtualKeyboardShow sets an func called when the flutter framework want to
 // show the keyboard.
 // This Option is interesting for people wanting to display the on-screen
@@ -0,0 +1,35 @@
+package flutter
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/internal/pixelplane"
+)
+
+// PixelFormat is the layout of the pixels of a PixelBuffer.
+type PixelFormat int
+
+const (
+	// PixelFormatRGBA is the default format, pixels are stored in R, G, B, A
+	// order, 8 bits per component.
+	PixelFormatRGBA PixelFormat = iota
+	// PixelFormatBGRA stores pixels in B, G, R, A order, 8 bits per component.
+	PixelFormatBGRA
+	// PixelFormatNV12 is a planar YUV 4:2:0 format. Pix holds the Y plane,
+	// followed by the interleaved U and V plane, subsampled by 2 in both
+	// directions. The rows of both planes are Stride bytes apart.
+	PixelFormatNV12
+	// PixelFormatI420 is a planar YUV 4:2:0 format. Pix holds the Y plane,
+	// followed by the U plane and the V plane, subsampled by 2 in both
+	// directions. The rows of the U and V planes are (Stride+1)/2 bytes apart.
+	PixelFormatI420
+)
+
+// String returns the name of the format.
+func (f PixelFormat) String() string {
+	return pixelplane.Format(f).String()
+}
+
+// planes returns the layout of the planes of the buffer, and checks that Pix
+// holds them.
+func (p *PixelBuffer) planes() ([]pixelplane.Plane, error) {
+	return pixelplane.Layout(pixelplane.Format(p.Format), p.Width, p.Height, p.Stride, len(p.Pix))
+}
@@ -1,12 +1,16 @@
 package flutter
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/pkg/errors"
 
 	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/clipboard"
 )
 
 // platformPlugin implements flutter.Plugin and handles method calls to the
@@ -16,6 +20,7 @@ type platformPlugin struct {
 
 	messenger plugin.BinaryMessenger
 	window    *glfw.Window
+	clipboard clipboard.Clipboard
 
 	// flutterInitialized is used as callbacks to know when the flutter framework
 	// is running and ready to process upstream plugin calls.
@@ -36,9 +41,12 @@ func (p *platformPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
 	p.messenger = messenger
 	channel := plugin.NewMethodChannel(p.messenger, "flutter/platform", plugin.JSONMethodCodec{})
 
-	channel.HandleFuncSync("Clipboard.setData", p.handleClipboardSetData)
-	channel.HandleFuncSync("Clipboard.getData", p.handleClipboardGetData)
-	channel.HandleFuncSync("Clipboard.hasStrings", p.handleClipboardHasString)
+	// The clipboard backend may run a process for each call, the clipboard
+	// calls are handled off the platform thread, in order.
+	channel.SetDispatcher(plugin.NewSerialDispatcher())
+	channel.HandleFunc("Clipboard.setData", p.handleClipboardSetData)
+	channel.HandleFunc("Clipboard.getData", p.handleClipboardGetData)
+	channel.HandleFunc("Clipboard.hasStrings", p.handleClipboardHasString)
 
 	channel.HandleFuncSync("SystemNavigator.pop", p.handleSystemNavigatorPop)
 	channel.HandleFunc("SystemChrome.setApplicationSwitcherDescription", p.handleWindowSetTitle)
@@ -58,47 +66,100 @@ func (p *platformPlugin) InitPluginGLFW(window *glfw.Window) (err error) {
 	return nil
 }
 
+// handleClipboardSetData writes the text of the arguments. Rich content may
+// be given in mimeData, keyed by MIME type, with the non-text types base64
+// encoded, e.g.: {"text": "a", "mimeData": {"text/html": "<b>a</b>"}}.
+//
+// The default clipboards keep a single representation, the other types are
+// dropped: the Command clipboard prefers image/png, then text/plain, so the
+// HTML of the example isn't published; the GLFW clipboard keeps the text. The
+// dropped types are reported, the call still succeeds.
 func (p *platformPlugin) handleClipboardSetData(arguments interface{}) (reply interface{}, err error) {
 	newClipboard := struct {
-		Text string `json:"text"`
+		Text     *string           `json:"text"`
+		MIMEData map[string]string `json:"mimeData"`
 	}{}
 	err = json.Unmarshal(arguments.(json.RawMessage), &newClipboard)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode json arguments for handleClipboardSetData")
 	}
-	p.window.SetClipboardString(newClipboard.Text)
+
+	content := make(map[string][]byte, len(newClipboard.MIMEData)+1)
+	for mimeType, data := range newClipboard.MIMEData {
+		if strings.HasPrefix(mimeType, "text/") {
+			content[mimeType] = []byte(data)
+			continue
+		}
+		content[mimeType], err = base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode the "+mimeType+" clipboard data")
+		}
+	}
+	if newClipboard.Text != nil {
+		content[clipboard.MIMEText] = []byte(*newClipboard.Text)
+	}
+	err = p.clipboard.Write(content)
+	if dropped, ok := err.(*clipboard.DroppedError); ok {
+		fmt.Printf("go-flutter: %v\n", dropped)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write the clipboard")
+	}
 	return nil, nil
 }
 
+// handleClipboardGetData replies with the content of the clipboard in the
+// requested MIME type: text types in text, the others base64 encoded in data.
+// The reply is null when the content isn't available in that type.
 func (p *platformPlugin) handleClipboardGetData(arguments interface{}) (reply interface{}, err error) {
 	requestedMime := ""
 	err = json.Unmarshal(arguments.(json.RawMessage), &requestedMime)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode json arguments for handleClipboardGetData")
 	}
-	if requestedMime != "text/plain" {
-		return nil, errors.New("obtaining mime type " + requestedMime + " from clipboard is not yet supported in go-flutter")
-	}
 
-	var clipText string
-	clipText = p.window.GetClipboardString()
+	data, err := p.clipboard.Read(requestedMime)
+	if err == clipboard.ErrUnavailable {
+		if requestedMime == clipboard.MIMEText {
+			// keep replying an empty text, as when the clipboard was only
+			// read through GLFW.
+			return struct {
+				Text string `json:"text"`
+			}{}, nil
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the clipboard")
+	}
 
+	if strings.HasPrefix(requestedMime, "text/") {
+		reply = struct {
+			Text string `json:"text"`
+		}{
+			Text: string(data),
+		}
+		return reply, nil
+	}
 	reply = struct {
-		Text string `json:"text"`
+		Data []byte `json:"data"`
 	}{
-		Text: clipText,
+		Data: data,
 	}
 	return reply, nil
 }
 
 func (p *platformPlugin) handleClipboardHasString(arguments interface{}) (reply interface{}, err error) {
-	var clipText string
-	clipText = p.window.GetClipboardString()
+	hasText, err := clipboard.HasType(p.clipboard, clipboard.MIMEText)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the clipboard types")
+	}
 
 	reply = struct {
 		Value bool `json:"value"`
 	}{
-		Value: len(clipText) > 0,
+		Value: hasText,
 	}
 	return reply, nil
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/go-gl/glfw/v3.3/glfw"
 
 	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/clipboard"
+	"github.com/go-flutter-desktop/go-flutter/plugin/eventsource"
 )
 
 // TODO: move type Plugin into package plugin?
@@ -53,3 +55,57 @@ type PluginTexture interface {
 	// returned it is printend the application is stopped.
 	InitPluginTexture(registry *TextureRegistry) error
 }
+
+// PluginMainThread defines the interface for plugins that need to run code on
+// the main thread, such as GLFW or engine calls made from a channel handler or
+// a goroutine. Plugins may implement this interface to receive access to the
+// MainThread. Note that plugins must still implement the Plugin interface. The
+// call to InitPluginMainThread is made after the call to PluginTexture.
+type PluginMainThread interface {
+	// Any type inmplementing PluginMainThread must also implement Plugin.
+	Plugin
+	// InitPluginMainThread is called after the call to InitPlugin. When an
+	// error is returned it is printend the application is stopped.
+	InitPluginMainThread(mainThread *MainThread) error
+}
+
+// PluginEventLoop defines the interface for plugins that need timers or need
+// to watch file descriptors (sockets, inotify, D-Bus). Plugins may implement
+// this interface to receive access to the event sources of the platform event
+// loop, whose callbacks are run on the main thread. Note that plugins must
+// still implement the Plugin interface. The call to InitPluginEventLoop is
+// made after the call to PluginMainThread.
+type PluginEventLoop interface {
+	// Any type inmplementing PluginEventLoop must also implement Plugin.
+	Plugin
+	// InitPluginEventLoop is called after the call to InitPlugin. When an
+	// error is returned it is printend the application is stopped.
+	InitPluginEventLoop(sources *eventsource.Sources) error
+}
+
+// PluginClipboard defines the interface for plugins that need to read or
+// write the clipboard, e.g., to copy images or paste files. Plugins may
+// implement this interface to receive access to the clipboard backend of the
+// application. Note that plugins must still implement the Plugin interface.
+// The call to InitPluginClipboard is made after the call to PluginEventLoop.
+type PluginClipboard interface {
+	// Any type inmplementing PluginClipboard must also implement Plugin.
+	Plugin
+	// InitPluginClipboard is called after the call to InitPlugin. When an
+	// error is returned it is printend the application is stopped.
+	InitPluginClipboard(c clipboard.Clipboard) error
+}
+
+// PluginClipboardWatcher defines the interface for plugins that need to know
+// when the clipboard changes. Plugins may implement this interface to receive
+// the clipboard Watcher of the application, polled while it has subscribers,
+// and whose subscribers are called on the main thread. Note that plugins must
+// still implement the Plugin interface. The call to
+// InitPluginClipboardWatcher is made after the call to PluginClipboard.
+type PluginClipboardWatcher interface {
+	// Any type inmplementing PluginClipboardWatcher must also implement Plugin.
+	Plugin
+	// InitPluginClipboardWatcher is called after the call to InitPlugin. When
+	// an error is returned it is printend the application is stopped.
+	InitPluginClipboardWatcher(watcher *clipboard.Watcher) error
+}
@@ -1,6 +1,10 @@
 package plugin
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 // BasicMessageHandler defines the interfece for a basic message handler.
 type BasicMessageHandler interface {
@@ -37,6 +41,7 @@ type BasicMessageChannel struct {
 	channelName string
 	codec       MessageCodec
 	handler     BasicMessageHandler
+	dispatcher  Dispatcher
 }
 
 // NewBasicMessageChannel creates a BasicMessageChannel.
@@ -113,22 +118,48 @@ func (b *BasicMessageChannel) HandleFunc(f func(message interface{}) (reply inte
 	b.Handle(BasicMessageHandlerFunc(f))
 }
 
+// SetDispatcher sets the Dispatcher running the handler. By default the
+// handler is run on the platform thread.
+func (b *BasicMessageChannel) SetDispatcher(dispatcher Dispatcher) {
+	b.dispatcher = dispatcher
+}
+
 // handleChannelMessage decodes an incoming binary envelopes, calls the bassic
 // message handler, and encodes the outgoing reply into an envelope.
 func (b *BasicMessageChannel) handleChannelMessage(binaryMessage []byte, r ResponseSender) (err error) {
 	if b.handler == nil {
+		r.Send(nil)
 		return nil
 	}
 	message, err := b.codec.DecodeMessage(binaryMessage)
 	if err != nil {
+		r.Send(nil)
 		return errors.Wrap(err, "failed to decode incoming message")
 	}
-	reply, err := b.handler.HandleMessage(message)
+	if b.dispatcher == nil {
+		return b.handleMessage(b.handler, message, r)
+	}
+	handler := b.handler
+	b.dispatcher.Dispatch(message, func() {
+		err := b.handleMessage(handler, message, r)
+		if err != nil {
+			fmt.Printf("go-flutter: handling message on channel %s failed: %v\n", b.channelName, err)
+		}
+	})
+	return nil
+}
+
+// handleMessage calls the handler and sends the encoded reply, or a nil reply
+// when the handler fails.
+func (b *BasicMessageChannel) handleMessage(handler BasicMessageHandler, message interface{}, r ResponseSender) error {
+	reply, err := handler.HandleMessage(message)
 	if err != nil {
+		r.Send(nil)
 		return errors.Wrap(err, "handler for incoming basic message failed")
 	}
 	binaryReply, err := b.codec.EncodeMessage(reply)
 	if err != nil {
+		r.Send(nil)
 		return errors.Wrap(err, "failed to encode outgoing reply")
 	}
 	r.Send(binaryReply)
@@ -175,3 +175,17 @@ func TestBasicMethodChannelEncodeFail(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "failed to encode outgoing message: invalid type provided to message codec: expected message to be of type string", err.Error())
 }
+
+// TestBasicMethodChannelDispatchedError tests that a dispatched handler
+// failing is answered with a nil reply.
+func TestBasicMethodChannelDispatchedError(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewBasicMessageChannel(messenger, "ch", StringCodec{})
+	channel.SetDispatcher(NewSerialDispatcher())
+	channel.HandleFunc(func(message interface{}) (reply interface{}, err error) {
+		return nil, errors.New("failed")
+	})
+	reply, err := messenger.MockSendAndWait("ch", []byte("hello"))
+	assert.NoError(t, err)
+	assert.Nil(t, reply)
+}
@@ -7,6 +7,11 @@ type BinaryMessenger interface {
 
 	// Send sends a binary message to the Flutter application without
 	// expecting a reply.
+	//
+	// Send may return before the message is passed to the engine, the
+	// messenger of go-flutter does so outside of the platform thread. The
+	// messages are then sent in order, and the errors are only logged. The
+	// caller may reuse binaryMessage once Send returns.
 	Send(channel string, binaryMessage []byte) (err error)
 
 	// SetChannelHandler registers a handler to be invoked when the Flutter
@@ -26,5 +31,9 @@ type ResponseSender interface {
 }
 
 // ChannelHandlerFunc describes the function that handles binary messages sent
-// on a channel. For each message, ResponseSender.Send must be called once.
+// on a channel. For each message, ResponseSender.Send must be called once,
+// the handler may reply after it returns, e.g. from another goroutine.
+//
+// The messenger of go-flutter reports the messages still waiting for a reply
+// after a while, and replies nil to them when the engine shuts down.
 type ChannelHandlerFunc func(binaryMessage []byte, r ResponseSender) (err error)
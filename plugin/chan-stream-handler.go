@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ChanStreamHandler is a StreamHandlerWithError that forwards the values
+// received on a Go channel to the event stream.
+//
+// Values implementing the error interface are sent as error events, using the
+// code of an *Error or "error" otherwise. When the Go channel is closed, the
+// end of the stream is sent to Flutter.
+type ChanStreamHandler struct {
+	open func(arguments interface{}, done <-chan struct{}) (interface{}, error)
+
+	lock sync.Mutex
+	done chan struct{}
+}
+
+var _ StreamHandlerWithError = &ChanStreamHandler{} // compile-time type check
+
+// NewChanStreamHandler creates a ChanStreamHandler. The open function is
+// called for each listen request and must return a receive-capable channel of
+// any element type, for example a `<-chan float64`.
+//
+// The done channel is closed when the stream is cancelled, producers should
+// stop sending and release their resources when it is. Values left in the
+// channel after the cancellation are not read.
+func NewChanStreamHandler(open func(arguments interface{}, done <-chan struct{}) (ch interface{}, err error)) *ChanStreamHandler {
+	return &ChanStreamHandler{open: open}
+}
+
+// OnListen calls the open function and starts forwarding the values of the
+// returned channel to the sink.
+func (c *ChanStreamHandler) OnListen(arguments interface{}, sink *EventSink) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.teardown()
+
+	done := make(chan struct{})
+	ch, err := c.open(arguments, done)
+	if err != nil {
+		close(done)
+		return err
+	}
+	value := reflect.ValueOf(ch)
+	if value.Kind() != reflect.Chan || value.Type().ChanDir()&reflect.RecvDir == 0 {
+		close(done)
+		return errors.Errorf("ChanStreamHandler: open returned %T, expected a receive-capable channel", ch)
+	}
+	c.done = done
+
+	go forwardChan(value, done, sink.streamSink())
+	return nil
+}
+
+// OnCancel closes the done channel given to the open function.
+func (c *ChanStreamHandler) OnCancel(arguments interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.teardown()
+	return nil
+}
+
+func (c *ChanStreamHandler) teardown() {
+	if c.done != nil {
+		close(c.done)
+		c.done = nil
+	}
+}
+
+func forwardChan(ch reflect.Value, done chan struct{}, sink *EventSink) {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)},
+		{Dir: reflect.SelectRecv, Chan: ch},
+	}
+	for {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return
+		}
+		// Select picks at random when a value and the cancellation are
+		// ready together, the value mustn't be sent after OnCancel.
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if !ok {
+			sink.EndOfStream()
+			return
+		}
+		switch v := value.Interface().(type) {
+		case *Error:
+			sink.Error(v.code, v.err, nil)
+		case error:
+			sink.Error("error", v.Error(), nil)
+		default:
+			sink.Success(v)
+		}
+	}
+}
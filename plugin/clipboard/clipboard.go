@@ -0,0 +1,97 @@
+// Package clipboard gives access to the clipboard with multiple MIME types,
+// for the flutter/platform channel and for plugins.
+package clipboard
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Common MIME types of the clipboard content.
+const (
+	MIMEText    = "text/plain"
+	MIMEHTML    = "text/html"
+	MIMEPNG     = "image/png"
+	MIMEURIList = "text/uri-list"
+)
+
+// ErrUnavailable is returned by Clipboard.Read when the content isn't
+// available in the requested MIME type.
+var ErrUnavailable = errors.New("clipboard content unavailable in the requested type")
+
+// Clipboard is a clipboard backend. The content of the clipboard has one or
+// more representations, identified by their MIME type.
+type Clipboard interface {
+	// Types returns the MIME types of the clipboard content, empty when the
+	// clipboard is empty.
+	Types() ([]string, error)
+	// Read returns the content in mimeType, or ErrUnavailable.
+	Read(mimeType string) ([]byte, error)
+	// Write replaces the content of the clipboard with the representations
+	// of content, keyed by MIME type. Backends which don't keep every
+	// representation write the others, and return a *DroppedError.
+	Write(content map[string][]byte) error
+}
+
+// DroppedError is returned by Clipboard.Write when the clipboard has been
+// written, but some representations of the content were dropped.
+type DroppedError struct {
+	// Types are the MIME types of the dropped representations, sorted.
+	Types []string
+}
+
+func (e *DroppedError) Error() string {
+	return "the clipboard dropped the " + strings.Join(e.Types, ", ") + " content"
+}
+
+// Dropped returns a *DroppedError with the types of content other than kept,
+// or nil when there are none. It's used by the backends which keep part of
+// the content.
+func Dropped(content map[string][]byte, kept ...string) error {
+	var dropped []string
+	for t := range content {
+		isKept := false
+		for _, k := range kept {
+			isKept = isKept || t == k
+		}
+		if !isKept {
+			dropped = append(dropped, t)
+		}
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+	sort.Strings(dropped)
+	return &DroppedError{Types: dropped}
+}
+
+// ReadText returns the text content of the clipboard, empty when the
+// clipboard has no text.
+func ReadText(c Clipboard) (string, error) {
+	text, err := c.Read(MIMEText)
+	if err == ErrUnavailable {
+		return "", nil
+	}
+	return string(text), err
+}
+
+// WriteText replaces the content of the clipboard with text.
+func WriteText(c Clipboard, text string) error {
+	return c.Write(map[string][]byte{MIMEText: []byte(text)})
+}
+
+// HasType tells whether the clipboard content is available in mimeType.
+func HasType(c Clipboard, mimeType string) (bool, error) {
+	types, err := c.Types()
+	if err != nil {
+		return false, err
+	}
+	for _, t := range types {
+		if t == mimeType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,228 @@
+package clipboard
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	if text, err := ReadText(m); err != nil || text != "" {
+		t.Fatalf("expected an empty clipboard, got %q, %v", text, err)
+	}
+	if _, err := m.Read(MIMEHTML); err != ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+
+	html := []byte("<b>hello</b>")
+	err := m.Write(map[string][]byte{MIMEText: []byte("hello"), MIMEHTML: html})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the written content is copied.
+	html[0] = 'x'
+
+	types, _ := m.Types()
+	if !reflect.DeepEqual(types, []string{MIMEHTML, MIMEText}) {
+		t.Fatalf("unexpected types %v", types)
+	}
+	data, err := m.Read(MIMEHTML)
+	if err != nil || string(data) != "<b>hello</b>" {
+		t.Fatalf("unexpected html %q, %v", data, err)
+	}
+	if ok, _ := HasType(m, MIMEPNG); ok {
+		t.Fatal("expected no image")
+	}
+
+	// Write replaces every representation.
+	WriteText(m, "bye")
+	if ok, _ := HasType(m, MIMEHTML); ok {
+		t.Fatal("expected the html to be replaced")
+	}
+	if text, _ := ReadText(m); text != "bye" {
+		t.Fatalf("expected the new text, got %q", text)
+	}
+}
+
+// fakeTool records the commands run by a Command.
+type fakeTool struct {
+	commands []string
+	inputs   []string
+	outputs  map[string]string
+}
+
+func (f *fakeTool) run(input []byte, output bool, name string, args ...string) ([]byte, error) {
+	command := name + " " + strings.Join(args, " ")
+	f.commands = append(f.commands, command)
+	f.inputs = append(f.inputs, string(input))
+	out, ok := f.outputs[command]
+	if !ok {
+		return nil, &exec.ExitError{}
+	}
+	return []byte(out), nil
+}
+
+func TestCommandX11(t *testing.T) {
+	f := &fakeTool{outputs: map[string]string{
+		"xclip -selection clipboard -target TARGETS -out":     "TIMESTAMP\nTARGETS\nUTF8_STRING\nSTRING\ntext/html\ntext/plain;charset=utf-8\n",
+		"xclip -selection clipboard -target UTF8_STRING -out": "hello",
+	}}
+	c := newCommand(toolX11, f.run)
+
+	types, err := c.Types()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(types, []string{MIMEText, MIMEHTML}) {
+		t.Fatalf("expected the X11 targets to be mapped to MIME types, got %v", types)
+	}
+	if text, err := ReadText(c); err != nil || text != "hello" {
+		t.Fatalf("unexpected text %q, %v", text, err)
+	}
+	if _, err := c.Read(MIMEPNG); err != ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+
+	f.outputs["xclip -selection clipboard -target image/png -in"] = ""
+	err = c.Write(map[string][]byte{MIMEText: []byte("image"), MIMEPNG: []byte("png")})
+	if dropped, ok := err.(*DroppedError); !ok || !reflect.DeepEqual(dropped.Types, []string{MIMEText}) {
+		t.Fatalf("expected the text to be reported as dropped, got %v", err)
+	}
+	if last := f.inputs[len(f.inputs)-1]; last != "png" {
+		t.Fatalf("expected the image to be written, got %q", last)
+	}
+}
+
+func TestCommandWayland(t *testing.T) {
+	f := &fakeTool{outputs: map[string]string{
+		"wl-paste --list-types":                      "text/uri-list\ntext/plain;charset=utf-8\ntext/plain\n",
+		"wl-paste --no-newline --type text/uri-list": "file:///tmp/a\r\n",
+		"wl-copy --type text/plain":                  "",
+	}}
+	c := newCommand(toolWayland, f.run)
+
+	types, _ := c.Types()
+	if !reflect.DeepEqual(types, []string{MIMEURIList, MIMEText}) {
+		t.Fatalf("unexpected types %v", types)
+	}
+	data, err := c.Read(MIMEURIList)
+	if err != nil || string(data) != "file:///tmp/a\r\n" {
+		t.Fatalf("unexpected uri list %q, %v", data, err)
+	}
+
+	// text is preferred to the other text representations.
+	err = c.Write(map[string][]byte{MIMEHTML: []byte("<i>a</i>"), MIMEText: []byte("a"), MIMEURIList: []byte("file:///a")})
+	if f.inputs[len(f.inputs)-1] != "a" {
+		t.Fatalf("expected the text to be written, got %q", f.inputs[len(f.inputs)-1])
+	}
+	if dropped, ok := err.(*DroppedError); !ok || !reflect.DeepEqual(dropped.Types, []string{MIMEHTML, MIMEURIList}) {
+		t.Fatalf("expected the html and uri list to be reported as dropped, got %v", err)
+	}
+	if err := WriteText(c, "b"); err != nil {
+		t.Fatalf("expected a single representation to be written, got %v", err)
+	}
+
+	// an empty clipboard has no types.
+	delete(f.outputs, "wl-paste --list-types")
+	types, err = c.Types()
+	if err != nil || len(types) != 0 {
+		t.Fatalf("expected no types, got %v, %v", types, err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	m := NewMemory()
+	WriteText(m, "a")
+	w := NewWatcher(m)
+
+	var changes []Change
+	unsubscribe := w.Subscribe(func(c Change) { changes = append(changes, c) })
+
+	// the first poll records the content.
+	w.Poll()
+	if len(changes) != 0 {
+		t.Fatalf("expected no change on the first poll, got %v", changes)
+	}
+
+	// an identical write isn't a change.
+	WriteText(m, "a")
+	w.Poll()
+	if len(changes) != 0 {
+		t.Fatalf("expected the same content to be deduplicated, got %v", changes)
+	}
+
+	WriteText(m, "b")
+	w.Poll()
+	w.Poll()
+	if len(changes) != 1 || !changes[0].HasType(MIMEText) {
+		t.Fatalf("expected a single text change, got %v", changes)
+	}
+
+	// a new type is a change, even with the same text.
+	m.Write(map[string][]byte{MIMEText: []byte("b"), MIMEHTML: []byte("<b>b</b>")})
+	w.Poll()
+	if len(changes) != 2 || !changes[1].HasType(MIMEHTML) {
+		t.Fatalf("expected a html change, got %v", changes)
+	}
+
+	// clearing the clipboard is a change.
+	m.Write(nil)
+	w.Poll()
+	if len(changes) != 3 || len(changes[2].Types) != 0 {
+		t.Fatalf("expected a change to an empty clipboard, got %v", changes)
+	}
+
+	// changes made without subscribers aren't notified.
+	unsubscribe()
+	WriteText(m, "c")
+	w.Poll()
+	w.Subscribe(func(c Change) { changes = append(changes, c) })
+	w.Poll()
+	if len(changes) != 3 {
+		t.Fatalf("expected no change while unsubscribed, got %v", changes)
+	}
+}
+
+func TestWatcherActive(t *testing.T) {
+	m := NewMemory()
+	w := NewWatcher(m)
+
+	var active []bool
+	first := w.Subscribe(func(Change) {})
+	w.OnActive(func(a bool) { active = append(active, a) })
+	second := w.Subscribe(func(Change) {})
+	first()
+	first()
+	second()
+	w.Subscribe(func(Change) {})
+	expected := []bool{true, false, true}
+	if !reflect.DeepEqual(active, expected) {
+		t.Fatalf("expected %v, got %v", expected, active)
+	}
+}
+
+func TestWatcherCheck(t *testing.T) {
+	m := NewMemory()
+	w := NewWatcher(m)
+
+	var changes []Change
+	w.Subscribe(func(c Change) { changes = append(changes, c) })
+	w.Check()
+	WriteText(m, "a")
+	change, changed, err := w.Check()
+	if err != nil || !changed || !change.HasType(MIMEText) {
+		t.Fatalf("expected a text change, got %v, %v, %v", change, changed, err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected Check not to notify, got %v", changes)
+	}
+	w.Notify(change)
+	if len(changes) != 1 {
+		t.Fatalf("expected Notify to notify, got %v", changes)
+	}
+	if _, changed, _ := w.Check(); changed {
+		t.Fatal("expected no change after the check")
+	}
+}
@@ -0,0 +1,181 @@
+package clipboard
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Command is a Clipboard backed by command line tools: wl-clipboard on
+// Wayland, xclip on X11. Unlike the GLFW clipboard, it reads and writes any
+// MIME type.
+//
+// The tools serve a single type when writing: when the content has several
+// representations, image/png is written if present, then text/plain, then the
+// first other type in lexical order. Write then returns a *DroppedError with
+// the other types.
+type Command struct {
+	tool tool
+	// run runs the command with input as its standard input, returning its
+	// standard output when output is true.
+	run func(input []byte, output bool, name string, args ...string) ([]byte, error)
+}
+
+var _ Clipboard = &Command{} // compile-time type check
+
+type tool int
+
+const (
+	toolWayland tool = iota
+	toolX11
+)
+
+// NewCommand returns the Command clipboard of the current session, or an
+// error when the session has no display or when the tool of its display
+// server isn't installed. The clipboard tools are only used on Linux.
+func NewCommand() (*Command, error) {
+	switch {
+	case runtime.GOOS != "linux":
+		return nil, errors.Errorf("the clipboard tools aren't used on %s", runtime.GOOS)
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		if _, err := exec.LookPath("wl-paste"); err != nil {
+			return nil, errors.Wrap(err, "wl-clipboard isn't installed")
+		}
+		return newCommand(toolWayland, runCommand), nil
+	case os.Getenv("DISPLAY") != "":
+		if _, err := exec.LookPath("xclip"); err != nil {
+			return nil, errors.Wrap(err, "xclip isn't installed")
+		}
+		return newCommand(toolX11, runCommand), nil
+	default:
+		return nil, errors.New("no Wayland nor X11 display")
+	}
+}
+
+func newCommand(tool tool, run func(input []byte, output bool, name string, args ...string) ([]byte, error)) *Command {
+	return &Command{tool: tool, run: run}
+}
+
+func runCommand(input []byte, output bool, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	if !output {
+		// The tools fork to serve the clipboard, their output must not be
+		// waited for.
+		return nil, cmd.Run()
+	}
+	return cmd.Output()
+}
+
+// x11TextTargets are the X11 targets of text, besides text/plain.
+var x11TextTargets = map[string]bool{
+	"UTF8_STRING":              true,
+	"STRING":                   true,
+	"TEXT":                     true,
+	"text/plain;charset=utf-8": true,
+}
+
+// Types returns the MIME types offered by the owner of the clipboard.
+func (c *Command) Types() ([]string, error) {
+	var out []byte
+	var err error
+	if c.tool == toolWayland {
+		out, err = c.run(nil, true, "wl-paste", "--list-types")
+	} else {
+		out, err = c.run(nil, true, "xclip", "-selection", "clipboard", "-target", "TARGETS", "-out")
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		// the clipboard is empty.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the clipboard types")
+	}
+
+	var types []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Fields(string(out)) {
+		if x11TextTargets[t] || strings.HasPrefix(t, MIMEText+";") {
+			t = MIMEText
+		}
+		// X11 targets which aren't MIME types, e.g., TIMESTAMP.
+		if !strings.Contains(t, "/") || seen[t] {
+			continue
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Read returns the content in mimeType, text is read as UTF-8.
+func (c *Command) Read(mimeType string) ([]byte, error) {
+	var out []byte
+	var err error
+	if c.tool == toolWayland {
+		out, err = c.run(nil, true, "wl-paste", "--no-newline", "--type", mimeType)
+	} else {
+		target := mimeType
+		if mimeType == MIMEText {
+			target = "UTF8_STRING"
+		}
+		out, err = c.run(nil, true, "xclip", "-selection", "clipboard", "-target", target, "-out")
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		return nil, ErrUnavailable
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the clipboard")
+	}
+	return out, nil
+}
+
+// Write writes a single representation of content, see Command.
+func (c *Command) Write(content map[string][]byte) error {
+	if len(content) == 0 {
+		if c.tool == toolWayland {
+			_, err := c.run(nil, false, "wl-copy", "--clear")
+			return errors.Wrap(err, "failed to clear the clipboard")
+		}
+		return c.Write(map[string][]byte{MIMEText: {}})
+	}
+
+	mimeType := writtenType(content)
+	data := content[mimeType]
+	var err error
+	if c.tool == toolWayland {
+		_, err = c.run(data, false, "wl-copy", "--type", mimeType)
+	} else {
+		target := mimeType
+		if mimeType == MIMEText {
+			target = "UTF8_STRING"
+		}
+		_, err = c.run(data, false, "xclip", "-selection", "clipboard", "-target", target, "-in")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write the clipboard")
+	}
+	return Dropped(content, mimeType)
+}
+
+// writtenType returns the type of the representation written by Write.
+func writtenType(content map[string][]byte) string {
+	for _, t := range []string{MIMEPNG, MIMEText} {
+		if _, ok := content[t]; ok {
+			return t
+		}
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types[0]
+}
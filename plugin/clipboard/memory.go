@@ -0,0 +1,55 @@
+package clipboard
+
+import (
+	"sort"
+	"sync"
+)
+
+// Memory is an in-memory Clipboard, e.g., for tests or for applications
+// which must not share their clipboard. It's safe for concurrent use.
+type Memory struct {
+	lock    sync.Mutex
+	content map[string][]byte
+}
+
+var _ Clipboard = &Memory{} // compile-time type check
+
+// NewMemory creates an empty Memory clipboard.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Types returns the MIME types of the content, sorted.
+func (m *Memory) Types() ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	types := make([]string, 0, len(m.content))
+	for t := range m.content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// Read returns a copy of the content in mimeType.
+func (m *Memory) Read(mimeType string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	data, ok := m.content[mimeType]
+	if !ok {
+		return nil, ErrUnavailable
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Write replaces the content with a copy of content.
+func (m *Memory) Write(content map[string][]byte) error {
+	copied := make(map[string][]byte, len(content))
+	for t, data := range content {
+		copied[t] = append([]byte(nil), data...)
+	}
+	m.lock.Lock()
+	m.content = copied
+	m.lock.Unlock()
+	return nil
+}
@@ -0,0 +1,173 @@
+package clipboard
+
+import (
+	"crypto/sha256"
+	"sort"
+	"sync"
+)
+
+// Change describes the new content of the clipboard.
+type Change struct {
+	// Types are the MIME types of the new content, empty when the clipboard
+	// has been cleared.
+	Types []string
+}
+
+// HasType tells whether the new content is available in mimeType.
+func (c Change) HasType(mimeType string) bool {
+	for _, t := range c.Types {
+		if t == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// Watcher notifies its subscribers when the content of a clipboard changes.
+// Clipboards don't tell when they change, the Watcher is polled.
+//
+// The content is identified by its types and by its text, or by its first
+// representation when it has no text, so that polling doesn't read every
+// representation. Polls finding the same content don't notify.
+type Watcher struct {
+	clipboard Clipboard
+
+	lock        sync.Mutex
+	subscribers map[uint64]func(Change)
+	nextID      uint64
+	onActive    func(active bool)
+	// polled is false until the content has been recorded by Poll
+	polled      bool
+	fingerprint [sha256.Size]byte
+}
+
+// NewWatcher creates a Watcher of c.
+func NewWatcher(c Clipboard) *Watcher {
+	return &Watcher{
+		clipboard:   c,
+		subscribers: make(map[uint64]func(Change)),
+	}
+}
+
+// Subscribe registers f to be called with every change, from the goroutine
+// calling Poll or Notify. The returned function unsubscribes f.
+func (w *Watcher) Subscribe(f func(Change)) (unsubscribe func()) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.subscribers) == 0 {
+		// changes made while nobody was watching aren't notified.
+		w.polled = false
+		if w.onActive != nil {
+			w.onActive(true)
+		}
+	}
+	w.nextID++
+	id := w.nextID
+	w.subscribers[id] = f
+	return func() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+		if _, ok := w.subscribers[id]; !ok {
+			return
+		}
+		delete(w.subscribers, id)
+		if len(w.subscribers) == 0 && w.onActive != nil {
+			w.onActive(false)
+		}
+	}
+}
+
+// OnActive sets f to be called with true when the Watcher gets its first
+// subscriber, and with false when its last subscriber leaves, so that the
+// Watcher is only polled while it has subscribers. f is called right away
+// when the Watcher already has subscribers.
+//
+// f is called with the Watcher locked, it must not call the Watcher.
+func (w *Watcher) OnActive(f func(active bool)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.onActive = f
+	if f != nil && len(w.subscribers) > 0 {
+		f(true)
+	}
+}
+
+// Poll reads the clipboard and notifies the subscribers when its content has
+// changed since the previous poll. The first poll after the first
+// subscription records the content without notifying. The clipboard isn't
+// read when there is no subscriber.
+func (w *Watcher) Poll() error {
+	change, changed, err := w.Check()
+	if err != nil || !changed {
+		return err
+	}
+	w.Notify(change)
+	return nil
+}
+
+// Check reads the clipboard like Poll, but returns the change instead of
+// notifying it. It lets the clipboard be read on one goroutine and the
+// subscribers be notified on another one, with Notify.
+func (w *Watcher) Check() (change Change, changed bool, err error) {
+	w.lock.Lock()
+	active := len(w.subscribers) > 0
+	w.lock.Unlock()
+	if !active {
+		return Change{}, false, nil
+	}
+
+	types, err := w.clipboard.Types()
+	if err != nil {
+		return Change{}, false, err
+	}
+	types = append([]string(nil), types...)
+	sort.Strings(types)
+	fingerprint, err := w.fingerprintOf(types)
+	if err != nil {
+		return Change{}, false, err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	changed = w.polled && fingerprint != w.fingerprint
+	w.polled = true
+	w.fingerprint = fingerprint
+	return Change{Types: types}, changed, nil
+}
+
+// Notify calls the subscribers with change.
+func (w *Watcher) Notify(change Change) {
+	w.lock.Lock()
+	subscribers := make([]func(Change), 0, len(w.subscribers))
+	for _, f := range w.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	w.lock.Unlock()
+
+	for _, f := range subscribers {
+		f(change)
+	}
+}
+
+// fingerprintOf hashes the types, sorted, and the identifying representation
+// of the content.
+func (w *Watcher) fingerprintOf(types []string) (fingerprint [sha256.Size]byte, err error) {
+	h := sha256.New()
+	for _, t := range types {
+		h.Write([]byte(t))
+		h.Write([]byte{0})
+	}
+	if len(types) > 0 {
+		identifying := types[0]
+		if (Change{Types: types}).HasType(MIMEText) {
+			identifying = MIMEText
+		}
+		data, err := w.clipboard.Read(identifying)
+		if err != nil && err != ErrUnavailable {
+			return fingerprint, err
+		}
+		h.Write(data)
+	}
+	copy(fingerprint[:], h.Sum(nil))
+	return fingerprint, nil
+}
@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Dispatcher runs the handlers of a channel outside of the platform thread.
+// Without a Dispatcher, MethodChannel and EventChannel start a goroutine for
+// each message, which gives no guarantee on the order in which handlers run or
+// finish, and BasicMessageChannel runs its handler on the platform thread.
+//
+// A Dispatcher may be shared by several channels.
+type Dispatcher interface {
+	// Dispatch schedules the handling of a message. The message is the decoded
+	// MethodCall for MethodChannel and EventChannel, and the decoded message
+	// for BasicMessageChannel. It may be used to choose an ordering key.
+	Dispatch(message interface{}, task func())
+	// Stats returns metrics about the pending and running tasks.
+	Stats() DispatcherStats
+}
+
+// DispatcherStats contains the metrics of a Dispatcher.
+type DispatcherStats struct {
+	// QueueDepth is the number of tasks waiting to run.
+	QueueDepth int
+	// MaxQueueDepth is the highest QueueDepth observed.
+	MaxQueueDepth int
+	// Running is the number of workers running tasks.
+	Running int
+	// Completed is the number of tasks that have finished.
+	Completed uint64
+}
+
+// NewSerialDispatcher returns a Dispatcher running one task at a time, in the
+// order they were dispatched. Replies are sent in the order the messages were
+// received.
+func NewSerialDispatcher() Dispatcher {
+	return newDispatcher(1, nil)
+}
+
+// NewPoolDispatcher returns a Dispatcher running at most workers tasks
+// concurrently. Tasks are started in the order they were dispatched.
+func NewPoolDispatcher(workers int) Dispatcher {
+	return newDispatcher(workers, nil)
+}
+
+// NewKeyedDispatcher returns a Dispatcher running at most workers tasks
+// concurrently, where the tasks having the same key run one at a time, in the
+// order they were dispatched.
+//
+// The key function receives the message given to Dispatch. When it is nil,
+// the method name is used as key for method calls, and all other messages
+// share a single key.
+func NewKeyedDispatcher(workers int, key func(message interface{}) string) Dispatcher {
+	if key == nil {
+		key = methodNameKey
+	}
+	return newDispatcher(workers, key)
+}
+
+func methodNameKey(message interface{}) string {
+	switch call := message.(type) {
+	case MethodCall:
+		return call.Method
+	case *MethodCall:
+		return call.Method
+	default:
+		return ""
+	}
+}
+
+// dispatch runs task with the dispatcher, or in a new goroutine when the
+// dispatcher is nil.
+func dispatch(dispatcher Dispatcher, message interface{}, task func()) {
+	if dispatcher == nil {
+		go task()
+		return
+	}
+	dispatcher.Dispatch(message, task)
+}
+
+type dispatcherTask struct {
+	key string
+	run func()
+}
+
+// dispatcher implements the three policies: a serial dispatcher is a pool of
+// one worker, a keyed dispatcher holds back the tasks whose key is in use.
+type dispatcher struct {
+	workers int
+	key     func(message interface{}) string
+
+	lock sync.Mutex
+	// ready holds the tasks which can run as soon as a worker is available.
+	ready []dispatcherTask
+	// waiting holds, per key in use, the tasks queued behind the running or
+	// ready task of that key.
+	waiting map[string][]dispatcherTask
+	stats   DispatcherStats
+}
+
+func newDispatcher(workers int, key func(message interface{}) string) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &dispatcher{
+		workers: workers,
+		key:     key,
+		waiting: make(map[string][]dispatcherTask),
+	}
+}
+
+func (d *dispatcher) Dispatch(message interface{}, task func()) {
+	t := dispatcherTask{run: task}
+	if d.key != nil {
+		t.key = d.key(message)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.stats.QueueDepth++
+	if d.stats.QueueDepth > d.stats.MaxQueueDepth {
+		d.stats.MaxQueueDepth = d.stats.QueueDepth
+	}
+	if d.key != nil {
+		if queue, inUse := d.waiting[t.key]; inUse {
+			d.waiting[t.key] = append(queue, t)
+			return
+		}
+		d.waiting[t.key] = nil
+	}
+	d.ready = append(d.ready, t)
+	if d.stats.Running < d.workers {
+		d.stats.Running++
+		go d.work()
+	}
+}
+
+func (d *dispatcher) Stats() DispatcherStats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.stats
+}
+
+// work runs ready tasks until there are none left.
+func (d *dispatcher) work() {
+	d.lock.Lock()
+	for len(d.ready) > 0 {
+		t := d.ready[0]
+		d.ready[0] = dispatcherTask{}
+		d.ready = d.ready[1:]
+		d.stats.QueueDepth--
+		d.lock.Unlock()
+
+		runDispatcherTask(t)
+
+		d.lock.Lock()
+		d.stats.Completed++
+		if d.key != nil {
+			if queue := d.waiting[t.key]; len(queue) > 0 {
+				d.ready = append(d.ready, queue[0])
+				d.waiting[t.key] = queue[1:]
+			} else {
+				delete(d.waiting, t.key)
+			}
+		}
+	}
+	d.stats.Running--
+	d.lock.Unlock()
+}
+
+func runDispatcherTask(t dispatcherTask) {
+	defer func() {
+		p := recover()
+		if p != nil {
+			fmt.Printf("go-flutter: recovered from panic in dispatched task: %v\n", p)
+			debug.PrintStack()
+		}
+	}()
+	t.run()
+}
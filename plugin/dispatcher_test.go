@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitCompleted(t *testing.T, d Dispatcher, n uint64) {
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Completed < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d completed tasks, got %+v", n, d.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSerialDispatcher(t *testing.T) {
+	d := NewSerialDispatcher()
+	var lock sync.Mutex
+	var order []int
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		i := i
+		d.Dispatch(nil, func() {
+			if i == 0 {
+				<-release
+			}
+			lock.Lock()
+			order = append(order, i)
+			lock.Unlock()
+		})
+	}
+	stats := d.Stats()
+	assert.Equal(t, 1, stats.Running)
+	assert.True(t, stats.QueueDepth >= 9)
+	close(release)
+	waitCompleted(t, d, 10)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, order)
+	stats = d.Stats()
+	assert.Equal(t, 0, stats.QueueDepth)
+	assert.Equal(t, 10, stats.MaxQueueDepth)
+	assert.Equal(t, 0, stats.Running)
+}
+
+func TestPoolDispatcher(t *testing.T) {
+	d := NewPoolDispatcher(3)
+	var running, maxRunning int32
+	for i := 0; i < 30; i++ {
+		d.Dispatch(nil, func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	waitCompleted(t, d, 30)
+	assert.True(t, maxRunning <= 3, "at most 3 tasks run concurrently, got %d", maxRunning)
+}
+
+func TestKeyedDispatcher(t *testing.T) {
+	d := NewKeyedDispatcher(4, nil)
+	var lock sync.Mutex
+	order := make(map[string][]int)
+	blockA := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		for _, method := range []string{"a", "b"} {
+			i, method := i, method
+			d.Dispatch(MethodCall{Method: method}, func() {
+				if method == "a" && i == 0 {
+					<-blockA
+				}
+				lock.Lock()
+				order[method] = append(order[method], i)
+				lock.Unlock()
+			})
+		}
+	}
+	// "b" isn't held back by the blocked "a" task.
+	waitCompleted(t, d, 5)
+	lock.Lock()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order["b"])
+	assert.Empty(t, order["a"])
+	lock.Unlock()
+	assert.Equal(t, 4, d.Stats().QueueDepth)
+
+	close(blockA)
+	waitCompleted(t, d, 10)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order["a"])
+}
+
+func TestMethodChannelSerialDispatcher(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	codec := StandardMethodCodec{}
+	channel := NewMethodChannel(messenger, "ch", codec)
+	channel.SetDispatcher(NewSerialDispatcher())
+
+	var calls []interface{}
+	channel.HandleFunc("add", func(arguments interface{}) (interface{}, error) {
+		calls = append(calls, arguments)
+		return int32(len(calls)), nil
+	})
+
+	for i := int32(1); i <= 3; i++ {
+		call, err := codec.EncodeMethodCall(MethodCall{Method: "add", Arguments: i})
+		require.NoError(t, err)
+		reply, err := messenger.MockSendAndWait("ch", call)
+		require.NoError(t, err)
+		result, err := codec.DecodeEnvelope(reply)
+		require.NoError(t, err)
+		assert.Equal(t, i, result)
+	}
+	assert.Equal(t, []interface{}{int32(1), int32(2), int32(3)}, calls)
+}
@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BackpressurePolicy defines what a buffered EventChannel does with a new
+// event when its buffer is full.
+type BackpressurePolicy int
+
+const (
+	// DropOldest discards the oldest queued event to make room for the new
+	// one. Suited to streams where only recent values matter, like sensors.
+	DropOldest BackpressurePolicy = iota
+	// DropNewest discards the new event.
+	DropNewest
+	// Block makes the EventSink method wait until the event can be queued.
+	// Producers also wait while Flutter isn't listening and the buffer is
+	// full.
+	//
+	// Producers running on the platform thread, e.g. in a channel handler or
+	// a plugin callback, must not use Block: the listen request is handled on
+	// that thread, so they would wait forever.
+	Block
+)
+
+// eventBuffer queues the encoded events of a buffered EventChannel and sends
+// them from its own goroutine while a stream is listening.
+type eventBuffer struct {
+	eventChannel *EventChannel
+	size         int
+	policy       BackpressurePolicy
+
+	lock sync.Mutex
+	// cond is signaled when an event is queued, when room is made in the
+	// buffer and when the listening state changes.
+	cond      *sync.Cond
+	events    [][]byte
+	listening bool
+	// generation identifies the current stream, it lets the goroutine of a
+	// cancelled stream stop even if a new stream has started.
+	generation int
+	dropped    uint64
+}
+
+func newEventBuffer(eventChannel *EventChannel, size int, policy BackpressurePolicy) *eventBuffer {
+	if size < 1 {
+		size = 1
+	}
+	b := &eventBuffer{
+		eventChannel: eventChannel,
+		size:         size,
+		policy:       policy,
+	}
+	b.cond = sync.NewCond(&b.lock)
+	return b
+}
+
+// push queues an encoded event, a nil event marks the end of the stream. A
+// non-zero generation binds the event to a stream, the event is dropped when
+// that stream isn't listening anymore.
+func (b *eventBuffer) push(binaryMsg []byte, generation int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for {
+		if generation != 0 && (generation != b.generation || !b.listening) {
+			b.dropped++
+			return
+		}
+		if len(b.events) < b.size {
+			break
+		}
+		switch b.policy {
+		case DropNewest:
+			b.dropped++
+			return
+		case Block:
+			b.cond.Wait()
+		default:
+			b.events[0] = nil
+			b.events = b.events[1:]
+			b.dropped++
+		}
+	}
+	b.events = append(b.events, binaryMsg)
+	b.cond.Broadcast()
+}
+
+// start flushes the queued events, and the following ones, to Flutter.
+func (b *eventBuffer) start() {
+	b.lock.Lock()
+	b.listening = true
+	b.generation++
+	generation := b.generation
+	b.lock.Unlock()
+
+	go b.run(generation)
+}
+
+// currentGeneration returns the generation of the last stream started.
+func (b *eventBuffer) currentGeneration() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.generation
+}
+
+// stop discards the queued events and stops sending until the next start.
+func (b *eventBuffer) stop() {
+	b.lock.Lock()
+	b.listening = false
+	b.dropped += uint64(len(b.events))
+	b.events = nil
+	b.cond.Broadcast()
+	b.lock.Unlock()
+}
+
+func (b *eventBuffer) droppedCount() uint64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.dropped
+}
+
+func (b *eventBuffer) run(generation int) {
+	channelName := b.eventChannel.channelName
+	b.lock.Lock()
+	for {
+		for b.generation == generation && b.listening && len(b.events) == 0 {
+			b.cond.Wait()
+		}
+		if b.generation != generation || !b.listening {
+			b.lock.Unlock()
+			return
+		}
+		events := b.events
+		b.events = make([][]byte, 0, b.size)
+		b.cond.Broadcast()
+		b.lock.Unlock()
+
+		for _, binaryMsg := range events {
+			err := b.eventChannel.messenger.Send(channelName, binaryMsg)
+			if err != nil {
+				fmt.Printf("go-flutter: failed to send buffered event on event channel '%s', error: %v\n", channelName, err)
+			}
+		}
+
+		b.lock.Lock()
+	}
+}
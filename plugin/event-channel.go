@@ -3,6 +3,7 @@ package plugin
 import (
 	"fmt"
 	"runtime/debug"
+	"sync"
 
 	"github.com/pkg/errors"
 )
@@ -15,8 +16,16 @@ type EventChannel struct {
 	channelName string
 	methodCodec MethodCodec
 
-	handler    StreamHandler
-	activeSink *EventSink
+	handler StreamHandlerWithError
+
+	activeSinkLock sync.Mutex
+	activeSink     *EventSink
+
+	dispatcher Dispatcher
+
+	// buffer is only set on channels created with NewBufferedEventChannel.
+	buffer *eventBuffer
+	sink   *EventSink
 }
 
 // NewEventChannel creates a new event channel
@@ -30,6 +39,42 @@ func NewEventChannel(messenger BinaryMessenger, channelName string, methodCodec
 	return ec
 }
 
+// NewBufferedEventChannel creates a new event channel whose events are queued
+// in a buffer of the given size and sent to Flutter from a dedicated
+// goroutine, so EventSink methods don't wait on the messenger.
+//
+// Events sent while no stream is listening are kept in the buffer and
+// delivered when Flutter starts listening. Events still queued when the stream
+// is cancelled are discarded. When the buffer is full, the policy decides
+// which event is dropped, or whether the producer waits. Producers on the
+// platform thread must not use the Block policy, see Block.
+//
+// The channel has a single EventSink, returned by Sink, which is also the
+// sink given to the StreamHandler.
+func NewBufferedEventChannel(messenger BinaryMessenger, channelName string, methodCodec MethodCodec, size int, policy BackpressurePolicy) (channel *EventChannel) {
+	ec := NewEventChannel(messenger, channelName, methodCodec)
+	ec.buffer = newEventBuffer(ec, size, policy)
+	ec.sink = &EventSink{eventChannel: ec}
+	return ec
+}
+
+// Sink returns the EventSink of a buffered event channel. It may be used
+// before Flutter starts listening and stays valid across listen and cancel
+// calls. Sink returns nil for channels created with NewEventChannel.
+func (e *EventChannel) Sink() *EventSink {
+	return e.sink
+}
+
+// Dropped returns the number of events dropped by a buffered event channel,
+// either by the backpressure policy or because the stream was cancelled
+// before they were sent.
+func (e *EventChannel) Dropped() uint64 {
+	if e.buffer == nil {
+		return 0
+	}
+	return e.buffer.droppedCount()
+}
+
 // Handle registers a StreamHandler for a event channel.
 //
 // Consecutive calls override any existing handler registration.
@@ -39,18 +84,57 @@ func NewEventChannel(messenger BinaryMessenger, channelName string, methodCodec
 // When no handler is registered for a method, it will be handled silently by
 // sending a nil reply which triggers the dart MissingPluginException exception.
 func (e *EventChannel) Handle(handler StreamHandler) {
+	if handler == nil {
+		e.handler = nil
+		return
+	}
+	e.handler = streamHandlerAdapter{handler}
+}
+
+// HandleWithError registers a StreamHandlerWithError for a event channel.
+//
+// Unlike Handle, the reply to the listen and cancel requests is sent once the
+// handler returns, errors are sent to Flutter as a PlatformException.
+func (e *EventChannel) HandleWithError(handler StreamHandlerWithError) {
 	e.handler = handler
 }
 
+// SetDispatcher sets the Dispatcher running the OnListen and OnCancel methods
+// of the handler. When no Dispatcher is set, each call is run in a new
+// goroutine.
+func (e *EventChannel) SetDispatcher(dispatcher Dispatcher) {
+	e.dispatcher = dispatcher
+}
+
+func (e *EventChannel) getActiveSink() *EventSink {
+	e.activeSinkLock.Lock()
+	defer e.activeSinkLock.Unlock()
+	return e.activeSink
+}
+
+// swapActiveSink replaces the active sink if it is old, and reports whether
+// it did so.
+func (e *EventChannel) swapActiveSink(old, new *EventSink) bool {
+	e.activeSinkLock.Lock()
+	defer e.activeSinkLock.Unlock()
+	if e.activeSink != old {
+		return false
+	}
+	e.activeSink = new
+	return true
+}
+
 // handleChannelMessage decodes incoming binary message to a method call, calls the
 // handler, and encodes the outgoing reply.
 func (e *EventChannel) handleChannelMessage(binaryMessage []byte, responseSender ResponseSender) (err error) {
 	methodCall, err := e.methodCodec.DecodeMethodCall(binaryMessage)
 	if err != nil {
+		responseSender.Send(nil)
 		return errors.Wrap(err, "failed to decode incoming message")
 	}
 
-	if e.handler == nil {
+	handler := e.handler
+	if handler == nil {
 		fmt.Printf("go-flutter: no method handler registered for event channel '%s'\n", e.channelName)
 		responseSender.Send(nil)
 		return nil
@@ -66,29 +150,48 @@ func (e *EventChannel) handleChannelMessage(binaryMessage []byte, responseSender
 
 	switch methodCall.Method {
 	case "listen":
-
-		binaryReply, err := e.methodCodec.EncodeSuccessEnvelope(nil)
-		if err != nil {
-			fmt.Printf("go-flutter: failed to encode listen envelope for event channel '%s', error: %v\n", e.channelName, err)
-		}
-		responseSender.Send(binaryReply)
-
-		if e.activeSink != nil {
+		if previous := e.getActiveSink(); previous != nil {
 			// Repeated calls to onListen may happen during hot restart.
 			// We separate them with a call to onCancel.
-			e.handler.OnCancel(nil)
+			e.swapActiveSink(previous, nil)
+			e.stopBuffer()
+			err = handler.OnCancel(nil)
+			if err != nil {
+				fmt.Printf("go-flutter: failed to cancel previous stream on event channel '%s': %v\n", e.channelName, err)
+			}
 		}
 
-		e.activeSink = &EventSink{eventChannel: e}
-		go e.handler.OnListen(methodCall.Arguments, e.activeSink)
+		sink := e.sink
+		if sink == nil {
+			sink = &EventSink{eventChannel: e}
+		}
+		if e.buffer != nil {
+			// started before the reset, which may wait for a producer
+			// blocked on a full buffer.
+			e.buffer.start()
+		}
+		sink.reset()
+		e.swapActiveSink(nil, sink)
+
+		dispatch(e.dispatcher, methodCall, func() {
+			e.handleStreamCall(methodCall.Method, responseSender, func() error {
+				err := handler.OnListen(methodCall.Arguments, sink)
+				if err != nil && e.swapActiveSink(sink, nil) {
+					e.stopBuffer()
+				}
+				return err
+			})
+		})
 
 	case "cancel":
-		if e.activeSink != nil {
-			e.activeSink = nil
-			go e.handler.OnCancel(methodCall.Arguments)
-
-			binaryReply, _ := e.methodCodec.EncodeSuccessEnvelope(nil)
-			responseSender.Send(binaryReply)
+		active := e.getActiveSink()
+		if active != nil && e.swapActiveSink(active, nil) {
+			e.stopBuffer()
+			dispatch(e.dispatcher, methodCall, func() {
+				e.handleStreamCall(methodCall.Method, responseSender, func() error {
+					return handler.OnCancel(methodCall.Arguments)
+				})
+			})
 		} else {
 			fmt.Printf("go-flutter: No active stream to cancel onEventChannel '%s'\n", e.channelName)
 			binaryReply, _ := e.methodCodec.EncodeErrorEnvelope("error", "No active stream to cancel", nil)
@@ -102,3 +205,49 @@ func (e *EventChannel) handleChannelMessage(binaryMessage []byte, responseSender
 
 	return nil
 }
+
+// handleStreamCall calls f and replies to a listen or cancel request with
+// its result.
+func (e *EventChannel) handleStreamCall(methodName string, responseSender ResponseSender, f func() error) {
+	defer func() {
+		p := recover()
+		if p != nil {
+			fmt.Printf("go-flutter: recovered from panic while handling '%s' on event channel '%s': %v\n", methodName, e.channelName, p)
+			debug.PrintStack()
+			binaryReply, _ := e.methodCodec.EncodeErrorEnvelope("error", fmt.Sprint(p), nil)
+			responseSender.Send(binaryReply)
+		}
+	}()
+
+	err := f()
+	if err != nil {
+		fmt.Printf("go-flutter: handler for '%s' on event channel '%s' returned an error: %v\n", methodName, e.channelName, err)
+
+		var errorCode string
+		switch t := err.(type) {
+		case *Error:
+			errorCode = t.code
+		default:
+			errorCode = "error"
+		}
+
+		binaryReply, err := e.methodCodec.EncodeErrorEnvelope(errorCode, err.Error(), nil)
+		if err != nil {
+			fmt.Printf("go-flutter: failed to encode error envelope for '%s' on event channel '%s', error: %v\n", methodName, e.channelName, err)
+		}
+		responseSender.Send(binaryReply)
+		return
+	}
+
+	binaryReply, err := e.methodCodec.EncodeSuccessEnvelope(nil)
+	if err != nil {
+		fmt.Printf("go-flutter: failed to encode %s envelope for event channel '%s', error: %v\n", methodName, e.channelName, err)
+	}
+	responseSender.Send(binaryReply)
+}
+
+func (e *EventChannel) stopBuffer() {
+	if e.buffer != nil {
+		e.buffer.stop()
+	}
+}
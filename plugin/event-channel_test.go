@@ -0,0 +1,236 @@
+package plugin
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEventListener records the events received on the Flutter side of an
+// event channel.
+type mockEventListener struct {
+	lock   sync.Mutex
+	events []interface{}
+	ended  chan struct{}
+}
+
+func newMockEventListener(t *testing.T, messenger *TestingBinaryMessenger, channelName string) *mockEventListener {
+	l := &mockEventListener{ended: make(chan struct{})}
+	codec := StandardMethodCodec{}
+	messenger.MockSetChannelHandler(channelName, func(msg []byte, r ResponseSender) error {
+		defer r.Send(nil)
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		if msg == nil {
+			close(l.ended)
+			return nil
+		}
+		event, err := codec.DecodeEnvelope(msg)
+		if err != nil {
+			l.events = append(l.events, err)
+			return nil
+		}
+		l.events = append(l.events, event)
+		return nil
+	})
+	return l
+}
+
+func (l *mockEventListener) received() []interface{} {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]interface{}(nil), l.events...)
+}
+
+// waitEvents waits until n events have been received.
+func (l *mockEventListener) waitEvents(t *testing.T, n int) {
+	deadline := time.Now().Add(time.Second)
+	for len(l.received()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d events, got %v", n, l.received())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func mockStreamCall(t *testing.T, messenger *TestingBinaryMessenger, channelName, method string) error {
+	codec := StandardMethodCodec{}
+	call, err := codec.EncodeMethodCall(MethodCall{Method: method})
+	require.NoError(t, err)
+	reply, err := messenger.MockSendAndWait(channelName, call)
+	require.NoError(t, err)
+	_, err = codec.DecodeEnvelope(reply)
+	return err
+}
+
+type testStreamHandler struct {
+	onListen func(sink *EventSink) error
+	onCancel func() error
+}
+
+func (h testStreamHandler) OnListen(arguments interface{}, sink *EventSink) error {
+	return h.onListen(sink)
+}
+
+func (h testStreamHandler) OnCancel(arguments interface{}) error {
+	if h.onCancel == nil {
+		return nil
+	}
+	return h.onCancel()
+}
+
+func TestEventChannelListenError(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewEventChannel(messenger, "ch", StandardMethodCodec{})
+	listener := newMockEventListener(t, messenger, "ch")
+
+	var sink *EventSink
+	channel.HandleWithError(testStreamHandler{onListen: func(s *EventSink) error {
+		sink = s
+		return NewError("unavailable", errors.New("no sensor"))
+	}})
+	err := mockStreamCall(t, messenger, "ch", "listen")
+	assert.Equal(t, FlutterError{Code: "unavailable", Message: "no sensor"}, err)
+
+	sink.Success(1)
+	assert.Empty(t, listener.received())
+	err = mockStreamCall(t, messenger, "ch", "cancel")
+	assert.Error(t, err, "the stream wasn't started")
+}
+
+func TestBufferedEventChannel(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewBufferedEventChannel(messenger, "ch", StandardMethodCodec{}, 2, DropOldest)
+	listener := newMockEventListener(t, messenger, "ch")
+	channel.HandleWithError(testStreamHandler{onListen: func(s *EventSink) error {
+		assert.Equal(t, channel.Sink(), s)
+		return nil
+	}})
+
+	// sent before listen, only the last two events are kept
+	for i := int32(1); i <= 4; i++ {
+		channel.Sink().Success(i)
+	}
+	assert.Equal(t, uint64(2), channel.Dropped())
+
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "listen"))
+	listener.waitEvents(t, 2)
+	channel.Sink().Success(int32(5))
+	channel.Sink().EndOfStream()
+	select {
+	case <-listener.ended:
+	case <-time.After(time.Second):
+		t.Fatal("end of stream not received")
+	}
+	assert.Equal(t, []interface{}{int32(3), int32(4), int32(5)}, listener.received())
+
+	// ended streams drop events until the next listen
+	channel.Sink().Success(int32(6))
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "cancel"))
+	assert.Equal(t, []interface{}{int32(3), int32(4), int32(5)}, listener.received())
+}
+
+func TestBufferedEventChannelDropNewest(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewBufferedEventChannel(messenger, "ch", StandardMethodCodec{}, 1, DropNewest)
+	for i := int32(1); i <= 3; i++ {
+		channel.Sink().Success(i)
+	}
+	assert.Equal(t, uint64(2), channel.Dropped())
+	assert.Equal(t, 1, len(channel.buffer.events))
+}
+
+func TestChanStreamHandler(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewEventChannel(messenger, "ch", StandardMethodCodec{})
+	listener := newMockEventListener(t, messenger, "ch")
+
+	values := make(chan interface{})
+	cancelled := make(chan struct{})
+	channel.HandleWithError(NewChanStreamHandler(func(arguments interface{}, done <-chan struct{}) (interface{}, error) {
+		go func() {
+			<-done
+			close(cancelled)
+		}()
+		return (<-chan interface{})(values), nil
+	}))
+
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "listen"))
+	values <- "a"
+	values <- NewError("bad", errors.New("bad value"))
+	listener.waitEvents(t, 2)
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "cancel"))
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("producer not torn down")
+	}
+	assert.Equal(t, []interface{}{"a", FlutterError{Code: "bad", Message: "bad value"}}, listener.received())
+
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "listen"))
+	close(values)
+	select {
+	case <-listener.ended:
+	case <-time.After(time.Second):
+		t.Fatal("end of stream not received")
+	}
+}
+
+func TestChanStreamHandlerInvalidChannel(t *testing.T) {
+	handler := NewChanStreamHandler(func(arguments interface{}, done <-chan struct{}) (interface{}, error) {
+		return 42, nil
+	})
+	err := handler.OnListen(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestBufferedEventChannelStreamSink(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewBufferedEventChannel(messenger, "ch", StandardMethodCodec{}, 4, DropOldest)
+	listener := newMockEventListener(t, messenger, "ch")
+	var stream *EventSink
+	channel.HandleWithError(testStreamHandler{onListen: func(s *EventSink) error {
+		stream = s.streamSink()
+		return nil
+	}})
+
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "listen"))
+	stream.Success(int32(1))
+	listener.waitEvents(t, 1)
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "cancel"))
+
+	// a late event of the cancelled stream isn't kept for the next listener
+	stream.Success(int32(2))
+	assert.Equal(t, uint64(1), channel.Dropped())
+	channel.Sink().Success(int32(3))
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "listen"))
+	listener.waitEvents(t, 2)
+	assert.Equal(t, []interface{}{int32(1), int32(3)}, listener.received())
+}
+
+func TestChanStreamHandlerBuffered(t *testing.T) {
+	messenger := NewTestingBinaryMessenger()
+	channel := NewBufferedEventChannel(messenger, "ch", StandardMethodCodec{}, 4, DropOldest)
+	listener := newMockEventListener(t, messenger, "ch")
+
+	values := make(chan interface{}, 4)
+	channel.HandleWithError(NewChanStreamHandler(func(arguments interface{}, done <-chan struct{}) (interface{}, error) {
+		return (<-chan interface{})(values), nil
+	}))
+
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "listen"))
+	values <- "a"
+	listener.waitEvents(t, 1)
+	require.NoError(t, mockStreamCall(t, messenger, "ch", "cancel"))
+
+	// values left in the channel aren't forwarded after the cancellation
+	values <- "b"
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, uint64(0), channel.Dropped())
+	assert.Empty(t, channel.buffer.events)
+	assert.Equal(t, []interface{}{"a"}, listener.received())
+}
@@ -15,20 +15,61 @@ type StreamHandler interface {
 	OnCancel(arguments interface{})
 }
 
+// StreamHandlerWithError is a StreamHandler able to report failures to
+// Flutter, where they are thrown as a PlatformException by the listen or
+// cancel call.
+//
+// Both methods are called in a new goroutine and the reply is sent when they
+// return. OnListen should start the production of events and return, rather
+// than block for the lifetime of the stream.
+type StreamHandlerWithError interface {
+	// OnListen handles a request to set up an event stream. When an error is
+	// returned, the stream isn't started.
+	OnListen(arguments interface{}, sink *EventSink) error
+	// OnCancel handles a request to tear down the most recently created event
+	// stream.
+	OnCancel(arguments interface{}) error
+}
+
+// streamHandlerAdapter keeps the original StreamHandler semantics: OnListen
+// runs on its own goroutine and the listen request is acknowledged right away.
+type streamHandlerAdapter struct {
+	handler StreamHandler
+}
+
+func (s streamHandlerAdapter) OnListen(arguments interface{}, sink *EventSink) error {
+	go s.handler.OnListen(arguments, sink)
+	return nil
+}
+
+func (s streamHandlerAdapter) OnCancel(arguments interface{}) error {
+	s.handler.OnCancel(arguments)
+	return nil
+}
+
 // EventSink defines the interface for producers of events to send message to
 // Flutter. StreamHandler act as a clients of EventSink for sending events.
 type EventSink struct {
 	eventChannel *EventChannel
+	// generation is the stream of a buffered channel the sink is bound to,
+	// see streamSink. It's 0 for the sink of the channel.
+	generation int
 
 	hasEnded bool
 	sync.Mutex
 }
 
+func (es *EventSink) reset() {
+	es.Lock()
+	es.hasEnded = false
+	es.Unlock()
+}
+
 // Success consumes a successful event.
 func (es *EventSink) Success(event interface{}) {
 	es.Lock()
 	defer es.Unlock()
-	if es.hasEnded || es != es.eventChannel.activeSink {
+	if !es.isActive() {
 		return
 	}
 
@@ -36,17 +77,14 @@ func (es *EventSink) Success(event interface{}) {
 	if err != nil {
 		fmt.Printf("go-flutter: failed to encode success envelope for event channel '%s', error: %v\n", es.eventChannel.channelName, err)
 	}
-	err = es.eventChannel.messenger.Send(es.eventChannel.channelName, binaryMsg)
-	if err != nil {
-		fmt.Printf("go-flutter: failed to send Success message on event channel '%s', error: %v\n", es.eventChannel.channelName, err)
-	}
+	es.send(binaryMsg, "Success")
 }
 
 // Error consumes an error event.
 func (es *EventSink) Error(errorCode string, errorMessage string, errorDetails interface{}) {
 	es.Lock()
 	defer es.Unlock()
-	if es.hasEnded || es != es.eventChannel.activeSink {
+	if !es.isActive() {
 		return
 	}
 
@@ -54,23 +92,51 @@ func (es *EventSink) Error(errorCode string, errorMessage string, errorDetails i
 	if err != nil {
 		fmt.Printf("go-flutter: failed to encode success envelope for event channel '%s', error: %v\n", es.eventChannel.channelName, err)
 	}
-	err = es.eventChannel.messenger.Send(es.eventChannel.channelName, binaryMsg)
-	if err != nil {
-		fmt.Printf("go-flutter: failed to send Error message on event channel '%s', error: %v\n", es.eventChannel.channelName, err)
-	}
+	es.send(binaryMsg, "Error")
 }
 
 // EndOfStream consumes end of stream.
 func (es *EventSink) EndOfStream() {
 	es.Lock()
 	defer es.Unlock()
-	if es.hasEnded || es != es.eventChannel.activeSink {
+	if !es.isActive() {
 		return
 	}
 	es.hasEnded = true
 
-	err := es.eventChannel.messenger.Send(es.eventChannel.channelName, nil)
+	es.send(nil, "EndOfStream")
+}
+
+// isActive reports whether events may be sent through this sink. Buffered
+// channels accept events before Flutter starts listening.
+func (es *EventSink) isActive() bool {
+	if es.hasEnded {
+		return false
+	}
+	return es.eventChannel.buffer != nil || es == es.eventChannel.getActiveSink()
+}
+
+func (es *EventSink) send(binaryMsg []byte, kind string) {
+	if es.eventChannel.buffer != nil {
+		es.eventChannel.buffer.push(binaryMsg, es.generation)
+		return
+	}
+	err := es.eventChannel.messenger.Send(es.eventChannel.channelName, binaryMsg)
 	if err != nil {
-		fmt.Printf("go-flutter: failed to send EndOfStream message on event channel '%s', error: %v\n", es.eventChannel.channelName, err)
+		fmt.Printf("go-flutter: failed to send %s message on event channel '%s', error: %v\n", kind, es.eventChannel.channelName, err)
+	}
+}
+
+// streamSink returns a sink bound to the current stream of a buffered
+// channel: its events are dropped once the stream is cancelled, instead of
+// being queued for the next listener. Other channels have a sink per stream,
+// streamSink returns es.
+func (es *EventSink) streamSink() *EventSink {
+	if es.eventChannel.buffer == nil {
+		return es
+	}
+	return &EventSink{
+		eventChannel: es.eventChannel,
+		generation:   es.eventChannel.buffer.currentGeneration(),
 	}
 }
@@ -0,0 +1,219 @@
+// Package eventsource provides timers and file descriptor readiness sources
+// whose callbacks are run by the platform event loop, on the platform thread.
+//
+// Plugins watching sockets, inotify or D-Bus file descriptors, or running
+// periodic work, can use Sources instead of their own goroutines and locking.
+// Plugins receive the Sources of the application by implementing the
+// flutter.PluginEventLoop interface.
+//
+// The package doesn't depend on GLFW or on the engine: an event loop drives
+// Sources by calling RunTimers and NextDeadline, and by running the functions
+// passed to the post function given to New.
+package eventsource
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Events is a set of file descriptor readiness events.
+type Events uint32
+
+const (
+	// Readable is set when the file descriptor can be read without blocking.
+	Readable Events = 1 << iota
+	// Writable is set when the file descriptor can be written without
+	// blocking.
+	Writable
+	// Hangup is set when the peer closed its end. It is always reported.
+	Hangup
+	// Error is set when an error condition happened on the file descriptor.
+	// It is always reported.
+	Error
+)
+
+// Sources holds the timers and file descriptor sources of an event loop.
+type Sources struct {
+	post   func(func())
+	wakeup func()
+	now    func() time.Time
+
+	lock   sync.Mutex
+	timers timerHeap
+	// timerSeq orders the timers expiring at the same time.
+	timerSeq uint64
+	fds      map[int]*FDSource
+	poller   *poller
+	// pollErr is the error which stopped the poller, AddFD fails once set.
+	pollErr error
+	closed  bool
+}
+
+// New creates Sources for an event loop. post must schedule a function to be
+// run on the event loop thread and wake the loop up. wakeup must make the
+// loop call NextDeadline again, it is called when a timer is added. Neither
+// must block.
+func New(post func(func()), wakeup func()) *Sources {
+	return &Sources{
+		post:   post,
+		wakeup: wakeup,
+		now:    time.Now,
+		fds:    make(map[int]*FDSource),
+	}
+}
+
+// SetNow sets the function returning the current time, time.Now by default.
+// It's used to drive the timers with a virtual clock, it must be called
+// before any timer is added.
+func (s *Sources) SetNow(now func() time.Time) {
+	s.now = now
+}
+
+// Close removes all the sources and stops watching file descriptors. The
+// file descriptors aren't closed.
+func (s *Sources) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for _, t := range s.timers {
+		t.index = -1
+		t.stopped = true
+	}
+	s.timers = nil
+	for fd, f := range s.fds {
+		f.removed = true
+		delete(s.fds, fd)
+	}
+	if s.poller != nil {
+		s.poller.close()
+		s.poller = nil
+	}
+}
+
+// Timer is a timer registered with AddTimer.
+type Timer struct {
+	sources  *Sources
+	fireTime time.Time
+	interval time.Duration
+	repeat   bool
+	callback func()
+	seq      uint64
+	stopped  bool
+	// index in the timerHeap, -1 when the timer isn't scheduled.
+	index int
+}
+
+// AddTimer calls callback on the event loop thread after delay. When repeat
+// is set, callback is then called every delay until the timer is stopped.
+// Repeating timers which fall behind skip the missed ticks.
+func (s *Sources) AddTimer(delay time.Duration, repeat bool, callback func()) *Timer {
+	t := &Timer{
+		sources:  s,
+		fireTime: s.now().Add(delay),
+		interval: delay,
+		repeat:   repeat && delay > 0,
+		callback: callback,
+		index:    -1,
+	}
+	s.lock.Lock()
+	if s.closed {
+		t.stopped = true
+		s.lock.Unlock()
+		return t
+	}
+	s.timerSeq++
+	t.seq = s.timerSeq
+	heap.Push(&s.timers, t)
+	s.lock.Unlock()
+	s.wakeup()
+	return t
+}
+
+// Stop stops the timer. The callback isn't called after Stop returns, unless
+// Stop is called from another goroutine while the callback is running.
+func (t *Timer) Stop() {
+	s := t.sources
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t.stopped = true
+	if t.index >= 0 {
+		heap.Remove(&s.timers, t.index)
+	}
+}
+
+// NextDeadline returns the time at which the next timer expires. ok is false
+// when no timer is scheduled.
+func (s *Sources) NextDeadline() (deadline time.Time, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.timers) == 0 {
+		return time.Time{}, false
+	}
+	return s.timers[0].fireTime, true
+}
+
+// RunTimers calls the callbacks of the timers expired at now, in the order of
+// their expiry. It must be called on the event loop thread.
+func (s *Sources) RunTimers(now time.Time) {
+	var expired []*Timer
+	s.lock.Lock()
+	for len(s.timers) > 0 && !s.timers[0].fireTime.After(now) {
+		t := heap.Pop(&s.timers).(*Timer)
+		expired = append(expired, t)
+		if t.repeat {
+			t.fireTime = t.fireTime.Add(t.interval)
+			if !t.fireTime.After(now) {
+				t.fireTime = now.Add(t.interval)
+			}
+			heap.Push(&s.timers, t)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, t := range expired {
+		// a previous callback may have stopped the timer.
+		s.lock.Lock()
+		stopped := t.stopped
+		s.lock.Unlock()
+		if !stopped {
+			t.callback()
+		}
+	}
+}
+
+// timerHeap implements heap.Interface, ordered by fire time.
+type timerHeap []*Timer
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].fireTime.Equal(h[j].fireTime) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].fireTime.Before(h[j].fireTime)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*Timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	t.index = -1
+	*h = old[:n-1]
+	return t
+}
@@ -0,0 +1,213 @@
+package eventsource
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testLoop imitates the platform event loop: posted functions are run on the
+// goroutine calling runFor.
+type testLoop struct {
+	tasks   chan func()
+	wakeups chan struct{}
+}
+
+func newTestLoop() *testLoop {
+	return &testLoop{
+		tasks:   make(chan func(), 64),
+		wakeups: make(chan struct{}, 64),
+	}
+}
+
+func (l *testLoop) post(f func()) { l.tasks <- f }
+
+func (l *testLoop) wakeup() {
+	select {
+	case l.wakeups <- struct{}{}:
+	default:
+	}
+}
+
+// runFor runs the posted functions until done returns true or the timeout
+// expires.
+func (l *testLoop) runFor(t *testing.T, timeout time.Duration, done func() bool) {
+	deadline := time.After(timeout)
+	for !done() {
+		select {
+		case f := <-l.tasks:
+			f()
+		case <-deadline:
+			t.Fatal("timeout waiting for the event loop")
+		}
+	}
+}
+
+func TestTimersOrder(t *testing.T) {
+	l := newTestLoop()
+	s := New(l.post, l.wakeup)
+	defer s.Close()
+
+	var got []int
+	s.AddTimer(30*time.Millisecond, false, func() { got = append(got, 3) })
+	s.AddTimer(10*time.Millisecond, false, func() { got = append(got, 1) })
+	s.AddTimer(20*time.Millisecond, false, func() { got = append(got, 2) })
+	stopped := s.AddTimer(15*time.Millisecond, false, func() { got = append(got, -1) })
+	stopped.Stop()
+
+	if len(l.wakeups) != 4 {
+		t.Fatalf("expected a wakeup per added timer, got %d", len(l.wakeups))
+	}
+
+	deadline, ok := s.NextDeadline()
+	if !ok {
+		t.Fatal("expected a deadline")
+	}
+	s.RunTimers(deadline.Add(-time.Millisecond))
+	if len(got) != 0 {
+		t.Fatalf("timers ran before their deadline: %v", got)
+	}
+	s.RunTimers(time.Now().Add(time.Second))
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("unexpected timers order: %v", got)
+	}
+	if _, ok := s.NextDeadline(); ok {
+		t.Fatal("expected no deadline once all timers expired")
+	}
+}
+
+func TestRepeatingTimer(t *testing.T) {
+	l := newTestLoop()
+	s := New(l.post, l.wakeup)
+	defer s.Close()
+
+	count := 0
+	var timer *Timer
+	timer = s.AddTimer(10*time.Millisecond, true, func() {
+		count++
+		if count == 3 {
+			timer.Stop()
+		}
+	})
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		s.RunTimers(now.Add(time.Duration(i) * 10 * time.Millisecond))
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 ticks before Stop, got %d", count)
+	}
+
+	// a timer falling behind skips the missed ticks.
+	count = 0
+	timer = s.AddTimer(10*time.Millisecond, true, func() { count++ })
+	s.RunTimers(now.Add(time.Second))
+	s.RunTimers(now.Add(time.Second + 5*time.Millisecond))
+	if count != 1 {
+		t.Fatalf("expected a single tick for missed ticks, got %d", count)
+	}
+	deadline, _ := s.NextDeadline()
+	if want := now.Add(time.Second + 10*time.Millisecond); !deadline.Equal(want) {
+		t.Fatalf("expected the next tick at %v, got %v", want, deadline)
+	}
+}
+
+func TestFDSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file descriptor sources aren't supported on windows")
+	}
+	l := newTestLoop()
+	s := New(l.post, l.wakeup)
+	defer s.Close()
+
+	var pipe [2]int
+	if err := syscall.Pipe(pipe[:]); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(pipe[0])
+	defer syscall.Close(pipe[1])
+
+	var read []byte
+	var source *FDSource
+	source, err := s.AddFD(pipe[0], Readable, func(ready Events) {
+		if ready&Readable == 0 {
+			t.Errorf("expected a readable event, got %v", ready)
+		}
+		// read one byte at a time to check readiness is level-triggered.
+		buf := make([]byte, 1)
+		n, _ := syscall.Read(pipe[0], buf)
+		read = append(read, buf[:n]...)
+		if len(read) == 3 {
+			source.Remove()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddFD(pipe[0], Readable, func(Events) {}); err == nil {
+		t.Fatal("expected an error when registering a file descriptor twice")
+	}
+
+	syscall.Write(pipe[1], []byte("abc"))
+	l.runFor(t, time.Second, func() bool { return len(read) == 3 })
+	if string(read) != "abc" {
+		t.Fatalf("unexpected data read: %q", read)
+	}
+
+	// the source is removed, no callback is posted anymore.
+	syscall.Write(pipe[1], []byte("d"))
+	select {
+	case f := <-l.tasks:
+		f()
+		if len(read) != 3 {
+			t.Fatal("callback called after Remove")
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClosedSources(t *testing.T) {
+	l := newTestLoop()
+	s := New(l.post, l.wakeup)
+	s.AddTimer(0, false, func() { t.Error("timer ran after Close") })
+	s.Close()
+	s.RunTimers(time.Now().Add(time.Second))
+	if _, err := s.AddFD(0, Readable, func(Events) {}); err == nil {
+		t.Fatal("expected an error when adding a source after Close")
+	}
+}
+
+func TestPollFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file descriptor sources aren't supported on windows")
+	}
+	l := newTestLoop()
+	s := New(l.post, l.wakeup)
+	defer s.Close()
+
+	var pipe [2]int
+	if err := syscall.Pipe(pipe[:]); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(pipe[0])
+	defer syscall.Close(pipe[1])
+
+	var events Events
+	source, err := s.AddFD(pipe[0], Readable, func(ready Events) {
+		events = ready
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Remove()
+
+	s.pollFailed(errors.New("poll failed"))
+	l.runFor(t, time.Second, func() bool { return events != 0 })
+	if events != Error {
+		t.Fatalf("expected an error event, got %v", events)
+	}
+	if _, err := s.AddFD(pipe[1], Writable, func(Events) {}); err == nil {
+		t.Fatal("expected an error when adding a source after a poll failure")
+	}
+}
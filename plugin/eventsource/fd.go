@@ -0,0 +1,139 @@
+package eventsource
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// FDSource is a file descriptor source registered with AddFD.
+type FDSource struct {
+	sources  *Sources
+	fd       int
+	events   Events
+	callback func(ready Events)
+	// busy is set while the callback is pending, the file descriptor isn't
+	// polled until the callback returns.
+	busy    bool
+	removed bool
+}
+
+// AddFD calls callback on the event loop thread when fd is ready for one of
+// the given events. Readiness is level-triggered: callback is called again
+// after it returns, as long as fd is ready. Hangup and Error are reported
+// whatever the events, the callback should then remove the source.
+//
+// Only one source may be registered per file descriptor. When polling the
+// file descriptors keeps failing, the sources get an Error event and AddFD
+// returns an error from then on.
+func (s *Sources) AddFD(fd int, events Events, callback func(ready Events)) (*FDSource, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil, errors.New("event sources are closed")
+	}
+	if s.pollErr != nil {
+		return nil, errors.Wrap(s.pollErr, "file descriptors can't be watched")
+	}
+	if _, ok := s.fds[fd]; ok {
+		return nil, errors.Errorf("file descriptor %d is already registered", fd)
+	}
+	if s.poller == nil {
+		p, err := newPoller(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start the file descriptor poller")
+		}
+		s.poller = p
+	}
+	f := &FDSource{
+		sources:  s,
+		fd:       fd,
+		events:   events,
+		callback: callback,
+	}
+	s.fds[fd] = f
+	s.poller.wake()
+	return f, nil
+}
+
+// Remove stops watching the file descriptor, which isn't closed. The callback
+// isn't called after Remove returns, unless Remove is called from another
+// goroutine while the callback is running.
+func (f *FDSource) Remove() {
+	s := f.sources
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if f.removed {
+		return
+	}
+	f.removed = true
+	delete(s.fds, f.fd)
+	if s.poller != nil {
+		s.poller.wake()
+	}
+}
+
+// ready is called by the poller when the file descriptor is ready.
+func (f *FDSource) ready(events Events) {
+	s := f.sources
+	s.lock.Lock()
+	if f.removed {
+		s.lock.Unlock()
+		return
+	}
+	f.busy = true
+	s.lock.Unlock()
+
+	s.post(func() {
+		s.lock.Lock()
+		removed := f.removed
+		s.lock.Unlock()
+		if !removed {
+			f.callback(events)
+		}
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		f.busy = false
+		if s.poller != nil {
+			s.poller.wake()
+		}
+	})
+}
+
+// pollSet returns the sources to poll.
+func (s *Sources) pollSet() (sources []*FDSource, closed bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, f := range s.fds {
+		if !f.busy {
+			sources = append(sources, f)
+		}
+	}
+	return sources, s.closed
+}
+
+// pollFailed is called when the poller stops on err. The file descriptor
+// sources get an Error event, and AddFD fails from then on.
+func (s *Sources) pollFailed(err error) {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return
+	}
+	s.pollErr = err
+	if s.poller != nil {
+		s.poller.close()
+		s.poller = nil
+	}
+	sources := make([]*FDSource, 0, len(s.fds))
+	for _, f := range s.fds {
+		sources = append(sources, f)
+	}
+	s.lock.Unlock()
+
+	fmt.Printf("go-flutter: %v\n", err)
+	for _, f := range sources {
+		f.ready(Error)
+	}
+}
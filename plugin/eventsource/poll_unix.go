@@ -0,0 +1,140 @@
+// +build !windows
+
+package eventsource
+
+// #include <poll.h>
+import "C"
+
+import (
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// poll is retried pollRetries times on failure, waiting pollRetryDelay,
+// doubled after each attempt.
+const (
+	pollRetries    = 5
+	pollRetryDelay = 10 * time.Millisecond
+)
+
+// poller waits for the readiness of the file descriptor sources on its own
+// goroutine. It's woken up through a pipe when the sources change.
+type poller struct {
+	sources *Sources
+	r, w    int
+	closed  uint32
+}
+
+func newPoller(s *Sources) (*poller, error) {
+	var fds [2]int
+	err := syscall.Pipe(fds[:])
+	if err != nil {
+		return nil, err
+	}
+	for _, fd := range fds {
+		syscall.CloseOnExec(fd)
+		err = syscall.SetNonblock(fd, true)
+		if err != nil {
+			syscall.Close(fds[0])
+			syscall.Close(fds[1])
+			return nil, err
+		}
+	}
+	p := &poller{sources: s, r: fds[0], w: fds[1]}
+	go p.run()
+	return p, nil
+}
+
+// wake makes the poller read the sources again.
+func (p *poller) wake() {
+	// the pipe is non-blocking, when it is full the poller is awake anyway.
+	syscall.Write(p.w, []byte{0})
+}
+
+func (p *poller) close() {
+	atomic.StoreUint32(&p.closed, 1)
+	p.wake()
+}
+
+func (p *poller) run() {
+	buf := make([]byte, 64)
+	retries := 0
+	for {
+		sources, closed := p.sources.pollSet()
+		if closed || atomic.LoadUint32(&p.closed) == 1 {
+			syscall.Close(p.r)
+			syscall.Close(p.w)
+			return
+		}
+
+		fds := make([]C.struct_pollfd, len(sources)+1)
+		fds[0].fd = C.int(p.r)
+		fds[0].events = C.POLLIN
+		for i, f := range sources {
+			fds[i+1].fd = C.int(f.fd)
+			fds[i+1].events = toPollEvents(f.events)
+		}
+
+		_, err := C.poll(&fds[0], C.nfds_t(len(fds)), -1)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+			if retries < pollRetries {
+				time.Sleep(pollRetryDelay << uint(retries))
+				retries++
+				continue
+			}
+			p.sources.pollFailed(errors.Wrap(err, "failed to poll file descriptors"))
+			syscall.Close(p.r)
+			syscall.Close(p.w)
+			return
+		}
+		retries = 0
+
+		if fds[0].revents != 0 {
+			for {
+				n, _ := syscall.Read(p.r, buf)
+				if n < len(buf) {
+					break
+				}
+			}
+		}
+		for i, f := range sources {
+			if fds[i+1].revents != 0 {
+				f.ready(fromPollEvents(fds[i+1].revents))
+			}
+		}
+	}
+}
+
+func toPollEvents(events Events) C.short {
+	var e C.short
+	if events&Readable != 0 {
+		e |= C.POLLIN
+	}
+	if events&Writable != 0 {
+		e |= C.POLLOUT
+	}
+	return e
+}
+
+func fromPollEvents(revents C.short) Events {
+	var e Events
+	if revents&C.POLLIN != 0 {
+		e |= Readable
+	}
+	if revents&C.POLLOUT != 0 {
+		e |= Writable
+	}
+	if revents&C.POLLHUP != 0 {
+		e |= Hangup
+	}
+	if revents&(C.POLLERR|C.POLLNVAL) != 0 {
+		e |= Error
+	}
+	return e
+}
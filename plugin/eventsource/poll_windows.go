@@ -0,0 +1,17 @@
+package eventsource
+
+import (
+	"github.com/pkg/errors"
+)
+
+// poller isn't implemented on windows, where sockets and handles can't be
+// waited on with poll.
+type poller struct{}
+
+func newPoller(s *Sources) (*poller, error) {
+	return nil, errors.New("file descriptor sources aren't supported on windows")
+}
+
+func (p *poller) wake() {}
+
+func (p *poller) close() {}
@@ -3,6 +3,7 @@ package plugin
 import (
 	"errors"
 	"sync"
+	"time"
 )
 
 // TestingBinaryMessenger implements the BinaryMessenger interface for testing
@@ -28,7 +29,7 @@ func NewTestingBinaryMessenger() *TestingBinaryMessenger {
 }
 
 func (t *TestingBinaryMessenger) Send(channel string, message []byte) (err error) {
-	err = t.Send(channel, message)
+	_, err = t.SendWithReply(channel, message)
 	return err
 }
 
@@ -43,9 +44,7 @@ func (t *TestingBinaryMessenger) SendWithReply(channel string, message []byte) (
 		return nil, errors.New("no handler set")
 	}
 
-	r := mockResponseSender{}
-	handler(message, &r)
-	return r.binaryReply, nil
+	return callMockHandler(handler, message, false)
 }
 
 // SetMessageHandler registers a binary message handler on given channel.
@@ -67,9 +66,20 @@ func (t *TestingBinaryMessenger) MockSend(channel string, message []byte) (reply
 		return nil, errors.New("no handler set")
 	}
 
-	r := mockResponseSender{}
-	handler(message, &r)
-	return r.binaryReply, nil
+	return callMockHandler(handler, message, false)
+}
+
+// MockSendAndWait is like MockSend, but waits for replies sent
+// asynchronously by the handler.
+func (t *TestingBinaryMessenger) MockSendAndWait(channel string, message []byte) (reply []byte, err error) {
+	t.channelHandlersLock.Lock()
+	handler := t.channelHandlers[channel]
+	t.channelHandlersLock.Unlock()
+	if handler == nil {
+		return nil, errors.New("no handler set")
+	}
+
+	return callMockHandler(handler, message, true)
 }
 
 // MockSetChannelHandler imitates a handler set at the other end of the
@@ -80,10 +90,30 @@ func (t *TestingBinaryMessenger) MockSetChannelHandler(channel string, handler C
 	t.mockChannelHandlersLock.Unlock()
 }
 
+// callMockHandler calls the handler and returns its reply. If wait is true,
+// the reply may be sent from another goroutine after the handler returns.
+func callMockHandler(handler ChannelHandlerFunc, message []byte, wait bool) (reply []byte, err error) {
+	r := mockResponseSender{binaryReply: make(chan []byte, 1)}
+	handler(message, &r)
+	if !wait {
+		select {
+		case reply = <-r.binaryReply:
+		default:
+		}
+		return reply, nil
+	}
+	select {
+	case reply = <-r.binaryReply:
+		return reply, nil
+	case <-time.After(time.Second):
+		return nil, errors.New("no reply")
+	}
+}
+
 type mockResponseSender struct {
-	binaryReply []byte
+	binaryReply chan []byte
 }
 
 func (m *mockResponseSender) Send(binaryReply []byte) {
-	m.binaryReply = binaryReply
+	m.binaryReply <- binaryReply
 }
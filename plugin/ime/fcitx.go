@@ -0,0 +1,229 @@
+package ime
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/dbus"
+)
+
+// The D-Bus frontend of Fcitx 5, the service names are tried in order.
+var fcitxServices = []string{"org.fcitx.Fcitx5", "org.freedesktop.portal.Fcitx"}
+
+const (
+	fcitxPath         = dbus.ObjectPath("/org/freedesktop/portal/inputmethod")
+	fcitxInputMethod  = "org.fcitx.Fcitx.InputMethod1"
+	fcitxInputContext = "org.fcitx.Fcitx.InputContext1"
+)
+
+// Fcitx capability flags of the input context
+const (
+	fcitxCapabilityPreedit          uint64 = 1 << 1
+	fcitxCapabilityFormattedPreedit uint64 = 1 << 4
+)
+
+// fcitxKeyTimeout is how long a key event waits for Fcitx, the UI is blocked
+// meanwhile.
+const fcitxKeyTimeout = 500 * time.Millisecond
+
+// Fcitx is the Backend of the Fcitx 5 input method framework, over D-Bus.
+// The preedit text is displayed by the application.
+type Fcitx struct {
+	conn    *dbus.Conn
+	service string
+	path    dbus.ObjectPath
+
+	lock    sync.Mutex
+	handler Handler
+}
+
+var _ Backend = &Fcitx{}
+
+// NewFcitx creates an input context of the Fcitx input method running on
+// the session bus. The cause of the error is ErrUnavailable if Fcitx isn't
+// running.
+func NewFcitx() (*Fcitx, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, errors.Wrap(ErrUnavailable, err.Error())
+	}
+	f, err := NewFcitxConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewFcitxConn creates an input context of the Fcitx input method, on a
+// connection to a bus. The connection is closed with the Fcitx backend.
+func NewFcitxConn(conn *dbus.Conn) (*Fcitx, error) {
+	f := &Fcitx{conn: conn}
+	conn.SetSignalHandler(f.handleSignal)
+	err := conn.AddMatch(fmt.Sprintf("type='signal',interface='%s'", fcitxInputContext))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to receive the Fcitx signals")
+	}
+
+	args := []interface{}{
+		[]interface{}{"program", filepath.Base(os.Args[0])},
+	}
+	var reply []interface{}
+	for _, service := range fcitxServices {
+		reply, err = conn.Call(&dbus.Call{
+			Destination: service,
+			Path:        fcitxPath,
+			Interface:   fcitxInputMethod,
+			Member:      "CreateInputContext",
+			Signature:   "a(ss)",
+			Args:        []interface{}{args},
+		}, dbus.DefaultTimeout)
+		if dbusErr, ok := err.(*dbus.Error); ok && dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown" {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create the Fcitx input context")
+		}
+		f.service = service
+		break
+	}
+	if f.service == "" {
+		return nil, ErrUnavailable
+	}
+	if len(reply) != 2 {
+		return nil, errors.Errorf("invalid Fcitx input context %v", reply)
+	}
+	path, ok := reply[0].(dbus.ObjectPath)
+	if !ok {
+		return nil, errors.Errorf("invalid Fcitx input context %v", reply)
+	}
+	f.lock.Lock()
+	f.path = path
+	f.lock.Unlock()
+
+	err = f.call("SetCapability", "t", fcitxCapabilityPreedit|fcitxCapabilityFormattedPreedit)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetHandler satisfies Backend.
+func (f *Fcitx) SetHandler(h Handler) {
+	f.lock.Lock()
+	f.handler = h
+	f.lock.Unlock()
+}
+
+// FocusIn satisfies Backend.
+func (f *Fcitx) FocusIn() error {
+	return f.call("FocusIn", "")
+}
+
+// FocusOut satisfies Backend.
+func (f *Fcitx) FocusOut() error {
+	return f.call("FocusOut", "")
+}
+
+// Reset satisfies Backend.
+func (f *Fcitx) Reset() error {
+	return f.call("Reset", "")
+}
+
+// SetCursorRect satisfies Backend.
+func (f *Fcitx) SetCursorRect(rect image.Rectangle) error {
+	return f.call("SetCursorRect", "iiii",
+		int32(rect.Min.X), int32(rect.Min.Y), int32(rect.Dx()), int32(rect.Dy()))
+}
+
+// ProcessKey satisfies Backend.
+func (f *Fcitx) ProcessKey(event KeyEvent) (bool, error) {
+	keysym := Keysym(event)
+	if keysym == 0 {
+		return false, nil
+	}
+	reply, err := f.conn.Call(&dbus.Call{
+		Destination: f.service,
+		Path:        f.path,
+		Interface:   fcitxInputContext,
+		Member:      "ProcessKeyEvent",
+		Signature:   "uuubu",
+		Args: []interface{}{
+			keysym,
+			uint32(event.Scancode),
+			X11State(event),
+			event.Release,
+			uint32(time.Now().UnixNano() / int64(time.Millisecond)),
+		},
+	}, fcitxKeyTimeout)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to process the key with Fcitx")
+	}
+	if len(reply) != 1 {
+		return false, errors.Errorf("invalid reply to the Fcitx key event %v", reply)
+	}
+	handled, _ := reply[0].(bool)
+	return handled, nil
+}
+
+// Close destroys the input context and closes the D-Bus connection.
+func (f *Fcitx) Close() error {
+	err := f.call("DestroyIC", "")
+	f.conn.Close()
+	return err
+}
+
+// call calls a method of the input context, without waiting for its reply.
+func (f *Fcitx) call(member string, signature dbus.Signature, args ...interface{}) error {
+	err := f.conn.CallNoReply(&dbus.Call{
+		Destination: f.service,
+		Path:        f.path,
+		Interface:   fcitxInputContext,
+		Member:      member,
+		Signature:   signature,
+		Args:        args,
+	})
+	return errors.Wrapf(err, "failed to call the Fcitx method %s", member)
+}
+
+func (f *Fcitx) handleSignal(m *dbus.Message) {
+	f.lock.Lock()
+	path, handler := f.path, f.handler
+	f.lock.Unlock()
+	if m.Path != path || m.Interface != fcitxInputContext || handler == nil {
+		return
+	}
+
+	switch m.Member {
+	case "CommitString":
+		if m.Signature != "s" {
+			return
+		}
+		handler.Commit(m.Body[0].(string))
+	case "UpdateFormattedPreedit":
+		if m.Signature != "a(si)i" {
+			return
+		}
+		// The preedit is a list of text segments with their format.
+		var preedit Preedit
+		for _, segment := range m.Body[0].([]interface{}) {
+			preedit.Text += segment.([]interface{})[0].(string)
+		}
+		preedit.Cursor = int(m.Body[1].(int32))
+		if preedit.Cursor < 0 || preedit.Cursor > len(preedit.Text) {
+			// the cursor is hidden
+			preedit.Cursor = len(preedit.Text)
+		}
+		handler.Preedit(preedit)
+	default:
+		// ForwardKey and DeleteSurroundingText aren't supported, the
+		// capabilities of the input context don't include the surrounding
+		// text.
+	}
+}
@@ -0,0 +1,68 @@
+// Package ime defines the input method backends composing text for the
+// text input, e.g., to type CJK text, and implements the Fcitx backend.
+package ime
+
+import (
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnavailable is returned when no input method is running.
+var ErrUnavailable = errors.New("input method unavailable")
+
+// KeyEvent is a key event passed to the input method.
+type KeyEvent struct {
+	// Key, Scancode and Mods are the key, scancode and modifier bits of the
+	// GLFW key event.
+	Key      int
+	Scancode int
+	Mods     int
+	// Text is the character produced by the key, empty for the keys which
+	// don't produce a character.
+	Text    string
+	Release bool
+}
+
+// Preedit is the text being composed, displayed in place of the selection
+// until it's committed.
+type Preedit struct {
+	Text string
+	// Cursor is the byte offset of the cursor in Text.
+	Cursor int
+}
+
+// Handler receives the composition updates of a Backend. It's called on a
+// goroutine of the backend.
+type Handler interface {
+	// Preedit is called when the text being composed changes, with an empty
+	// Preedit when the composition is cancelled.
+	Preedit(preedit Preedit)
+	// Commit is called with the composed text, which replaces the text being
+	// composed.
+	Commit(text string)
+}
+
+// Backend is an input method. The key events are passed to the input method
+// while a text input has the focus, the keys it handles are part of the
+// composition and must not be handled by the application.
+type Backend interface {
+	// SetHandler sets the Handler of the composition updates.
+	SetHandler(h Handler)
+	// FocusIn tells the input method that a text input got the focus.
+	FocusIn() error
+	// FocusOut tells the input method that the text input lost the focus.
+	FocusOut() error
+	// Reset cancels the composition, e.g., when the text input content is
+	// changed by the application.
+	Reset() error
+	// SetCursorRect sets the rectangle of the cursor or of the text being
+	// composed, in screen coordinates, next to which the candidates of the
+	// input method are displayed.
+	SetCursorRect(rect image.Rectangle) error
+	// ProcessKey passes a key event to the input method, and reports whether
+	// the input method handled it.
+	ProcessKey(event KeyEvent) (handled bool, err error)
+	// Close releases the resources of the input method.
+	Close() error
+}
@@ -0,0 +1,165 @@
+package ime
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/dbus"
+	"github.com/go-flutter-desktop/go-flutter/internal/dbus/dbustest"
+)
+
+func TestKeysym(t *testing.T) {
+	for _, test := range []struct {
+		event  KeyEvent
+		keysym uint32
+	}{
+		{KeyEvent{Key: 'A', Text: "a"}, 'a'},
+		{KeyEvent{Key: 'A', Text: "A", Mods: 0x1}, 'A'},
+		{KeyEvent{Key: 'A'}, 'a'},
+		{KeyEvent{Key: ' ', Text: " "}, ' '},
+		{KeyEvent{Key: 'E', Text: "é"}, 0xe9},
+		{KeyEvent{Key: 'Q', Text: "й"}, 0x01000439},
+		{KeyEvent{Key: 257}, 0xff0d},
+		{KeyEvent{Key: 321, Text: "1"}, 0xffb1},
+		{KeyEvent{Key: 291}, 0xffbf},
+		{KeyEvent{Key: -1}, 0},
+	} {
+		if keysym := Keysym(test.event); keysym != test.keysym {
+			t.Errorf("expected keysym %#x for %+v, got %#x", test.keysym, test.event, keysym)
+		}
+	}
+
+	state := X11State(KeyEvent{Mods: 0x1 | 0x4 | 0x10})
+	if state != x11ShiftMask|x11Mod1Mask|x11LockMask {
+		t.Errorf("unexpected X11 state %#x", state)
+	}
+}
+
+type recordingHandler chan interface{}
+
+func (h recordingHandler) Preedit(preedit Preedit) { h <- preedit }
+func (h recordingHandler) Commit(text string)      { h <- text }
+
+func (h recordingHandler) next(t *testing.T) interface{} {
+	select {
+	case update := <-h:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("no update received")
+		return nil
+	}
+}
+
+func TestFcitx(t *testing.T) {
+	const inputContext = dbus.ObjectPath("/org/freedesktop/portal/inputcontext/1")
+	bus, client := dbustest.New()
+	defer bus.Close()
+	bus.Handle(fcitxInputMethod, "CreateInputContext", func(call *dbus.Message) (dbus.Signature, []interface{}, error) {
+		if call.Destination != fcitxServices[0] {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}
+		}
+		return "oay", []interface{}{inputContext, []byte{1, 2}}, nil
+	})
+	bus.Handle(fcitxInputContext, "ProcessKeyEvent", func(call *dbus.Message) (dbus.Signature, []interface{}, error) {
+		switch call.Body[0].(uint32) {
+		case 'n':
+			bus.Emit(inputContext, fcitxInputContext, "UpdateFormattedPreedit", "a(si)i",
+				[]interface{}{[]interface{}{"n", int32(0)}, []interface{}{"i", int32(8)}}, int32(2))
+			return "b", []interface{}{true}, nil
+		case ' ':
+			bus.Emit(inputContext, fcitxInputContext, "CommitString", "s", "你")
+			bus.Emit(inputContext, fcitxInputContext, "UpdateFormattedPreedit", "a(si)i", []interface{}{}, int32(-1))
+			return "b", []interface{}{true}, nil
+		}
+		return "b", []interface{}{false}, nil
+	})
+
+	conn, err := dbus.NewConn(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFcitxConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	handler := make(recordingHandler, 4)
+	f.SetHandler(handler)
+
+	err = f.FocusIn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.SetCursorRect(image.Rect(10, 20, 12, 40))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled, err := f.ProcessKey(KeyEvent{Key: 'N', Text: "n", Scancode: 57})
+	if err != nil || !handled {
+		t.Fatalf("expected the key to be handled, got %v, %v", handled, err)
+	}
+	if update := handler.next(t); !reflect.DeepEqual(update, Preedit{Text: "ni", Cursor: 2}) {
+		t.Fatalf("unexpected update %v", update)
+	}
+
+	handled, err = f.ProcessKey(KeyEvent{Key: ' ', Text: " "})
+	if err != nil || !handled {
+		t.Fatalf("expected the key to be handled, got %v, %v", handled, err)
+	}
+	if update := handler.next(t); update != "你" {
+		t.Fatalf("unexpected update %v", update)
+	}
+	if update := handler.next(t); !reflect.DeepEqual(update, Preedit{}) {
+		t.Fatalf("unexpected update %v", update)
+	}
+
+	handled, err = f.ProcessKey(KeyEvent{Key: 257})
+	if err != nil || handled {
+		t.Fatalf("expected the key to be unhandled, got %v, %v", handled, err)
+	}
+
+	var calls []*dbus.Message
+	var members []string
+	for _, call := range bus.Calls() {
+		if call.Path == inputContext {
+			calls = append(calls, call)
+			members = append(members, call.Member)
+		}
+	}
+	want := []string{"SetCapability", "FocusIn", "SetCursorRect", "ProcessKeyEvent", "ProcessKeyEvent", "ProcessKeyEvent"}
+	if !reflect.DeepEqual(members, want) {
+		t.Fatalf("expected the calls %v, got %v", want, members)
+	}
+	rect := calls[2].Body
+	if !reflect.DeepEqual(rect, []interface{}{int32(10), int32(20), int32(2), int32(20)}) {
+		t.Fatalf("unexpected cursor rectangle %v", rect)
+	}
+	key := calls[3].Body
+	if key[0] != uint32('n') || key[1] != uint32(57) || key[3] != false {
+		t.Fatalf("unexpected key event %v", key)
+	}
+}
+
+func TestFcitxUnavailable(t *testing.T) {
+	bus, client := dbustest.New()
+	defer bus.Close()
+	bus.Handle(fcitxInputMethod, "CreateInputContext", func(call *dbus.Message) (dbus.Signature, []interface{}, error) {
+		return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}
+	})
+	conn, err := dbus.NewConn(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = NewFcitxConn(conn)
+	if err != ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if len(bus.Calls()) != len(fcitxServices) {
+		t.Fatalf("expected every Fcitx service to be tried, got %d calls", len(bus.Calls()))
+	}
+}
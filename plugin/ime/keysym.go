@@ -0,0 +1,119 @@
+package ime
+
+// glfwKeysyms maps the GLFW keys which don't produce a character, and the
+// keypad keys, to their X11 keysym.
+var glfwKeysyms = map[int]uint32{
+	256: 0xff1b, // Escape
+	257: 0xff0d, // Return
+	258: 0xff09, // Tab
+	259: 0xff08, // BackSpace
+	260: 0xff63, // Insert
+	261: 0xffff, // Delete
+	262: 0xff53, // Right
+	263: 0xff51, // Left
+	264: 0xff54, // Down
+	265: 0xff52, // Up
+	266: 0xff55, // Page_Up
+	267: 0xff56, // Page_Down
+	268: 0xff50, // Home
+	269: 0xff57, // End
+	280: 0xffe5, // Caps_Lock
+	281: 0xff14, // Scroll_Lock
+	282: 0xff7f, // Num_Lock
+	283: 0xff61, // Print
+	284: 0xff13, // Pause
+	320: 0xffb0, // KP_0
+	321: 0xffb1, // KP_1
+	322: 0xffb2, // KP_2
+	323: 0xffb3, // KP_3
+	324: 0xffb4, // KP_4
+	325: 0xffb5, // KP_5
+	326: 0xffb6, // KP_6
+	327: 0xffb7, // KP_7
+	328: 0xffb8, // KP_8
+	329: 0xffb9, // KP_9
+	330: 0xffae, // KP_Decimal
+	331: 0xffaf, // KP_Divide
+	332: 0xffaa, // KP_Multiply
+	333: 0xffad, // KP_Subtract
+	334: 0xffab, // KP_Add
+	335: 0xff8d, // KP_Enter
+	336: 0xffbd, // KP_Equal
+	340: 0xffe1, // Shift_L
+	341: 0xffe3, // Control_L
+	342: 0xffe9, // Alt_L
+	343: 0xffeb, // Super_L
+	344: 0xffe2, // Shift_R
+	345: 0xffe4, // Control_R
+	346: 0xffea, // Alt_R
+	347: 0xffec, // Super_R
+	348: 0xff67, // Menu
+}
+
+// GLFW function keys, mapped to the consecutive keysyms of F1 to F25
+const (
+	glfwKeyF1  = 290
+	glfwKeyF25 = 314
+	keysymF1   = 0xffbe
+)
+
+// Keysym returns the X11 keysym of a key event, 0 when it's unknown.
+func Keysym(event KeyEvent) uint32 {
+	if keysym, ok := glfwKeysyms[event.Key]; ok {
+		return keysym
+	}
+	if event.Key >= glfwKeyF1 && event.Key <= glfwKeyF25 {
+		return keysymF1 + uint32(event.Key-glfwKeyF1)
+	}
+	runes := []rune(event.Text)
+	if len(runes) == 1 {
+		r := runes[0]
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			// Latin-1 keysyms are their code point
+			return uint32(r)
+		case r > 0xff:
+			return 0x01000000 | uint32(r)
+		}
+	}
+	// the printable GLFW keys are their uppercase ASCII character
+	if event.Key >= 'A' && event.Key <= 'Z' {
+		return uint32(event.Key - 'A' + 'a')
+	}
+	if event.Key >= 0x20 && event.Key < 0x7f {
+		return uint32(event.Key)
+	}
+	return 0
+}
+
+// X11 modifier masks
+const (
+	x11ShiftMask   = 1 << 0
+	x11LockMask    = 1 << 1
+	x11ControlMask = 1 << 2
+	x11Mod1Mask    = 1 << 3
+	x11Mod2Mask    = 1 << 4
+	x11Mod4Mask    = 1 << 6
+)
+
+// X11State returns the X11 modifier state of the GLFW modifier bits of a key
+// event: Alt is Mod1, Num Lock is Mod2 and Super is Mod4.
+func X11State(event KeyEvent) uint32 {
+	var state uint32
+	for _, mod := range []struct {
+		glfw int
+		x11  uint32
+	}{
+		{0x01, x11ShiftMask},
+		{0x02, x11ControlMask},
+		{0x04, x11Mod1Mask},
+		{0x08, x11Mod4Mask},
+		{0x10, x11LockMask},
+		{0x20, x11Mod2Mask},
+	} {
+		if event.Mods&mod.glfw != 0 {
+			state |= mod.x11
+		}
+	}
+	return state
+}
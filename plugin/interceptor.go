@@ -0,0 +1,164 @@
+package plugin
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MessageDirection tells whether a Message is sent by Flutter or by Go.
+type MessageDirection int
+
+const (
+	// IncomingMessage is a message sent by the Flutter application to a
+	// handler registered with SetChannelHandler.
+	IncomingMessage MessageDirection = iota
+	// OutgoingMessage is a message sent to the Flutter application with Send
+	// or SendWithReply.
+	OutgoingMessage
+)
+
+// String returns "incoming" or "outgoing".
+func (d MessageDirection) String() string {
+	if d == IncomingMessage {
+		return "incoming"
+	}
+	return "outgoing"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d MessageDirection) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *MessageDirection) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "incoming":
+		*d = IncomingMessage
+	case "outgoing":
+		*d = OutgoingMessage
+	default:
+		return errors.Errorf("invalid message direction '%s'", text)
+	}
+	return nil
+}
+
+// Message is a binary message passed through the MessageInterceptor's of a
+// messenger.
+type Message struct {
+	// ID identifies the message and its reply. IDs are unique per messenger.
+	ID        uint64
+	Direction MessageDirection
+	// Channel and Data may be rewritten by interceptors, the message is then
+	// delivered to the new channel.
+	Channel string
+	Data    []byte
+	// Time is when the message entered the interceptor chain. It can be used
+	// to measure the time taken to reply.
+	Time time.Time
+}
+
+// MessageInterceptor observes, rewrites or rejects the messages exchanged
+// between Flutter and Go, as well as their replies.
+type MessageInterceptor interface {
+	// InterceptMessage is called before a message is delivered. Returning an
+	// error rejects the message: outgoing messages are not sent and the error
+	// is returned by Send or SendWithReply, incoming messages are answered
+	// with a nil reply, which triggers the dart MissingPluginException.
+	InterceptMessage(message *Message) error
+	// InterceptReply is called with the reply to an accepted message, before
+	// it is delivered. The returned reply replaces the original one.
+	InterceptReply(message *Message, binaryReply []byte) []byte
+}
+
+// MessageInterceptorFuncs is a MessageInterceptor built from functions. A nil
+// function lets messages, or replies, through unchanged.
+type MessageInterceptorFuncs struct {
+	Message func(message *Message) error
+	Reply   func(message *Message, binaryReply []byte) []byte
+}
+
+var _ MessageInterceptor = MessageInterceptorFuncs{} // compile-time type check
+
+// InterceptMessage calls f.Message, if set.
+func (f MessageInterceptorFuncs) InterceptMessage(message *Message) error {
+	if f.Message == nil {
+		return nil
+	}
+	return f.Message(message)
+}
+
+// InterceptReply calls f.Reply, if set.
+func (f MessageInterceptorFuncs) InterceptReply(message *Message, binaryReply []byte) []byte {
+	if f.Reply == nil {
+		return binaryReply
+	}
+	return f.Reply(message, binaryReply)
+}
+
+// InterceptorChain is a MessageInterceptor calling a list of interceptors.
+// Messages go through the interceptors in order, replies in reverse order.
+type InterceptorChain []MessageInterceptor
+
+var _ MessageInterceptor = InterceptorChain{} // compile-time type check
+
+// InterceptMessage calls the interceptors until one of them rejects the
+// message.
+func (c InterceptorChain) InterceptMessage(message *Message) error {
+	for _, interceptor := range c {
+		err := interceptor.InterceptMessage(message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InterceptReply calls the interceptors in reverse order.
+func (c InterceptorChain) InterceptReply(message *Message, binaryReply []byte) []byte {
+	for i := len(c) - 1; i >= 0; i-- {
+		binaryReply = c[i].InterceptReply(message, binaryReply)
+	}
+	return binaryReply
+}
+
+// NewChannelAllowlist returns a MessageInterceptor rejecting the messages, in
+// both directions, on channels which aren't listed. A name ending with `*`
+// allows all the channels starting with the given prefix, for example
+// "flutter/*".
+func NewChannelAllowlist(channels ...string) MessageInterceptor {
+	allowlist := channelAllowlist{
+		names: make(map[string]bool, len(channels)),
+	}
+	for _, channel := range channels {
+		if strings.HasSuffix(channel, "*") {
+			allowlist.prefixes = append(allowlist.prefixes, strings.TrimSuffix(channel, "*"))
+		} else {
+			allowlist.names[channel] = true
+		}
+	}
+	return allowlist
+}
+
+type channelAllowlist struct {
+	names    map[string]bool
+	prefixes []string
+}
+
+func (a channelAllowlist) InterceptMessage(message *Message) error {
+	if a.names[message.Channel] {
+		return nil
+	}
+	for _, prefix := range a.prefixes {
+		if strings.HasPrefix(message.Channel, prefix) {
+			return nil
+		}
+	}
+	return errors.Errorf("channel '%s' is not in the allowlist", message.Channel)
+}
+
+func (a channelAllowlist) InterceptReply(message *Message, binaryReply []byte) []byte {
+	return binaryReply
+}
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptorChain(t *testing.T) {
+	var calls []string
+	record := func(name string) MessageInterceptor {
+		return MessageInterceptorFuncs{
+			Message: func(message *Message) error {
+				calls = append(calls, name+" message")
+				message.Data = append(message.Data, name...)
+				return nil
+			},
+			Reply: func(message *Message, binaryReply []byte) []byte {
+				calls = append(calls, name+" reply")
+				return append(binaryReply, name...)
+			},
+		}
+	}
+	chain := InterceptorChain{record("a"), record("b"), MessageInterceptorFuncs{}}
+
+	message := &Message{Channel: "ch"}
+	assert.NoError(t, chain.InterceptMessage(message))
+	assert.Equal(t, "ab", string(message.Data))
+	assert.Equal(t, "ba", string(chain.InterceptReply(message, nil)))
+	assert.Equal(t, []string{"a message", "b message", "b reply", "a reply"}, calls)
+
+	calls = nil
+	reject := MessageInterceptorFuncs{Message: func(*Message) error { return errors.New("rejected") }}
+	chain = InterceptorChain{reject, record("a")}
+	assert.EqualError(t, chain.InterceptMessage(&Message{Channel: "ch"}), "rejected")
+	assert.Empty(t, calls)
+}
+
+func TestChannelAllowlist(t *testing.T) {
+	allowlist := NewChannelAllowlist("flutter/*", "kiosk/printer")
+	for channel, allowed := range map[string]bool{
+		"flutter/platform":  true,
+		"flutter/textinput": true,
+		"kiosk/printer":     true,
+		"kiosk/printer2":    false,
+		"plugins/camera":    false,
+		"":                  false,
+	} {
+		err := allowlist.InterceptMessage(&Message{Channel: channel})
+		assert.Equal(t, allowed, err == nil, channel)
+	}
+}
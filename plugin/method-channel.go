@@ -20,6 +20,8 @@ type MethodChannel struct {
 	methods         map[string]methodHandlerRegistration
 	catchAllhandler MethodHandler
 	methodsLock     sync.RWMutex
+
+	dispatcher Dispatcher
 }
 
 type methodHandlerRegistration struct {
@@ -144,6 +146,18 @@ func (m *MethodChannel) HandleFuncSync(methodName string, f func(arguments inter
 	m.HandleSync(methodName, MethodHandlerFunc(f))
 }
 
+// SetDispatcher sets the Dispatcher running the handlers registered with
+// Handle, HandleFunc and CatchAllHandle. Handlers registered with HandleSync
+// are still run on the platform thread.
+//
+// When no Dispatcher is set, or when given nil, each method call is handled
+// in a new goroutine.
+func (m *MethodChannel) SetDispatcher(dispatcher Dispatcher) {
+	m.methodsLock.Lock()
+	m.dispatcher = dispatcher
+	m.methodsLock.Unlock()
+}
+
 // ClearAllHandle clear all the handlers registered by
 // Handle\HandleFunc and HandleSync\HandleFuncSync.
 // ClearAllHandle doesn't not clear the handler registered by CatchAllHandle\CatchAllHandleFunc
@@ -183,16 +197,21 @@ func (m *MethodChannel) CatchAllHandleFunc(f func(arguments interface{}) (reply
 func (m *MethodChannel) handleChannelMessage(binaryMessage []byte, responseSender ResponseSender) (err error) {
 	methodCall, err := m.methodCodec.DecodeMethodCall(binaryMessage)
 	if err != nil {
+		responseSender.Send(nil)
 		return errors.Wrap(err, "failed to decode incoming message")
 	}
 
 	m.methodsLock.RLock()
 	registration, registrationExists := m.methods[methodCall.Method]
+	catchAllhandler := m.catchAllhandler
+	dispatcher := m.dispatcher
 	m.methodsLock.RUnlock()
 	if !registrationExists {
 
-		if m.catchAllhandler != nil {
-			go m.handleMethodCall(m.catchAllhandler, methodCall.Method, methodCall, responseSender)
+		if catchAllhandler != nil {
+			dispatch(dispatcher, methodCall, func() {
+				m.handleMethodCall(catchAllhandler, methodCall.Method, methodCall, responseSender)
+			})
 			return nil
 		}
 
@@ -204,7 +223,9 @@ func (m *MethodChannel) handleChannelMessage(binaryMessage []byte, responseSende
 	if registration.sync {
 		m.handleMethodCall(registration.handler, methodCall.Method, methodCall.Arguments, responseSender)
 	} else {
-		go m.handleMethodCall(registration.handler, methodCall.Method, methodCall.Arguments, responseSender)
+		dispatch(dispatcher, methodCall, func() {
+			m.handleMethodCall(registration.handler, methodCall.Method, methodCall.Arguments, responseSender)
+		})
 	}
 
 	return nil
@@ -259,6 +280,11 @@ func (e *Error) Error() string {
 	return e.err
 }
 
+// Code returns the error code sent to the Flutter side.
+func (e *Error) Code() string {
+	return e.code
+}
+
 // NewError create an error with an specific error code.
 func NewError(code string, err error) *Error {
 	pe := &Error{
@@ -0,0 +1,179 @@
+package recording
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// Codec identifies the encoding of the messages of a channel.
+type Codec int
+
+const (
+	// CodecGuess decodes the message with the first codec that accepts it.
+	CodecGuess Codec = iota
+	// CodecJSONMethod is plugin.JSONMethodCodec.
+	CodecJSONMethod
+	// CodecStandardMethod is plugin.StandardMethodCodec.
+	CodecStandardMethod
+	// CodecJSONMessage is a JSON message, as used by flutter/keyevent.
+	CodecJSONMessage
+	// CodecStandardMessage is plugin.StandardMessageCodec.
+	CodecStandardMessage
+	// CodecString is plugin.StringCodec.
+	CodecString
+	// CodecBinary is plugin.BinaryCodec, messages are printed in hexadecimal.
+	CodecBinary
+)
+
+// Decoder pretty-prints the messages of a recording.
+type Decoder struct {
+	// Codecs maps channel names to their codec. Messages of the channels
+	// which aren't listed are decoded with CodecGuess.
+	Codecs map[string]Codec
+}
+
+// NewDecoder returns a Decoder knowing the codecs of the channels used by the
+// Flutter framework.
+func NewDecoder() *Decoder {
+	return &Decoder{
+		Codecs: map[string]Codec{
+			"flutter/platform":      CodecJSONMethod,
+			"flutter/textinput":     CodecJSONMethod,
+			"flutter/navigation":    CodecJSONMethod,
+			"flutter/keyevent":      CodecJSONMessage,
+			"flutter/settings":      CodecJSONMessage,
+			"flutter/lifecycle":     CodecString,
+			"flutter/isolate":       CodecString,
+			"flutter/accessibility": CodecStandardMessage,
+			"flutter/mousecursor":   CodecStandardMethod,
+			"flutter/restoration":   CodecStandardMethod,
+		},
+	}
+}
+
+// Format returns a one-line description of the record and its decoded
+// payload.
+func (d *Decoder) Format(record *Record) string {
+	kind := record.Direction.String()
+	if record.Reply {
+		kind += " reply"
+	}
+	return fmt.Sprintf("%s #%d %s %s: %s",
+		record.Time.Format("15:04:05.000"), record.ID, kind, record.Channel, d.Decode(record))
+}
+
+// Decode returns the decoded payload of the record.
+func (d *Decoder) Decode(record *Record) string {
+	if len(record.Data) == 0 {
+		return "<empty>"
+	}
+	codec := d.Codecs[record.Channel]
+	if codec != CodecGuess {
+		s, ok := decodeWith(codec, record.Data, record.Reply)
+		if ok {
+			return s
+		}
+		return "<invalid> " + hexDump(record.Data)
+	}
+	for _, codec := range []Codec{CodecJSONMethod, CodecJSONMessage, CodecStandardMethod, CodecStandardMessage, CodecString} {
+		s, ok := decodeWith(codec, record.Data, record.Reply)
+		if ok {
+			return s
+		}
+	}
+	return hexDump(record.Data)
+}
+
+// decodeWith decodes data with the given codec, it reports whether the data
+// is valid for that codec.
+func decodeWith(codec Codec, data []byte, reply bool) (string, bool) {
+	switch codec {
+	case CodecJSONMethod:
+		if !json.Valid(data) {
+			return "", false
+		}
+		if reply {
+			result, err := plugin.JSONMethodCodec{}.DecodeEnvelope(data)
+			return formatEnvelope(result, err)
+		}
+		call, err := plugin.JSONMethodCodec{}.DecodeMethodCall(data)
+		if err != nil || call.Method == "" {
+			return "", false
+		}
+		return formatMethodCall(call), true
+
+	case CodecJSONMessage:
+		if !json.Valid(data) {
+			return "", false
+		}
+		return string(data), true
+
+	case CodecStandardMethod:
+		if reply {
+			result, err := plugin.StandardMethodCodec{}.DecodeEnvelope(data)
+			return formatEnvelope(result, err)
+		}
+		call, err := plugin.StandardMethodCodec{}.DecodeMethodCall(data)
+		if err != nil {
+			return "", false
+		}
+		return formatMethodCall(call), true
+
+	case CodecStandardMessage:
+		message, err := plugin.StandardMessageCodec{}.DecodeMessage(data)
+		if err != nil {
+			return "", false
+		}
+		return formatValue(message), true
+
+	case CodecString:
+		if !utf8.Valid(data) {
+			return "", false
+		}
+		return fmt.Sprintf("%q", data), true
+
+	default:
+		return hexDump(data), true
+	}
+}
+
+func formatMethodCall(call plugin.MethodCall) string {
+	return fmt.Sprintf("%s(%s)", call.Method, formatValue(call.Arguments))
+}
+
+func formatEnvelope(result interface{}, err error) (string, bool) {
+	if err != nil {
+		if ferr, ok := err.(plugin.FlutterError); ok {
+			return fmt.Sprintf("error %s: %s (%s)", ferr.Code, ferr.Message, formatValue(ferr.Details)), true
+		}
+		return "", false
+	}
+	return "success " + formatValue(result), true
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case json.RawMessage:
+		return string(v)
+	case []byte:
+		return hexDump(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// hexDump returns the hexadecimal representation of the first 64 bytes of
+// data.
+func hexDump(data []byte) string {
+	const max = 64
+	if len(data) > max {
+		return fmt.Sprintf("%s... (%d bytes)", hex.EncodeToString(data[:max]), len(data))
+	}
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,143 @@
+// Package recording records the platform messages exchanged between Flutter
+// and Go, and replays them without the engine.
+//
+// A recording is written by a Writer, which is a plugin.MessageInterceptor
+// (see the flutter.RecordMessages option). It is a file of JSON records, one
+// per line, for each message and each reply. Replies share the ID of their
+// message.
+//
+// Recordings are read back by a Reader, printed with a Decoder, and fed to
+// plugins with a Replayer.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// Record is a message, or the reply to a message, as written in a recording.
+type Record struct {
+	// ID is the plugin.Message ID, shared by the message and its reply.
+	ID        uint64                  `json:"id"`
+	Time      time.Time               `json:"time"`
+	Direction plugin.MessageDirection `json:"direction"`
+	// Reply is set on the record of a reply. The direction of a reply is the
+	// one of its message.
+	Reply   bool   `json:"reply,omitempty"`
+	Channel string `json:"channel"`
+	Data    []byte `json:"data"`
+}
+
+// Writer writes the messages and replies it intercepts to a recording. It
+// never rejects or rewrites messages.
+type Writer struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+var _ plugin.MessageInterceptor = &Writer{} // compile-time type check
+
+// NewWriter creates a Writer writing to w. Each record is written with a
+// single call to w.Write.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(w)}
+}
+
+// InterceptMessage records the message.
+func (w *Writer) InterceptMessage(message *plugin.Message) error {
+	w.write(Record{
+		ID:        message.ID,
+		Time:      message.Time,
+		Direction: message.Direction,
+		Channel:   message.Channel,
+		Data:      message.Data,
+	})
+	return nil
+}
+
+// InterceptReply records the reply.
+func (w *Writer) InterceptReply(message *plugin.Message, binaryReply []byte) []byte {
+	w.write(Record{
+		ID:        message.ID,
+		Time:      time.Now(),
+		Direction: message.Direction,
+		Reply:     true,
+		Channel:   message.Channel,
+		Data:      binaryReply,
+	})
+	return binaryReply
+}
+
+// Err returns the first error encountered while writing.
+func (w *Writer) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.err
+}
+
+func (w *Writer) write(record Record) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil {
+		return
+	}
+	w.err = w.encoder.Encode(record)
+}
+
+// Reader reads the records of a recording.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader creates a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	// messages like images sent over channels may be large.
+	scanner.Buffer(nil, 64*1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next record, or io.EOF at the end of the recording.
+func (r *Reader) Next() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		if len(r.scanner.Bytes()) == 0 {
+			continue
+		}
+		record := &Record{}
+		err := json.Unmarshal(r.scanner.Bytes(), record)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid record on line %d", r.line)
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadAll reads all the records of a recording.
+func ReadAll(r io.Reader) ([]*Record, error) {
+	reader := NewReader(r)
+	var records []*Record
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
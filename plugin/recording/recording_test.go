@@ -0,0 +1,74 @@
+package recording
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+func TestWriteReadReplay(t *testing.T) {
+	codec := plugin.StandardMethodCodec{}
+	call, err := codec.EncodeMethodCall(plugin.MethodCall{Method: "add", Arguments: int32(2)})
+	require.NoError(t, err)
+	reply, err := codec.EncodeSuccessEnvelope(int32(3))
+	require.NoError(t, err)
+
+	// record an incoming call and its reply, and an outgoing message with
+	// its reply.
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	incoming := &plugin.Message{ID: 1, Direction: plugin.IncomingMessage, Channel: "calc", Data: call, Time: time.Now()}
+	require.NoError(t, w.InterceptMessage(incoming))
+	assert.Equal(t, reply, w.InterceptReply(incoming, reply))
+	outgoing := &plugin.Message{ID: 2, Direction: plugin.OutgoingMessage, Channel: "calc/events", Data: []byte("ping"), Time: time.Now()}
+	require.NoError(t, w.InterceptMessage(outgoing))
+	w.InterceptReply(outgoing, []byte("pong"))
+	require.NoError(t, w.Err())
+
+	records, err := ReadAll(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, plugin.IncomingMessage, records[0].Direction)
+	assert.True(t, records[1].Reply)
+	assert.Equal(t, uint64(1), records[1].ID)
+
+	decoder := NewDecoder()
+	decoder.Codecs["calc/events"] = CodecString
+	assert.Equal(t, "add(2)", decoder.Decode(records[0]))
+	assert.Equal(t, "success 3", decoder.Decode(records[1]))
+	assert.Equal(t, `"pong"`, decoder.Decode(records[3]))
+
+	replayer := NewReplayer(records)
+	channel := plugin.NewMethodChannel(replayer, "calc", codec)
+	channel.HandleFunc("add", func(arguments interface{}) (interface{}, error) {
+		events := plugin.NewBasicMessageChannel(replayer, "calc/events", plugin.StringCodec{})
+		pong, err := events.SendWithReply("ping")
+		assert.NoError(t, err)
+		assert.Equal(t, "pong", pong)
+		return arguments.(int32) + 1, nil
+	})
+	results := replayer.Replay(time.Second)
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.True(t, results[0].Replied)
+	assert.Equal(t, results[0].RecordedReply.Data, results[0].Reply)
+	require.Len(t, replayer.Sent(), 1)
+	assert.Equal(t, "calc/events", replayer.Sent()[0].Channel)
+}
+
+func TestDecoderGuess(t *testing.T) {
+	decoder := NewDecoder()
+	for data, expected := range map[string]string{
+		`{"method":"SystemNavigator.pop","args":null}`: "SystemNavigator.pop(null)",
+		`{"keymap":"linux","type":"keydown"}`:          `{"keymap":"linux","type":"keydown"}`,
+		"AppLifecycleState.resumed":                    `"AppLifecycleState.resumed"`,
+		"\xff\xfe":                                     "fffe",
+	} {
+		assert.Equal(t, expected, decoder.Decode(&Record{Channel: "unknown", Data: []byte(data)}))
+	}
+}
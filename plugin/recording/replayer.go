@@ -0,0 +1,161 @@
+package recording
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// Replayer is a plugin.BinaryMessenger feeding the incoming messages of a
+// recording to the channel handlers registered on it, without the engine.
+//
+// Plugins are initialized with the Replayer as messenger, then Replay sends
+// them the recorded messages. Outgoing messages sent by the plugins are kept
+// and can be inspected with Sent, SendWithReply returns the replies recorded
+// for the same channel, in order.
+type Replayer struct {
+	records []*Record
+
+	lock     sync.Mutex
+	handlers map[string]plugin.ChannelHandlerFunc
+	// replies are the recorded replies to outgoing messages, per channel.
+	replies map[string][][]byte
+	sent    []*Record
+}
+
+var _ plugin.BinaryMessenger = &Replayer{} // compile-time type check
+
+// ReplayResult is the outcome of replaying an incoming message.
+type ReplayResult struct {
+	Message *Record
+	// RecordedReply is the reply found in the recording, if any.
+	RecordedReply *Record
+	// Replied is set when the handler sent a reply, which is then in Reply.
+	Replied bool
+	Reply   []byte
+	// Err is set when no handler is registered for the channel, or when the
+	// handler returns an error.
+	Err error
+}
+
+// NewReplayer creates a Replayer for the given records.
+func NewReplayer(records []*Record) *Replayer {
+	r := &Replayer{
+		records:  records,
+		handlers: make(map[string]plugin.ChannelHandlerFunc),
+		replies:  make(map[string][][]byte),
+	}
+	for _, record := range records {
+		if record.Reply && record.Direction == plugin.OutgoingMessage {
+			r.replies[record.Channel] = append(r.replies[record.Channel], record.Data)
+		}
+	}
+	return r
+}
+
+// Replay sends the incoming messages of the recording to the registered
+// handlers, in order. For each message, it waits at most timeout for the
+// handler to reply before moving on to the next one.
+func (r *Replayer) Replay(timeout time.Duration) []ReplayResult {
+	recordedReplies := make(map[uint64]*Record)
+	for _, record := range r.records {
+		if record.Reply && record.Direction == plugin.IncomingMessage {
+			recordedReplies[record.ID] = record
+		}
+	}
+
+	var results []ReplayResult
+	for _, record := range r.records {
+		if record.Reply || record.Direction != plugin.IncomingMessage {
+			continue
+		}
+		result := ReplayResult{
+			Message:       record,
+			RecordedReply: recordedReplies[record.ID],
+		}
+
+		r.lock.Lock()
+		handler := r.handlers[record.Channel]
+		r.lock.Unlock()
+		if handler == nil {
+			result.Err = errors.Errorf("no handler registered for channel '%s'", record.Channel)
+			results = append(results, result)
+			continue
+		}
+
+		sender := &replayResponseSender{reply: make(chan []byte, 1)}
+		err := handler(record.Data, sender)
+		if err != nil {
+			result.Err = err
+		}
+		select {
+		case result.Reply = <-sender.reply:
+			result.Replied = true
+		case <-time.After(timeout):
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Sent returns the outgoing messages sent by the plugins during the replay.
+func (r *Replayer) Sent() []*Record {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]*Record(nil), r.sent...)
+}
+
+// Send records the outgoing message.
+func (r *Replayer) Send(channel string, binaryMessage []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sent = append(r.sent, &Record{
+		ID:        uint64(len(r.sent) + 1),
+		Time:      time.Now(),
+		Direction: plugin.OutgoingMessage,
+		Channel:   channel,
+		Data:      binaryMessage,
+	})
+	return nil
+}
+
+// SendWithReply records the outgoing message and returns the next recorded
+// reply for the channel.
+func (r *Replayer) SendWithReply(channel string, binaryMessage []byte) (binaryReply []byte, err error) {
+	r.Send(channel, binaryMessage)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	replies := r.replies[channel]
+	if len(replies) == 0 {
+		return nil, errors.Errorf("no recorded reply left for channel '%s'", channel)
+	}
+	r.replies[channel] = replies[1:]
+	return replies[0], nil
+}
+
+// SetChannelHandler registers a handler for the replayed messages.
+func (r *Replayer) SetChannelHandler(channel string, handler plugin.ChannelHandlerFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if handler == nil {
+		delete(r.handlers, channel)
+		return
+	}
+	r.handlers[channel] = handler
+}
+
+type replayResponseSender struct {
+	reply chan []byte
+}
+
+func (s *replayResponseSender) Send(binaryReply []byte) {
+	select {
+	case s.reply <- binaryReply:
+	default:
+		// the first reply is kept, sending twice is a bug of the handler.
+	}
+}
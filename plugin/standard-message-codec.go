@@ -25,6 +25,9 @@ const (
 	standardMessageTypeFloat64Slice = 11
 	standardMessageTypeList         = 12
 	standardMessageTypeMap          = 13
+
+	// first type discriminator available to a StandardMessageCodecExtension
+	standardMessageTypeExtension = 128
 )
 
 // StandardMessageCodec implements a MessageCodec using the Flutter standard
@@ -64,7 +67,27 @@ const (
 // *big.Int's are represented in Dart as strings with the
 // hexadecimal representation of the integer's value.
 //
-type StandardMessageCodec struct{}
+// Application-specific values, such as the data classes of a Pigeon API, can
+// be supported by setting an Extension. They are written as a type
+// discriminator of 128 or greater, followed by a supported value.
+//
+type StandardMessageCodec struct {
+	// Extension is optional and handles values the codec doesn't support.
+	Extension StandardMessageCodecExtension
+}
+
+// StandardMessageCodecExtension extends a StandardMessageCodec with custom
+// types. It corresponds to overriding writeValue and readValueOfType in a Dart
+// subclass of StandardMessageCodec.
+type StandardMessageCodecExtension interface {
+	// EncodeValue returns the type discriminator (128 or greater) and the
+	// supported value representing value. ok must be false when the extension
+	// doesn't handle the given value.
+	EncodeValue(value interface{}) (valueType byte, content interface{}, ok bool)
+	// DecodeValue converts content, which was read after the given type
+	// discriminator, back to the application-specific value.
+	DecodeValue(valueType byte, content interface{}) (value interface{}, err error)
+}
 
 var _ MessageCodec = StandardMessageCodec{} // compile-time type check
 
@@ -79,8 +102,12 @@ func (s StandardMessageCodec) EncodeMessage(message interface{}) ([]byte, error)
 	return buf.Bytes(), nil
 }
 
-// DecodeMessage decodes binary data into a standard message
+// DecodeMessage decodes binary data into a standard message. Empty data, as
+// sent by Dart for a null message, decodes to nil.
 func (s StandardMessageCodec) DecodeMessage(data []byte) (message interface{}, err error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
 	buf := bytes.NewBuffer(data)
 	message, err = s.readValue(buf)
 	if err != nil {
@@ -371,6 +398,19 @@ func (s StandardMessageCodec) writeValue(buf *bytes.Buffer, value interface{}) e
 		return s.writeMap(buf, typedValue)
 
 	default:
+		if s.Extension != nil {
+			valueType, content, ok := s.Extension.EncodeValue(value)
+			if ok {
+				if valueType < standardMessageTypeExtension {
+					return errors.Errorf("extension type %d for %T collides with the standard types", valueType, value)
+				}
+				err = buf.WriteByte(valueType)
+				if err != nil {
+					return err
+				}
+				return s.writeValue(buf, content)
+			}
+		}
 		return MessageTypeError{fmt.Sprintf("type %T is not supported by StandardMessageCodec", value)}
 	}
 	// no return statement because each case must return
@@ -592,6 +632,13 @@ func (s StandardMessageCodec) readValueAligned(buf *bytes.Buffer, originalSize i
 		return s.readMap(buf, originalSize)
 
 	default:
+		if valueType >= standardMessageTypeExtension && s.Extension != nil {
+			content, err := s.readValueAligned(buf, originalSize)
+			if err != nil {
+				return nil, err
+			}
+			return s.Extension.DecodeValue(valueType, content)
+		}
 		return nil, errors.New("invalid message value type")
 	}
 }
@@ -216,3 +216,50 @@ func TestStandardMessageEncodeAlignment(t *testing.T) {
 		assert.Equal(t, s.data, result)
 	}
 }
+
+type testPoint struct {
+	X, Y float64
+}
+
+type testPointExtension struct{}
+
+func (testPointExtension) EncodeValue(value interface{}) (byte, interface{}, bool) {
+	p, ok := value.(testPoint)
+	if !ok {
+		return 0, nil, false
+	}
+	return 128, []interface{}{p.X, p.Y}, true
+}
+
+func (testPointExtension) DecodeValue(valueType byte, content interface{}) (interface{}, error) {
+	list := content.([]interface{})
+	return testPoint{X: list[0].(float64), Y: list[1].(float64)}, nil
+}
+
+func TestStandardMessageExtension(t *testing.T) {
+	codec := StandardMessageCodec{Extension: testPointExtension{}}
+
+	data, err := codec.EncodeMessage(testPoint{X: 1, Y: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{
+		128, 12, 2,
+		6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+		6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x40,
+	}, data)
+
+	v, err := codec.DecodeMessage(data)
+	assert.Nil(t, err)
+	assert.Equal(t, testPoint{X: 1, Y: 2}, v)
+
+	list := []interface{}{testPoint{X: 1, Y: 2}, nil, "point"}
+	data, err = codec.EncodeMessage(list)
+	assert.Nil(t, err)
+	v, err = codec.DecodeMessage(data)
+	assert.Nil(t, err)
+	assert.Equal(t, list, v)
+
+	_, err = StandardMessageCodec{}.DecodeMessage([]byte{128, 0})
+	assert.NotNil(t, err)
+	_, err = StandardMessageCodec{}.EncodeMessage(testPoint{})
+	assert.NotNil(t, err)
+}
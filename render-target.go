@@ -0,0 +1,174 @@
+package flutter
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/opengl"
+)
+
+// RenderTarget is a Texture rendered by the plugin with OpenGL. The plugin
+// draws into a framebuffer, on a dedicated thread owning an OpenGL context
+// shared with the window. Like the textures registered with RegisterFrames,
+// the framebuffer textures are triple-buffered: the plugin never waits for
+// Flutter to draw the previous frame.
+type RenderTarget struct {
+	Texture
+	width, height int32
+
+	// window is a hidden window, owning the OpenGL context of the target
+	window *glfw.Window
+	frames *frameTexture
+	fbo    uint32
+	// allocated tells which slots of frames have a width x height storage
+	allocated [3]bool
+
+	lock    sync.Mutex
+	closed  bool
+	tasks   chan func()
+	stopped chan struct{}
+}
+
+// NewRenderTarget creates and registers a Texture of width x height pixels,
+// rendered with RenderTarget.Render.
+func (t *TextureRegistry) NewRenderTarget(width, height int) (*RenderTarget, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.Errorf("invalid render target size %dx%d", width, height)
+	}
+	window, err := t.createSharedWindow()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the render target context")
+	}
+
+	r := &RenderTarget{
+		Texture: t.NewTexture(),
+		width:   int32(width),
+		height:  int32(height),
+		window:  window,
+		tasks:   make(chan func()),
+		stopped: make(chan struct{}),
+	}
+	r.frames = newFrameTexture(r.ID)
+	go r.run()
+
+	t.channelsLock.Lock()
+	t.channels[r.ID] = &externalTextureHanlder{frames: r.frames}
+	t.channelsLock.Unlock()
+	err = t.engine.RegisterExternalTexture(r.ID)
+	if err != nil {
+		r.stop()
+		t.channelsLock.Lock()
+		delete(t.channels, r.ID)
+		t.channelsLock.Unlock()
+		return nil, errors.Errorf("'go-flutter' couldn't register texture with id: '%v': %v", r.ID, err)
+	}
+
+	t.targetsLock.Lock()
+	t.targets[r] = struct{}{}
+	t.targetsLock.Unlock()
+	return r, nil
+}
+
+func (r *RenderTarget) run() {
+	runtime.LockOSThread()
+	defer close(r.stopped)
+
+	r.window.MakeContextCurrent()
+	defer glfw.DetachCurrentContext()
+	once.Do(func() {
+		if err := opengl.Init(); err != nil {
+			fmt.Printf("go-flutter: TextureRegistry gl init failed: %v\n", err)
+		}
+	})
+
+	for task := range r.tasks {
+		task()
+	}
+
+	r.frames.delete()
+	if r.fbo != 0 {
+		opengl.DeleteFramebuffer(&r.fbo)
+	}
+}
+
+// Render calls draw on the thread of the target, with its OpenGL context
+// current and the framebuffer fbo bound, rendering to a texture of the
+// target size. The plugin must load its own OpenGL bindings, e.g., call
+// gl.Init, from draw. Flutter is notified of the new frame once draw has
+// returned without error, there is no need to call FrameAvailable.
+func (r *RenderTarget) Render(draw func(fbo uint32) error) error {
+	errc := make(chan error, 1)
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return errors.Errorf("'go-flutter' render target with id: '%v' is closed", r.ID)
+	}
+	r.tasks <- func() { errc <- r.render(draw) }
+	r.lock.Unlock()
+	return <-errc
+}
+
+func (r *RenderTarget) render(draw func(fbo uint32) error) error {
+	if r.fbo == 0 {
+		opengl.CreateFramebuffer(&r.fbo)
+	}
+	index, slot := r.frames.beginFrame()
+	if !r.allocated[index] {
+		opengl.AllocateTexture(slot.texture, r.width, r.height)
+		r.allocated[index] = true
+	}
+	err := opengl.BindFramebufferTexture(r.fbo, slot.texture, r.width, r.height)
+	if err == nil {
+		err = draw(r.fbo)
+	}
+	opengl.UnbindFramebuffer()
+	if err != nil {
+		return errors.Wrapf(err, "'go-flutter' couldn't render texture with id: '%v'", r.ID)
+	}
+	r.frames.endFrame(index)
+
+	return r.FrameAvailable()
+}
+
+// UnRegister unregisters the target, like Close.
+func (r *RenderTarget) UnRegister() error {
+	return r.Close()
+}
+
+// Close unregisters the target and releases its OpenGL resources.
+func (r *RenderTarget) Close() error {
+	registry := r.registry
+	registry.targetsLock.Lock()
+	_, open := registry.targets[r]
+	delete(registry.targets, r)
+	registry.targetsLock.Unlock()
+	if !open {
+		return nil
+	}
+
+	err := registry.engine.UnregisterExternalTexture(r.ID)
+	registry.channelsLock.Lock()
+	delete(registry.channels, r.ID)
+	registry.channelsLock.Unlock()
+	r.stop()
+	if err != nil {
+		return errors.Errorf("'go-flutter' couldn't unregisters texture with id: '%v': %v", r.ID, err)
+	}
+	return nil
+}
+
+// stop stops the thread of the target and destroys its window.
+func (r *RenderTarget) stop() {
+	r.lock.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.tasks)
+	}
+	r.lock.Unlock()
+	<-r.stopped
+	r.registry.destroySharedWindow(r.window)
+}
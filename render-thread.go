@@ -0,0 +1,97 @@
+package flutter
+
+import (
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+)
+
+// renderThread runs the render (raster) tasks of the engine on a dedicated,
+// locked, OS thread with its own EventLoop, so that rasterization doesn't
+// delay the input processing and the plugins on the main thread.
+//
+// While the engine runs, the OpenGL context of the window is made current on
+// the render thread by the engine, through GLMakeCurrent. It must not be made
+// current on the main thread.
+type renderThread struct {
+	eventLoop *EventLoop
+	// tasks holds the tasks which must run with the OpenGL context of the
+	// window, e.g., deleting textures.
+	tasks *taskqueue.Queue
+
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// startRenderThread starts the render thread, tasks are run by runTask.
+func startRenderThread(runTask func(*embedder.FlutterTask) error, policy idlePolicy) *renderThread {
+	r := &renderThread{
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	r.tasks = taskqueue.New(r.postEmptyEvent)
+
+	ready := make(chan struct{})
+	go func() {
+		runtime.LockOSThread()
+		defer close(r.stopped)
+
+		// The EventLoop must be created on the thread running its tasks.
+		r.eventLoop = newEventLoop(r.postEmptyEvent, runTask, nil, policy)
+		close(ready)
+
+		timer := time.NewTimer(time.Hour)
+		defer timer.Stop()
+		for {
+			r.eventLoop.WaitForEvents(func(duration float64) {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				if !math.IsInf(duration, 1) {
+					timer.Reset(time.Duration(duration * float64(time.Second)))
+				}
+				select {
+				case <-r.wake:
+				case <-timer.C:
+				case <-r.stop:
+				}
+			})
+			r.tasks.Drain()
+
+			select {
+			case <-r.stop:
+				// Release the window context, the thread is about to exit.
+				glfw.DetachCurrentContext()
+				return
+			default:
+			}
+		}
+	}()
+	<-ready
+	return r
+}
+
+// postEmptyEvent wakes up the render thread.
+func (r *renderThread) postEmptyEvent() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// stopAndWait stops the render thread, it must be called after the engine
+// has been shutdown.
+func (r *renderThread) stopAndWait() {
+	close(r.stop)
+	<-r.stopped
+}
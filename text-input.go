@@ -4,11 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
-	"unicode"
 	"unicode/utf16"
 
+	"golang.org/x/text/language"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/capitalization"
 	"github.com/go-flutter-desktop/go-flutter/internal/keyboard"
+	"github.com/go-flutter-desktop/go-flutter/internal/keymap"
+	"github.com/go-flutter-desktop/go-flutter/internal/textdelta"
 	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/ime"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/pkg/errors"
 )
@@ -23,8 +28,28 @@ type textinputPlugin struct {
 	clientID   float64
 	clientConf argSetClientConf
 	ed         argsEditingState
+	// deltas are the changes since the last update of a delta model client
+	deltas textdelta.Batch
 
 	backOnEscape bool
+	// capitalizer applies the TextCapitalization of the client, with the
+	// case mappings of the locale sent to the engine.
+	capitalizer *capitalization.Capitalizer
+
+	// the input method composing text, nil without input method
+	inputMethod ime.Backend
+	// inputMethodHandledKey is true when the characters of the last key
+	// event are part of the composition.
+	inputMethodHandledKey bool
+	// keyEvents are the key events waiting for the reply of the framework,
+	// the characters are typed in their order.
+	keyEvents *keymap.Pending
+	mainThread            *MainThread
+	window                *glfw.Window
+	windowManager         *windowManager
+	// the position of the text being composed
+	editableTransform *[16]float64
+	markedTextRect    argsMarkedTextRect
 
 	virtualKeyboardShow func()
 	virtualKeyboardHide func()
@@ -40,6 +65,9 @@ type argSetClientConf struct {
 	} `json:"inputType"`
 	InputAction        string `json:"inputAction"`
 	TextCapitalization string `json:"textCapitalization"`
+	// EnableDeltaModel clients receive the changes of the editing state as
+	// TextEditingDeltas.
+	EnableDeltaModel bool `json:"enableDeltaModel"`
 }
 
 // argsEditingState is used to hold the current TextInput state.
@@ -49,10 +77,18 @@ type argsEditingState struct {
 	SelectionBase     int    `json:"selectionBase"`
 	SelectionExtent   int    `json:"selectionExtent"`
 	SelectionAffinity string `json:"selectionAffinity"`
+	// SelectionIsDirectional is part of the TextEditingDeltas.
+	SelectionIsDirectional bool `json:"selectionIsDirectional"`
+	// ComposingBase and ComposingExtent are the range of the text being
+	// composed by the input method, -1 without composing text.
+	ComposingBase   int `json:"composingBase"`
+	ComposingExtent int `json:"composingExtent"`
 }
 
 // all hardcoded because theres not pluggable renderer system.
-var defaultTextinputPlugin = &textinputPlugin{}
+var defaultTextinputPlugin = &textinputPlugin{
+	capitalizer: capitalization.New(language.Und),
+}
 
 func (p *textinputPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
 	p.channel = plugin.NewMethodChannel(messenger, textinputChannelName, plugin.JSONMethodCodec{})
@@ -75,10 +111,9 @@ func (p *textinputPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
 	p.channel.HandleFuncSync("TextInput.setStyle", func(_ interface{}) (interface{}, error) { return nil, nil })
 	// Ignored: Used on MacOS to position accent selection menu
 	p.channel.HandleFuncSync("TextInput.setCaretRect", func(_ interface{}) (interface{}, error) { return nil, nil })
-	// Ignored: GLFW dosn't support setting the input method of the current cursor location #426
-	p.channel.HandleFuncSync("TextInput.setEditableSizeAndTransform", func(_ interface{}) (interface{}, error) { return nil, nil })
-	// Ignored: GLFW dosn't support setting the input method of the current cursor location #426
-	p.channel.HandleFuncSync("TextInput.setMarkedTextRect", func(_ interface{}) (interface{}, error) { return nil, nil })
+	// Used to position the candidates of the input method
+	p.channel.HandleFuncSync("TextInput.setEditableSizeAndTransform", p.handleSetEditableSizeAndTransform)
+	p.channel.HandleFuncSync("TextInput.setMarkedTextRect", p.handleSetMarkedTextRect)
 	// Ignored: This information is used by flutter on Android, iOS and web
 	p.channel.HandleFuncSync("TextInput.requestAutofill", func(_ interface{}) (interface{}, error) { return nil, nil })
 
@@ -101,15 +136,29 @@ func (p *textinputPlugin) handleSetClient(arguments interface{}) (reply interfac
 		return nil, errors.Wrap(err, "failed to decode clientID for handleSetClient")
 	}
 
+	p.clientConf = argSetClientConf{}
+	p.deltas.Reset()
 	err = json.Unmarshal(args[1], &p.clientConf)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode clientConf for handleSetClient")
 	}
 
+	if p.inputMethod != nil {
+		err = p.inputMethod.FocusIn()
+		if err != nil {
+			fmt.Printf("go-flutter: %v\n", err)
+		}
+	}
 	return nil, nil
 }
 
 func (p *textinputPlugin) handleClearClient(arguments interface{}) (reply interface{}, err error) {
+	if p.inputMethod != nil && p.clientID != 0 {
+		err = p.inputMethod.FocusOut()
+		if err != nil {
+			fmt.Printf("go-flutter: %v\n", err)
+		}
+	}
 	p.clientID = 0
 	return nil, nil
 }
@@ -119,6 +168,8 @@ func (p *textinputPlugin) handleSetEditingState(arguments interface{}) (reply in
 		return nil, errors.New("cannot set editing state when no client is selected")
 	}
 
+	wasComposing := p.isComposing()
+	p.deltas.Reset()
 	err = json.Unmarshal(arguments.(json.RawMessage), &p.ed)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode json arguments for handleSetEditingState")
@@ -127,6 +178,17 @@ func (p *textinputPlugin) handleSetEditingState(arguments interface{}) (reply in
 	p.ed.utf16Text = utf16.Encode([]rune(p.ed.Text))
 	utf16TextLen := len(p.ed.utf16Text)
 
+	if !p.isComposing() {
+		p.ed.ComposingBase, p.ed.ComposingExtent = -1, -1
+		// the composition was cancelled by the framework
+		if wasComposing && p.inputMethod != nil {
+			err = p.inputMethod.Reset()
+			if err != nil {
+				fmt.Printf("go-flutter: %v\n", err)
+			}
+		}
+	}
+
 	// sometimes flutter sends invalid cursor position
 	if p.ed.SelectionBase < 0 ||
 		p.ed.SelectionExtent < 0 ||
@@ -147,17 +209,17 @@ func (p *textinputPlugin) handleSetEditingState(arguments interface{}) (reply in
 }
 
 func (p *textinputPlugin) glfwCharCallback(w *glfw.Window, char rune) {
-	if p.clientID == 0 {
+	if p.inputMethodHandledKey {
 		return
 	}
-	// Opinionated: If a flutter dev uses TextCapitalization.characters
-	//              in a TextField, that means she/he wants to receive
-	//              uppercase characters.
-	// TODO(Drakirus): Handle language-specific case mappings such as Turkish.
-	if p.clientConf.TextCapitalization == "TextCapitalization.characters" {
-		char = unicode.ToUpper(char)
-	}
-	p.addText(char)
+	// the fallbacks of the previous keys, e.g., the newline of Enter, are
+	// run first.
+	p.keyEvents.Run(func() {
+		if p.clientID == 0 {
+			return
+		}
+		p.addText(p.capitalize(string(char)))
+	})
 }
 
 func (p *textinputPlugin) glfwKeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
@@ -178,46 +240,75 @@ func (p *textinputPlugin) glfwKeyCallback(window *glfw.Window, key glfw.Key, sca
 				p.performAction("TextInputAction.done")
 				return
 			} else if p.clientConf.InputType.Name == "TextInputType.multiline" {
-				p.addText('\n')
+				p.addText("\n")
 			}
 			// this action is described by argSetClientConf.
 			p.performAction(p.clientConf.InputAction)
 		}
-		// Mapping to some text navigation shortcut that are already implemented in
-		// the flutter framework.
-		// Home
-		if key == glfw.KeyHome {
-			defaultKeyeventsPlugin.sendKeyEvent(window, glfw.KeyLeft, glfw.GetKeyScancode(glfw.KeyLeft), glfw.Press, mods|glfw.ModAlt)
-			defaultKeyeventsPlugin.sendKeyEvent(window, glfw.KeyLeft, glfw.GetKeyScancode(glfw.KeyLeft), glfw.Release, mods|glfw.ModAlt)
-		}
-		// End
-		if key == glfw.KeyEnd {
-			defaultKeyeventsPlugin.sendKeyEvent(window, glfw.KeyRight, glfw.GetKeyScancode(glfw.KeyRight), glfw.Press, mods|glfw.ModAlt)
-			defaultKeyeventsPlugin.sendKeyEvent(window, glfw.KeyRight, glfw.GetKeyScancode(glfw.KeyRight), glfw.Release, mods|glfw.ModAlt)
-		}
 
 	}
 }
 
-func (p *textinputPlugin) addText(text rune) {
-	p.removeSelectedText()
-	utf16text := utf16.Encode([]rune{text})
-	utf16TextLen := len(p.ed.utf16Text) + len(utf16text)
-	newText := make([]uint16, 0, utf16TextLen)
-	newText = append(newText, p.ed.utf16Text[:p.ed.SelectionBase]...)
-	newText = append(newText, utf16text...)
-	newText = append(newText, p.ed.utf16Text[p.ed.SelectionBase:]...)
-	p.ed.utf16Text = newText
-
-	p.ed.SelectionBase++
+// replaceText replaces the UTF-16 range [start, end) of the text with text,
+// and records the change for the clients of the delta model. The selection
+// and the composing range are left to the caller.
+func (p *textinputPlugin) replaceText(start, end int, text string) {
+	if p.clientConf.EnableDeltaModel {
+		p.ed.Text = p.deltas.Replace(p.ed.Text, start, end, text)
+	} else {
+		p.ed.Text = textdelta.Replace(p.ed.Text, start, end, text)
+	}
+	p.ed.utf16Text = utf16.Encode([]rune(p.ed.Text))
+}
+
+func (p *textinputPlugin) addText(text string) {
+	selectionIndexStart, selectionIndexEnd := p.getSelectedText()
+	p.replaceText(selectionIndexStart, selectionIndexEnd, text)
+
+	p.ed.SelectionBase = selectionIndexStart + len(utf16.Encode([]rune(text)))
 	p.ed.SelectionExtent = p.ed.SelectionBase
 	p.updateEditingState()
 }
 
-// UpupdateEditingState updates the TextInput with the current state by invoking
-// TextInputClient.updateEditingState in the flutter framework
+// capitalize applies the TextCapitalization of the client to text, typed in
+// place of the selection, or of the text being composed.
+func (p *textinputPlugin) capitalize(text string) string {
+	mode := capitalization.ParseMode(p.clientConf.TextCapitalization)
+	if mode == capitalization.None {
+		return text
+	}
+	start, _ := p.getSelectedText()
+	if p.isComposing() {
+		start = p.ed.ComposingBase
+		if p.ed.ComposingExtent < start {
+			start = p.ed.ComposingExtent
+		}
+	}
+	before := p.ed.Text[:textdelta.ByteOffset(p.ed.Text, start)]
+	return p.capitalizer.Apply(mode, before, text)
+}
+
+// updateEditingState updates the TextInput with the current state by invoking
+// TextInputClient.updateEditingState in the flutter framework, or
+// TextInputClient.updateEditingStateWithDeltas for the clients of the delta
+// model.
 func (p *textinputPlugin) updateEditingState() {
-	p.ed.Text = string(utf16.Decode(p.ed.utf16Text))
+	if p.clientConf.EnableDeltaModel {
+		p.channel.InvokeMethod("TextInputClient.updateEditingStateWithDeltas", []interface{}{
+			p.clientID,
+			map[string]interface{}{
+				"deltas": p.deltas.Flush(p.ed.Text, textdelta.Selection{
+					Base:            p.ed.SelectionBase,
+					Extent:          p.ed.SelectionExtent,
+					Affinity:        p.ed.SelectionAffinity,
+					IsDirectional:   p.ed.SelectionIsDirectional,
+					ComposingBase:   p.ed.ComposingBase,
+					ComposingExtent: p.ed.ComposingExtent,
+				}),
+			},
+		})
+		return
+	}
 	arguments := []interface{}{
 		p.clientID,
 		p.ed,
@@ -245,7 +336,7 @@ func (p *textinputPlugin) performTextInputAction() {
 func (p *textinputPlugin) removeSelectedText() bool {
 	selectionIndexStart, selectionIndexEnd := p.getSelectedText()
 	if selectionIndexStart != selectionIndexEnd {
-		p.ed.utf16Text = append(p.ed.utf16Text[:selectionIndexStart], p.ed.utf16Text[selectionIndexEnd:]...)
+		p.replaceText(selectionIndexStart, selectionIndexEnd, "")
 		p.ed.SelectionBase = selectionIndexStart
 		p.ed.SelectionExtent = selectionIndexStart
 		return true
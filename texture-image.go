@@ -0,0 +1,224 @@
+package flutter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/imagetexture"
+)
+
+// ImageScaling is how an image is scaled to the size requested by Flutter
+type ImageScaling int
+
+const (
+	// ImageScaleFit scales the image to fit in the requested size, keeping its
+	// aspect ratio. The remaining area is transparent.
+	ImageScaleFit ImageScaling = ImageScaling(imagetexture.Fit)
+	// ImageScaleFill scales the image to cover the requested size, keeping
+	// its aspect ratio. The image is cropped around its center.
+	ImageScaleFill ImageScaling = ImageScaling(imagetexture.Fill)
+	// ImageScaleNone keeps the image at its own size, Flutter stretches it to
+	// the size of the Texture widget.
+	ImageScaleNone ImageScaling = ImageScaling(imagetexture.None)
+)
+
+// FrameSource produces the frames of an animation or of a video stream.
+type FrameSource interface {
+	// NextFrame returns the next frame and how long it's displayed, it may
+	// block until the frame is available. The frame must not be modified
+	// afterwards. io.EOF ends the playback, the last frame stays displayed.
+	NextFrame() (image.Image, time.Duration, error)
+}
+
+// NewGIFFrameSource returns a FrameSource playing an animated GIF, as many
+// times as its LoopCount.
+func NewGIFFrameSource(g *gif.GIF) FrameSource {
+	return imagetexture.NewGIF(g)
+}
+
+// NewMJPEGFrameSource returns a FrameSource decoding a MJPEG stream, i.e.,
+// JPEG images sent as the parts of a multipart/x-mixed-replace response.
+// boundary is the boundary parameter of the Content-Type of the stream. Every
+// frame is displayed for frameDuration, use 0 for a live stream paced by its
+// source.
+func NewMJPEGFrameSource(r io.Reader, boundary string, frameDuration time.Duration) FrameSource {
+	return imagetexture.NewMJPEG(r, boundary, frameDuration)
+}
+
+// ImageTexture is a Texture backed by an image. The image is converted and
+// scaled to the size requested by Flutter when it's drawn, the result is
+// cached until the image changes.
+type ImageTexture struct {
+	texture *Texture
+	scaling ImageScaling
+
+	lock  sync.Mutex
+	image image.Image
+	// inPlace is true for a draw.Image updated in place, whose pixels must
+	// be copied
+	inPlace bool
+	// generation is incremented every time the image changes
+	generation uint64
+
+	// the latest converted image
+	buffer                    *PixelBuffer
+	bufferGeneration          uint64
+	bufferWidth, bufferHeight int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// RegisterImage registers the textureID with an image, which can be replaced
+// with SetImage. The image must not be modified once given.
+func (t *Texture) RegisterImage(img image.Image, scaling ImageScaling) (*ImageTexture, error) {
+	it := newImageTexture(t, scaling)
+	it.image = img
+	return it, it.register()
+}
+
+// RegisterDrawImage registers the textureID with an image updated in place,
+// with ImageTexture.Draw.
+func (t *Texture) RegisterDrawImage(img draw.Image, scaling ImageScaling) (*ImageTexture, error) {
+	it := newImageTexture(t, scaling)
+	it.image = img
+	it.inPlace = true
+	return it, it.register()
+}
+
+// RegisterFrameSource registers the textureID with the frames of source. The
+// frames are read on a goroutine, each one is displayed for the duration
+// returned by the source, until the end of the source or until the
+// ImageTexture is closed.
+func (t *Texture) RegisterFrameSource(source FrameSource, scaling ImageScaling) (*ImageTexture, error) {
+	it := newImageTexture(t, scaling)
+	err := it.register()
+	if err != nil {
+		return nil, err
+	}
+	go it.play(source)
+	return it, nil
+}
+
+func newImageTexture(t *Texture, scaling ImageScaling) *ImageTexture {
+	return &ImageTexture{
+		texture: t,
+		scaling: scaling,
+		stop:    make(chan struct{}),
+	}
+}
+
+func (it *ImageTexture) register() error {
+	return it.texture.Register(it.handle)
+}
+
+// SetImage replaces the image, which must not be modified once given.
+func (it *ImageTexture) SetImage(img image.Image) error {
+	it.lock.Lock()
+	it.image = img
+	it.inPlace = false
+	it.generation++
+	it.lock.Unlock()
+	return it.texture.FrameAvailable()
+}
+
+// Draw calls f with the image given to RegisterDrawImage, to update it in
+// place. Flutter doesn't read the image during the call, and is notified of
+// the new frame afterwards.
+func (it *ImageTexture) Draw(f func(img draw.Image)) error {
+	it.lock.Lock()
+	img, ok := it.image.(draw.Image)
+	if !ok || !it.inPlace {
+		it.lock.Unlock()
+		return errors.Errorf("'go-flutter' texture with id: '%v' isn't registered with RegisterDrawImage", it.texture.ID)
+	}
+	f(img)
+	it.generation++
+	it.lock.Unlock()
+	return it.texture.FrameAvailable()
+}
+
+// Close stops the frame source, if any, and unregisters the texture.
+func (it *ImageTexture) Close() error {
+	it.stopOnce.Do(func() { close(it.stop) })
+	return it.texture.UnRegister()
+}
+
+// play displays the frames of source, paced by their duration.
+func (it *ImageTexture) play(source FrameSource) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		img, duration, err := source.NextFrame()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fmt.Printf("go-flutter: failed to read the next frame of texture with id: '%v': %v\n", it.texture.ID, err)
+			return
+		}
+
+		select {
+		case <-it.stop:
+			return
+		default:
+		}
+		err = it.SetImage(img)
+		if err != nil {
+			fmt.Printf("go-flutter: %v\n", err)
+		}
+
+		if duration <= 0 {
+			continue
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(duration)
+		select {
+		case <-timer.C:
+		case <-it.stop:
+			return
+		}
+	}
+}
+
+// handle converts the image to a PixelBuffer of the requested size.
+func (it *ImageTexture) handle(width, height int) (bool, *PixelBuffer) {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+	if it.image == nil {
+		return false, nil
+	}
+	if it.buffer != nil && it.bufferGeneration == it.generation &&
+		it.bufferWidth == width && it.bufferHeight == height {
+		return true, it.buffer
+	}
+
+	rgba := imagetexture.Scale(it.image, width, height, imagetexture.Mode(it.scaling))
+	if it.inPlace && image.Image(rgba) == it.image {
+		// the pixels are uploaded after the lock is released.
+		copied := image.NewRGBA(rgba.Rect)
+		draw.Draw(copied, copied.Rect, rgba, rgba.Rect.Min, draw.Src)
+		rgba = copied
+	}
+	it.buffer = &PixelBuffer{
+		Pix:    rgba.Pix,
+		Width:  rgba.Rect.Dx(),
+		Height: rgba.Rect.Dy(),
+		Stride: rgba.Stride,
+	}
+	it.bufferGeneration = it.generation
+	it.bufferWidth, it.bufferHeight = width, height
+	return true, it.buffer
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/go-flutter-desktop/go-flutter/embedder"
 	"github.com/go-flutter-desktop/go-flutter/internal/opengl"
-	"github.com/go-flutter-desktop/go-flutter/internal/tasker"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/pkg/errors"
 )
@@ -22,29 +22,64 @@ type TextureRegistry struct {
 	channels     map[int64]*externalTextureHanlder
 	channelsLock sync.RWMutex
 
-	// engineTasker holds tasks which must be executed in the engine thread
-	engineTasker *tasker.Tasker
+	// glTasks holds tasks which must be executed in the thread owning the
+	// OpenGL context, the render thread
+	glTasks *taskqueue.Queue
 
 	texture      int64
 	texturesLock sync.Mutex
+
+	// yuvConverter converts planar YUV pixels, only used on the render thread
+	yuvConverter *opengl.YUVConverter
+
+	// uploader uploads the frames pushed to textures, started on first use
+	uploader     *textureUploader
+	uploaderLock sync.Mutex
+	// mainThread creates the hidden windows owning the shared OpenGL
+	// contexts of the uploader and of the render targets
+	mainThread *MainThread
+
+	// targets holds the open render targets, closed with the registry
+	targets     map[*RenderTarget]struct{}
+	targetsLock sync.Mutex
 }
 
 type externalTextureHanlder struct {
 	// handle is called when flutter needs the PixelBuffer
 	handle ExternalTextureHanlderFunc
+	// frames is set for textures whose frames are pushed, handle is then nil
+	frames *frameTexture
+	// glHandle is set for textures owned by the plugin, handle is then nil
+	glHandle GLTextureHandlerFunc
 	// gl texture to refer to for this handler
 	texture uint32
 }
 
-func newTextureRegistry(engine *embedder.FlutterEngine, window *glfw.Window) *TextureRegistry {
+func newTextureRegistry(engine *embedder.FlutterEngine, window *glfw.Window, glTasks *taskqueue.Queue, mainThread *MainThread) *TextureRegistry {
 	return &TextureRegistry{
-		window:       window,
-		engine:       engine,
-		channels:     make(map[int64]*externalTextureHanlder),
-		engineTasker: tasker.New(),
+		window:     window,
+		engine:     engine,
+		channels:   make(map[int64]*externalTextureHanlder),
+		glTasks:    glTasks,
+		mainThread: mainThread,
+		targets:    make(map[*RenderTarget]struct{}),
 	}
 }
 
+// createSharedWindow creates a hidden window whose OpenGL context is shared
+// with the window.
+func (t *TextureRegistry) createSharedWindow() (window *glfw.Window, err error) {
+	t.mainThread.RunOnMainThread(func() {
+		window, err = createResourceWindow(t.window)
+	})
+	return window, err
+}
+
+// destroySharedWindow destroys a window created by createSharedWindow.
+func (t *TextureRegistry) destroySharedWindow(window *glfw.Window) {
+	t.mainThread.RunOnMainThread(window.Destroy)
+}
+
 // init must happen in engine thread
 func (t *TextureRegistry) init() error {
 	t.window.MakeContextCurrent()
@@ -68,12 +103,34 @@ func (t *TextureRegistry) NewTexture() Texture {
 // Texture on a given ID.
 type ExternalTextureHanlderFunc func(width int, height int) (bool, *PixelBuffer)
 
-// PixelBuffer is an in-memory (RGBA) image.
+// PixelBuffer is an in-memory image.
 type PixelBuffer struct {
-	// Pix holds the image's pixels, in R, G, B, A order.
+	// Pix holds the image's pixels, in R, G, B, A order by default. See
+	// PixelFormat for the layout of the other formats.
 	Pix []uint8
 	// Width and Height of the image's bounds
 	Width, Height int
+	// Format of the pixels, PixelFormatRGBA when unset.
+	Format PixelFormat
+	// Stride is the number of bytes between the start of two rows of the
+	// first plane. Rows are tightly packed when Stride is 0.
+	Stride int
+}
+
+// GLTextureHandlerFunc describes the function that returns the OpenGL texture
+// of a Texture registered with RegisterGL.
+type GLTextureHandlerFunc func(width int, height int) (bool, *GLTexture)
+
+// GLTexture is an OpenGL texture owned by a plugin. It must have been created
+// on an OpenGL context shared with the window, e.g., the context of a
+// RenderTarget, and must not be modified while Flutter draws it.
+type GLTexture struct {
+	// Name of the texture, as returned by glGenTextures.
+	Name uint32
+	// Target of the texture, GL_TEXTURE_2D when unset.
+	Target uint32
+	// Format is the internal format of the texture, GL_RGBA8 when unset.
+	Format uint32
 }
 
 // setTextureHandler registers a handler to be invoked when the Flutter
@@ -85,9 +142,15 @@ func (t *TextureRegistry) setTextureHandler(textureID int64, handler ExternalTex
 	t.channelsLock.Lock()
 	if handler == nil {
 		texture := t.channels[textureID]
-		if texture != nil {
-			t.engineTasker.Do(func() {
-				// Must run on the main tread
+		if texture != nil && texture.frames != nil {
+			t.uploaderLock.Lock()
+			if t.uploader != nil {
+				t.uploader.remove(texture.frames)
+			}
+			t.uploaderLock.Unlock()
+		} else if texture != nil && texture.glHandle == nil {
+			t.glTasks.Do(func() {
+				// Must run on the render tread
 				opengl.DeleteTextures(1, &texture.texture)
 			})
 		}
@@ -100,9 +163,69 @@ func (t *TextureRegistry) setTextureHandler(textureID int64, handler ExternalTex
 	t.channelsLock.Unlock()
 }
 
+// setGLTextureHandler registers a handler returning the OpenGL texture of the
+// plugin.
+func (t *TextureRegistry) setGLTextureHandler(textureID int64, handler GLTextureHandlerFunc) {
+	t.channelsLock.Lock()
+	t.channels[textureID] = &externalTextureHanlder{glHandle: handler}
+	t.channelsLock.Unlock()
+}
+
+// setFrameTexture registers a texture whose frames are pushed, starting the
+// uploader if needed.
+func (t *TextureRegistry) setFrameTexture(textureID int64) (*frameTexture, error) {
+	t.uploaderLock.Lock()
+	if t.uploader == nil {
+		window, err := t.createSharedWindow()
+		if err != nil {
+			t.uploaderLock.Unlock()
+			return nil, errors.Wrap(err, "failed to create the texture upload context")
+		}
+		t.uploader = startTextureUploader(t.engine, window)
+	}
+	t.uploaderLock.Unlock()
+
+	frames := newFrameTexture(textureID)
+	t.channelsLock.Lock()
+	t.channels[textureID] = &externalTextureHanlder{frames: frames}
+	t.channelsLock.Unlock()
+	return frames, nil
+}
+
+// pushFrame pushes a frame to the uploader.
+func (t *TextureRegistry) pushFrame(frames *frameTexture, frame *PixelBuffer) error {
+	t.uploaderLock.Lock()
+	defer t.uploaderLock.Unlock()
+	if t.uploader == nil {
+		return errors.New("the texture uploader is stopped")
+	}
+	t.uploader.push(frames, frame)
+	return nil
+}
+
+// close stops the uploader and closes the render targets, it must be called
+// on the main thread once the engine has been shutdown.
+func (t *TextureRegistry) close() {
+	t.targetsLock.Lock()
+	targets := t.targets
+	t.targets = make(map[*RenderTarget]struct{})
+	t.targetsLock.Unlock()
+	for target := range targets {
+		target.stop()
+	}
+
+	t.uploaderLock.Lock()
+	defer t.uploaderLock.Unlock()
+	if t.uploader != nil {
+		t.uploader.close()
+		t.destroySharedWindow(t.uploader.window)
+		t.uploader = nil
+	}
+}
+
 // handleExternalTexture receive low level C calls to create and/or update the
 // content of a OpenGL TexImage2D.
-// Calls must happen on the engine thread, no need to use engineTasker as this
+// Calls must happen on the render thread, no need to use glTasks as this
 // function is a callback directly managed by the engine.
 func (t *TextureRegistry) handleExternalTexture(textureID int64,
 	width int, height int) *embedder.FlutterOpenGLTexture {
@@ -119,6 +242,27 @@ func (t *TextureRegistry) handleExternalTexture(textureID int64,
 		fmt.Printf("go-flutter: no texture handler found for Texture ID: %v\n", textureID)
 		return nil
 	}
+	if registration.frames != nil {
+		return registration.frames.acquire()
+	}
+	if registration.glHandle != nil {
+		res, texture := registration.glHandle(width, height)
+		if !res || texture == nil || texture.Name == 0 {
+			return nil
+		}
+		glTexture := &embedder.FlutterOpenGLTexture{
+			Target: texture.Target,
+			Name:   texture.Name,
+			Format: texture.Format,
+		}
+		if glTexture.Target == 0 {
+			glTexture.Target = opengl.TEXTURE2D
+		}
+		if glTexture.Format == 0 {
+			glTexture.Format = opengl.RGBA8
+		}
+		return glTexture
+	}
 	res, pixelBuffer := registration.handle(width, height)
 	if !res || pixelBuffer == nil {
 		return nil
@@ -128,19 +272,23 @@ func (t *TextureRegistry) handleExternalTexture(textureID int64,
 		return nil
 	}
 
+	planes, err := pixelBuffer.planes()
+	if err != nil {
+		fmt.Printf("go-flutter: invalid PixelBuffer for Texture ID %v: %v\n", textureID, err)
+		return nil
+	}
+
 	t.window.MakeContextCurrent()
 
 	if registration.texture == 0 {
 		opengl.CreateTexture(&registration.texture)
 	}
 
-	opengl.BindTexture(registration.texture)
-
-	opengl.TexImage2D(
-		int32(pixelBuffer.Width),
-		int32(pixelBuffer.Height),
-		opengl.Ptr(pixelBuffer.Pix),
-	)
+	err = uploadPixelBuffer(registration.texture, pixelBuffer, planes, &t.yuvConverter)
+	if err != nil {
+		fmt.Printf("go-flutter: failed to upload frame of Texture ID %v: %v\n", textureID, err)
+		return nil
+	}
 
 	return &embedder.FlutterOpenGLTexture{
 		Target: opengl.TEXTURE2D,
@@ -0,0 +1,305 @@
+package flutter
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/opengl"
+	"github.com/go-flutter-desktop/go-flutter/internal/pixelplane"
+)
+
+// textureUploader uploads the frames pushed to textures on a dedicated
+// thread, with its own OpenGL context shared with the window. The render
+// thread is never blocked by a slow producer or by a large upload.
+type textureUploader struct {
+	engine *embedder.FlutterEngine
+	// window is a hidden window, owning the OpenGL context of the uploader
+	window *glfw.Window
+
+	lock sync.Mutex
+	// textures having a pending frame, or being removed
+	dirty   map[*frameTexture]struct{}
+	removed []*frameTexture
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+
+	yuvConverter *opengl.YUVConverter
+}
+
+// frameTexture is a texture whose frames are pushed by the plugin. It's
+// triple-buffered: the render thread draws the front slot, the latest
+// uploaded frame waits in the ready slot and the uploader writes to the third
+// one. Frames pushed faster than they're uploaded are dropped, only the
+// latest one is kept.
+type frameTexture struct {
+	id int64
+
+	lock  sync.Mutex
+	slots [3]frameSlot
+	// indexes of the front and ready slots, -1 when unset
+	front, ready int
+	// pending is the latest pushed frame, not yet uploaded
+	pending *PixelBuffer
+}
+
+type frameSlot struct {
+	texture uint32
+	// uploaded is signaled when the upload of the slot has completed.
+	uploaded uintptr
+	// released is signaled when the render thread no longer reads the slot.
+	released uintptr
+}
+
+func newFrameTexture(id int64) *frameTexture {
+	return &frameTexture{id: id, front: -1, ready: -1}
+}
+
+func startTextureUploader(engine *embedder.FlutterEngine, window *glfw.Window) *textureUploader {
+	u := &textureUploader{
+		engine:  engine,
+		window:  window,
+		dirty:   make(map[*frameTexture]struct{}),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go u.run()
+	return u
+}
+
+// push replaces the pending frame of the texture.
+func (u *textureUploader) push(texture *frameTexture, frame *PixelBuffer) {
+	texture.lock.Lock()
+	texture.pending = frame
+	texture.lock.Unlock()
+
+	u.lock.Lock()
+	u.dirty[texture] = struct{}{}
+	u.lock.Unlock()
+	u.signal()
+}
+
+// remove releases the OpenGL textures of the texture.
+func (u *textureUploader) remove(texture *frameTexture) {
+	u.lock.Lock()
+	delete(u.dirty, texture)
+	u.removed = append(u.removed, texture)
+	u.lock.Unlock()
+	u.signal()
+}
+
+func (u *textureUploader) signal() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
+
+// close stops the uploader, its window must then be destroyed.
+func (u *textureUploader) close() {
+	close(u.stop)
+	<-u.stopped
+}
+
+func (u *textureUploader) run() {
+	runtime.LockOSThread()
+	defer close(u.stopped)
+
+	u.window.MakeContextCurrent()
+	defer glfw.DetachCurrentContext()
+	once.Do(func() {
+		if err := opengl.Init(); err != nil {
+			fmt.Printf("go-flutter: TextureRegistry gl init failed: %v\n", err)
+		}
+	})
+
+	for {
+		select {
+		case <-u.wake:
+		case <-u.stop:
+			return
+		}
+
+		u.lock.Lock()
+		dirty := u.dirty
+		removed := u.removed
+		u.dirty = make(map[*frameTexture]struct{})
+		u.removed = nil
+		u.lock.Unlock()
+
+		for texture := range dirty {
+			u.upload(texture)
+		}
+		for _, texture := range removed {
+			texture.delete()
+		}
+	}
+}
+
+// upload uploads the pending frame of the texture to its free slot.
+func (u *textureUploader) upload(texture *frameTexture) {
+	texture.lock.Lock()
+	frame := texture.pending
+	texture.pending = nil
+	texture.lock.Unlock()
+	if frame == nil {
+		return
+	}
+
+	planes, err := frame.planes()
+	if err != nil {
+		fmt.Printf("go-flutter: invalid PixelBuffer for Texture ID %v: %v\n", texture.id, err)
+		return
+	}
+
+	index, slot := texture.beginFrame()
+	err = uploadPixelBuffer(slot.texture, frame, planes, &u.yuvConverter)
+	if err != nil {
+		fmt.Printf("go-flutter: failed to upload frame of Texture ID %v: %v\n", texture.id, err)
+		return
+	}
+	texture.endFrame(index)
+
+	err = u.engine.MarkExternalTextureFrameAvailable(texture.id)
+	if err != nil {
+		fmt.Printf("go-flutter: couldn't mark frame available of texture with id: '%v': %v\n", texture.id, err)
+	}
+}
+
+// beginFrame returns the free slot, to be written by the producer of the
+// frames. The slot texture is created if needed.
+func (t *frameTexture) beginFrame() (int, *frameSlot) {
+	t.lock.Lock()
+	free := 0
+	for free == t.front || free == t.ready {
+		free++
+	}
+	slot := &t.slots[free]
+	t.lock.Unlock()
+
+	// The render thread may still read the slot.
+	if slot.released != 0 {
+		opengl.WaitSync(slot.released)
+		opengl.DeleteSync(slot.released)
+		slot.released = 0
+	}
+	if slot.uploaded != 0 {
+		opengl.DeleteSync(slot.uploaded)
+		slot.uploaded = 0
+	}
+	if slot.texture == 0 {
+		opengl.CreateTexture(&slot.texture)
+	}
+	return free, slot
+}
+
+// endFrame makes the slot written since beginFrame the ready slot. A ready
+// frame which wasn't drawn is dropped, its slot becomes free.
+func (t *frameTexture) endFrame(index int) {
+	fence := opengl.FenceSync()
+	t.lock.Lock()
+	t.slots[index].uploaded = fence
+	t.ready = index
+	t.lock.Unlock()
+}
+
+// acquire returns the OpenGL texture of the latest uploaded frame. It must be
+// called on the render thread.
+func (t *frameTexture) acquire() *embedder.FlutterOpenGLTexture {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.ready != -1 {
+		if t.front != -1 {
+			// The previous frame is no longer drawn once the commands issued
+			// so far have completed.
+			t.slots[t.front].released = opengl.FenceSync()
+		}
+		t.front = t.ready
+		t.ready = -1
+		// Wait for the upload on the GPU, without blocking the thread.
+		front := &t.slots[t.front]
+		opengl.WaitSync(front.uploaded)
+	}
+	if t.front == -1 {
+		return nil
+	}
+	return &embedder.FlutterOpenGLTexture{
+		Target: opengl.TEXTURE2D,
+		Name:   t.slots[t.front].texture,
+		Format: opengl.RGBA8,
+	}
+}
+
+// delete releases the OpenGL objects of the texture, on the uploader thread.
+func (t *frameTexture) delete() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for i := range t.slots {
+		slot := &t.slots[i]
+		if slot.released != 0 {
+			opengl.DeleteSync(slot.released)
+		}
+		if slot.uploaded != 0 {
+			opengl.DeleteSync(slot.uploaded)
+		}
+		if slot.texture != 0 {
+			opengl.DeleteTextures(1, &slot.texture)
+		}
+		*slot = frameSlot{}
+	}
+	t.front, t.ready = -1, -1
+}
+
+// uploadPixelBuffer uploads the pixels to texture, converting planar YUV
+// formats with the converter, created on first use.
+func uploadPixelBuffer(texture uint32, pixelBuffer *PixelBuffer, planes []pixelplane.Plane, converter **opengl.YUVConverter) error {
+	switch pixelBuffer.Format {
+	case PixelFormatRGBA, PixelFormatBGRA:
+		format := uint32(opengl.RGBA)
+		if pixelBuffer.Format == PixelFormatBGRA {
+			format = opengl.BGRA
+		}
+		opengl.BindTexture(texture)
+		opengl.TexImage2DFormat(
+			int32(pixelBuffer.Width),
+			int32(pixelBuffer.Height),
+			int32(planes[0].RowLength),
+			format,
+			opengl.Ptr(pixelBuffer.Pix),
+		)
+
+	default:
+		// Planar YUV formats are uploaded as a texture per plane, and
+		// converted to RGBA by a shader.
+		if *converter == nil {
+			c, err := opengl.NewYUVConverter()
+			if err != nil {
+				return errors.Wrap(err, "failed to create the YUV converter")
+			}
+			*converter = c
+		}
+		yuvPlanes := make([]opengl.YUVPlane, len(planes))
+		for i, plane := range planes {
+			yuvPlanes[i] = opengl.YUVPlane{
+				Width:     int32(plane.Width),
+				Height:    int32(plane.Height),
+				RowLength: int32(plane.RowLength),
+				Pixels:    opengl.Ptr(&pixelBuffer.Pix[plane.Offset]),
+			}
+		}
+		(*converter).Convert(
+			texture,
+			int32(pixelBuffer.Width),
+			int32(pixelBuffer.Height),
+			yuvPlanes,
+		)
+	}
+	return nil
+}
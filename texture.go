@@ -8,6 +8,8 @@ import (
 type Texture struct {
 	ID       int64
 	registry *TextureRegistry
+	// frames is set when the texture is registered with RegisterFrames
+	frames *frameTexture
 }
 
 // Register registers a textureID with his associated handler
@@ -21,6 +23,53 @@ func (t *Texture) Register(handler ExternalTextureHanlderFunc) error {
 	return nil
 }
 
+// RegisterGL registers the textureID with a handler returning an OpenGL
+// texture owned by the plugin, drawn as is. The texture isn't copied nor
+// deleted by go-flutter. Call FrameAvailable when its content has changed.
+func (t *Texture) RegisterGL(handler GLTextureHandlerFunc) error {
+	t.registry.setGLTextureHandler(t.ID, handler)
+	err := t.registry.engine.RegisterExternalTexture(t.ID)
+	if err != nil {
+		t.registry.setTextureHandler(t.ID, nil)
+		return errors.Errorf("'go-flutter' couldn't register texture with id: '%v': %v", t.ID, err)
+	}
+	return nil
+}
+
+// RegisterFrames registers the textureID for frames pushed with PushFrame,
+// instead of a handler. The frames are uploaded in the background, on a
+// dedicated thread and OpenGL context, and the latest uploaded frame is
+// drawn. Flutter is notified when a frame has been uploaded, there is no need
+// to call FrameAvailable.
+func (t *Texture) RegisterFrames() error {
+	frames, err := t.registry.setFrameTexture(t.ID)
+	if err != nil {
+		return errors.Errorf("'go-flutter' couldn't register texture with id: '%v': %v", t.ID, err)
+	}
+	err = t.registry.engine.RegisterExternalTexture(t.ID)
+	if err != nil {
+		t.registry.setTextureHandler(t.ID, nil)
+		return errors.Errorf("'go-flutter' couldn't register texture with id: '%v': %v", t.ID, err)
+	}
+	t.frames = frames
+	return nil
+}
+
+// PushFrame pushes a frame to a texture registered with RegisterFrames. It
+// may be called from any goroutine and doesn't wait for the upload. The frame
+// must not be modified after the call. When frames are pushed faster than
+// they are uploaded, only the latest one is kept.
+func (t *Texture) PushFrame(frame *PixelBuffer) error {
+	if t.frames == nil {
+		return errors.Errorf("'go-flutter' texture with id: '%v' isn't registered with RegisterFrames", t.ID)
+	}
+	err := t.registry.pushFrame(t.frames, frame)
+	if err != nil {
+		return errors.Errorf("'go-flutter' couldn't push frame of texture with id: '%v': %v", t.ID, err)
+	}
+	return nil
+}
+
 // FrameAvailable mark a texture buffer is ready to be draw in the flutter scene
 func (t *Texture) FrameAvailable() error {
 	err := t.registry.engine.MarkExternalTextureFrameAvailable(t.ID)